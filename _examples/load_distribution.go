@@ -50,7 +50,8 @@ func main() {
 		member := c.LocateKey(key)
 		distribution[member.String()]++
 	}
+	estimates := c.EstimateKeyCounts(keyCount)
 	for member, count := range distribution {
-		fmt.Printf("member: %s, key count: %d\n", member, count)
+		fmt.Printf("member: %s, key count: %d, estimated: %d\n", member, count, estimates[member])
 	}
 }
@@ -0,0 +1,131 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// LabeledMember is implemented by members which carry labels, e.g. gpu=true, see LabelSelector.
+type LabeledMember interface {
+	Member
+	Labels() map[string]string
+}
+
+// LabelSelector returns a selector for SetPartitionAffinity which matches the members whose label key has the
+// given value. Members which don't implement LabeledMember never match.
+func LabelSelector(key, value string) func(Member) bool {
+	return func(m Member) bool {
+		lm, ok := m.(LabeledMember)
+		if !ok {
+			return false
+		}
+		v, ok := lm.Labels()[key]
+		return ok && v == value
+	}
+}
+
+// SetPartitionAffinity registers a hint that the partition prefers the members matched by selector. The hint is
+// honored if a matching member has room under the load bound: the partition goes to the first one found by
+// walking the ring from the point of the partition. Otherwise the partition is distributed as usual, see
+// UnsatisfiedAffinities. A nil selector removes the hint. Hints aren't part of snapshots.
+//
+// The partitions are distributed again right away. It returns ErrInvalidPartitionID if there is no such
+// partition, and the error of the distribution if it fails, in which case the previous hint is restored.
+func (c *Consistent) SetPartitionAffinity(partID int, selector func(Member) bool) error {
+	if partID < 0 || partID >= int(c.partitionCount) {
+		return ErrInvalidPartitionID
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	previous, ok := c.affinities[partID]
+	if selector == nil {
+		delete(c.affinities, partID)
+	} else {
+		c.affinities[partID] = selector
+	}
+	if len(c.members) == 0 {
+		return nil
+	}
+	if err := c.redistributeNow(); err != nil {
+		if ok {
+			c.affinities[partID] = previous
+		} else {
+			delete(c.affinities, partID)
+		}
+		return err
+	}
+	return nil
+}
+
+// UnsatisfiedAffinities returns the IDs of the partitions, in ascending order, whose owners don't match the hint
+// registered by SetPartitionAffinity.
+func (c *Consistent) UnsatisfiedAffinities() []int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.unsatisfiedAffinities()
+}
+
+// unsatisfiedAffinities returns the partitions whose hint isn't honored in ascending order. It's not thread-safe.
+func (c *Consistent) unsatisfiedAffinities() []int {
+	var res []int
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		selector, ok := c.affinities[partID]
+		if !ok {
+			continue
+		}
+		if owner := c.getPartitionOwner(partID); owner == nil || !selector(owner) {
+			res = append(res, partID)
+		}
+	}
+	return res
+}
+
+// distributeAffinity assigns the partitions with a hint to the first matching member with room under the load
+// bound, walking the ring from the point of the partition. It's not thread-safe.
+func (c *Consistent) distributeAffinity(partitions map[int]*Member, loads map[string]float64) {
+	if len(c.affinities) == 0 {
+		return
+	}
+	bs := make([]byte, 8)
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		selector, ok := c.affinities[partID]
+		if !ok {
+			continue
+		}
+		if _, ok := partitions[partID]; ok {
+			continue
+		}
+		idx := c.partitionIndex(partID, bs)
+		for i := 0; i < len(c.sortedSet); i++ {
+			member := c.ring[c.sortedSet[(idx+i)%len(c.sortedSet)]]
+			if !selector(*member) {
+				continue
+			}
+			if loads[(*member).String()]+1 <= c.loadBound(*member) {
+				partitions[partID] = member
+				loads[(*member).String()]++
+				break
+			}
+		}
+	}
+}
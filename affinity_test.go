@@ -0,0 +1,99 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+type labeledMember struct {
+	name   string
+	labels map[string]string
+}
+
+func (m labeledMember) String() string {
+	return m.name
+}
+
+func (m labeledMember) Labels() map[string]string {
+	return m.labels
+}
+
+func TestPartitionAffinity(t *testing.T) {
+	var members []Member
+	for i := 0; i < 6; i++ {
+		gpu := "false"
+		if i < 2 {
+			gpu = "true"
+		}
+		members = append(members, labeledMember{name: fmt.Sprintf("node%d.olric", i), labels: map[string]string{"gpu": gpu}})
+	}
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	c := New(members, cfg)
+	gpu := LabelSelector("gpu", "true")
+
+	for partID := 0; partID < 20; partID++ {
+		if err := c.SetPartitionAffinity(partID, gpu); err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+	}
+	for partID := 0; partID < 20; partID++ {
+		if owner := c.GetPartitionOwner(partID); !gpu(owner) {
+			t.Fatalf("Partition %d is owned by %s", partID, owner)
+		}
+	}
+	if unsatisfied := c.UnsatisfiedAffinities(); len(unsatisfied) != 0 {
+		t.Fatalf("Expected no unsatisfied hints, Got: %v", unsatisfied)
+	}
+
+	// The two GPU members cannot own every partition under the load bound.
+	for partID := 20; partID < 271; partID++ {
+		if err := c.SetPartitionAffinity(partID, gpu); err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+	}
+	if unsatisfied := c.UnsatisfiedAffinities(); len(unsatisfied) == 0 {
+		t.Fatalf("Expected unsatisfied hints")
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	for partID := 0; partID < 271; partID++ {
+		if err := c.SetPartitionAffinity(partID, nil); err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+	}
+	if !c.Equal(New(members, cfg)) {
+		t.Fatalf("Expected the layout without hints")
+	}
+	if err := c.SetPartitionAffinity(271, gpu); err != ErrInvalidPartitionID {
+		t.Fatalf("Expected ErrInvalidPartitionID, Got: %v", err)
+	}
+	if LabelSelector("gpu", "true")(testMember("node9.olric")) {
+		t.Fatalf("Expected a member without labels not to match")
+	}
+}
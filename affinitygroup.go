@@ -0,0 +1,202 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"sort"
+)
+
+// SetAffinityGroup declares that the given partitions, e.g. those of the same logical table, should preferably be
+// owned by the same member. The distribution puts as many of them as fit under the load bound on the member which
+// owns most of them, then on the next one, and so on; partition hints of SetPartitionAffinity take precedence. A
+// partition belongs to at most one group, adding it to a group takes it out of its previous one. Empty partitions
+// remove the group. Groups aren't part of snapshots.
+//
+// The partitions are distributed again right away. It returns ErrInvalidPartitionID if one of the partitions
+// doesn't exist, and the error of the distribution if it fails, in which case the previous groups are restored.
+func (c *Consistent) SetAffinityGroup(name string, partitions []int) error {
+	for _, partID := range partitions {
+		if partID < 0 || partID >= int(c.partitionCount) {
+			return ErrInvalidPartitionID
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	previous := c.affinityGroups
+	groups := make(map[string][]int, len(previous)+1)
+	for group, members := range previous {
+		if group == name {
+			continue
+		}
+		var kept []int
+		for _, partID := range members {
+			if !containsPartition(partitions, partID) {
+				kept = append(kept, partID)
+			}
+		}
+		if len(kept) != 0 {
+			groups[group] = kept
+		}
+	}
+	if len(partitions) != 0 {
+		sorted := make([]int, 0, len(partitions))
+		for _, partID := range partitions {
+			if !containsPartition(sorted, partID) {
+				sorted = append(sorted, partID)
+			}
+		}
+		sort.Ints(sorted)
+		groups[name] = sorted
+	}
+	c.affinityGroups = groups
+	if len(c.members) == 0 {
+		return nil
+	}
+	if err := c.redistributeNow(); err != nil {
+		c.affinityGroups = previous
+		return err
+	}
+	return nil
+}
+
+// AffinityGroups returns a copy of the groups registered by SetAffinityGroup.
+func (c *Consistent) AffinityGroups() map[string][]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return copyGroups(c.affinityGroups)
+}
+
+// Colocation returns the share of the partitions of the group which are owned by the member owning most of them,
+// between zero and one. One means the whole group is on one member. It returns zero if there is no such group or
+// the ring is empty.
+func (c *Consistent) Colocation(name string) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	partitions := c.affinityGroups[name]
+	if len(partitions) == 0 {
+		return 0
+	}
+	counts := make(map[string]int)
+	var max int
+	for _, partID := range partitions {
+		owner := c.getPartitionOwner(partID)
+		if owner == nil {
+			continue
+		}
+		counts[owner.String()]++
+		if counts[owner.String()] > max {
+			max = counts[owner.String()]
+		}
+	}
+	return float64(max) / float64(len(partitions))
+}
+
+// AffinityGroups returns a copy of the groups registered by SetAffinityGroup, e.g. to score co-location in a
+// custom Distributor.
+func (d DistributionContext) AffinityGroups() map[string][]int {
+	return copyGroups(d.c.affinityGroups)
+}
+
+func copyGroups(groups map[string][]int) map[string][]int {
+	res := make(map[string][]int, len(groups))
+	for name, partitions := range groups {
+		res[name] = append([]int(nil), partitions...)
+	}
+	return res
+}
+
+func containsPartition(partitions []int, partID int) bool {
+	for _, p := range partitions {
+		if p == partID {
+			return true
+		}
+	}
+	return false
+}
+
+// distributeAffinityGroups assigns the partitions of every affinity group, in the order of the group names. The
+// candidates are the members which own most of the partitions of the group in the current table, then the
+// members found by walking the ring from the point of the first partition of the group. Every candidate takes as
+// many partitions as fit under its load bound before the next one gets any. It's not thread-safe.
+func (c *Consistent) distributeAffinityGroups(partitions map[int]*Member, loads map[string]float64) {
+	if len(c.affinityGroups) == 0 || len(c.sortedSet) == 0 {
+		return
+	}
+	names := make([]string, 0, len(c.affinityGroups))
+	for name := range c.affinityGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	bs := make([]byte, 8)
+	for _, name := range names {
+		group := c.affinityGroups[name]
+		candidates := c.groupCandidates(group, bs)
+		next := 0
+		for _, partID := range group {
+			if _, ok := partitions[partID]; ok {
+				continue
+			}
+			for ; next < len(candidates); next++ {
+				member := candidates[next]
+				if loads[(*member).String()]+1 <= c.loadBound(*member) {
+					partitions[partID] = member
+					loads[(*member).String()]++
+					break
+				}
+			}
+		}
+	}
+}
+
+// groupCandidates returns the members in the order distributeAffinityGroups tries them for the group. It's not
+// thread-safe.
+func (c *Consistent) groupCandidates(group []int, bs []byte) []*Member {
+	counts := make(map[string]int)
+	for _, partID := range group {
+		if owner, ok := c.partitions[partID]; ok {
+			if _, ok := c.members[(*owner).String()]; ok {
+				counts[(*owner).String()]++
+			}
+		}
+	}
+	var candidates []*Member
+	seen := make(map[string]struct{})
+	idx := c.partitionIndex(group[0], bs)
+	for i := 0; i < len(c.sortedSet); i++ {
+		member := c.ring[c.sortedSet[(idx+i)%len(c.sortedSet)]]
+		if _, ok := seen[(*member).String()]; ok {
+			continue
+		}
+		seen[(*member).String()] = struct{}{}
+		candidates = append(candidates, member)
+	}
+	// The ring order breaks ties between members which own equally many partitions of the group.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return counts[(*candidates[i]).String()] > counts[(*candidates[j]).String()]
+	})
+	return candidates
+}
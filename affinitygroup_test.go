@@ -0,0 +1,91 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAffinityGroup(t *testing.T) {
+	members := testMembers(6)
+	cfg := newConfigWith(271)
+	c := New(members, cfg)
+
+	var table []int
+	for partID := 0; partID < 271; partID += 7 {
+		table = append(table, partID)
+	}
+	if err := c.SetAffinityGroup("table", table); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if colocation := c.Colocation("table"); colocation != 1 {
+		t.Fatalf("Expected the group on one member, Got: %v", colocation)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	// The group doesn't fit under the load bound of one member.
+	var large []int
+	for partID := 0; partID < 150; partID++ {
+		large = append(large, partID)
+	}
+	if err := c.SetAffinityGroup("large", large); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if colocation := c.Colocation("large"); colocation >= 1 || colocation < 0.3 {
+		t.Fatalf("Expected the group to be split over few members, Got: %v", colocation)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	groups := c.AffinityGroups()
+	for _, partID := range groups["table"] {
+		if partID < 150 {
+			t.Fatalf("Expected partition %d to leave the first group", partID)
+		}
+	}
+	if !reflect.DeepEqual(groups["large"], large) {
+		t.Fatalf("Expected %v, Got: %v", large, groups["large"])
+	}
+
+	for _, name := range []string{"table", "large"} {
+		if err := c.SetAffinityGroup(name, nil); err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+	}
+	if len(c.AffinityGroups()) != 0 {
+		t.Fatalf("Expected no groups, Got: %v", c.AffinityGroups())
+	}
+	if colocation := c.Colocation("table"); colocation != 0 {
+		t.Fatalf("Expected zero for a missing group, Got: %v", colocation)
+	}
+	if !c.Equal(New(members, cfg)) {
+		t.Fatalf("Expected the layout without groups")
+	}
+	if err := c.SetAffinityGroup("table", []int{271}); err != ErrInvalidPartitionID {
+		t.Fatalf("Expected ErrInvalidPartitionID, Got: %v", err)
+	}
+}
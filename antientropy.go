@@ -0,0 +1,133 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"sort"
+	"sync"
+)
+
+// AntiEntropy compares the content checksums of the replicas of every partition, see
+// GetPartitionOwnerAndBackups, to schedule replica repair. The application computes the checksums, e.g. a hash
+// of the keys and versions of a partition, and reports them for every member with SetChecksum. The owner of a
+// partition is the reference: a backup which reports another checksum, or none, needs a repair. It's safe for
+// concurrent use.
+type AntiEntropy struct {
+	c *Consistent
+
+	mu   sync.Mutex
+	sums map[int]map[string]uint64
+}
+
+// Divergence describes a partition whose replicas don't agree, see AntiEntropy.Divergent.
+type Divergence struct {
+	// PartitionID is the ID of the partition.
+	PartitionID int
+
+	// Owner is the owner of the partition, whose checksum is the reference.
+	Owner Member
+
+	// Checksums maps the names of the replicas which reported a checksum to it.
+	Checksums map[string]uint64
+
+	// Repair are the backups whose checksum differs from the one of the owner or is missing, in replica order.
+	Repair []Member
+}
+
+// NewAntiEntropy returns an AntiEntropy which compares the checksums over the replicas of the ring.
+func NewAntiEntropy(c *Consistent) *AntiEntropy {
+	return &AntiEntropy{
+		c:    c,
+		sums: make(map[int]map[string]uint64),
+	}
+}
+
+// SetChecksum records the checksum of the partition on the member. It returns ErrInvalidPartitionID if the
+// partition doesn't exist.
+func (a *AntiEntropy) SetChecksum(member string, partID int, sum uint64) error {
+	if partID < 0 || partID >= int(a.c.partitionCount) {
+		return ErrInvalidPartitionID
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	sums, ok := a.sums[partID]
+	if !ok {
+		sums = make(map[string]uint64)
+		a.sums[partID] = sums
+	}
+	sums[member] = sum
+	return nil
+}
+
+// Forget drops the checksums of the member, e.g. after it left the ring or lost its data.
+func (a *AntiEntropy) Forget(member string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for partID, sums := range a.sums {
+		delete(sums, member)
+		if len(sums) == 0 {
+			delete(a.sums, partID)
+		}
+	}
+}
+
+// Divergent returns the partitions whose backups don't agree with their owner, ordered by partition ID.
+// Partitions whose owner hasn't reported a checksum yet are left out, and checksums of members which don't hold
+// a replica of the partition are ignored.
+func (a *AntiEntropy) Divergent() []Divergence {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var res []Divergence
+	for partID, sums := range a.sums {
+		replicas := a.c.GetPartitionOwnerAndBackups(partID)
+		if len(replicas) == 0 {
+			continue
+		}
+		reference, ok := sums[replicas[0].String()]
+		if !ok {
+			continue
+		}
+		d := Divergence{PartitionID: partID, Owner: replicas[0], Checksums: make(map[string]uint64)}
+		d.Checksums[replicas[0].String()] = reference
+		for _, backup := range replicas[1:] {
+			sum, ok := sums[backup.String()]
+			if ok {
+				d.Checksums[backup.String()] = sum
+			}
+			if !ok || sum != reference {
+				d.Repair = append(d.Repair, backup)
+			}
+		}
+		if len(d.Repair) != 0 {
+			res = append(res, d)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].PartitionID < res[j].PartitionID
+	})
+	return res
+}
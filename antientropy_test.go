@@ -0,0 +1,85 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import "testing"
+
+func TestAntiEntropy(t *testing.T) {
+	cfg := newConfigWith(271)
+	cfg.BackupCount = 2
+	c := New(testMembers(6), cfg)
+	a := NewAntiEntropy(c)
+
+	for partID := 0; partID < 271; partID++ {
+		for _, member := range c.GetPartitionOwnerAndBackups(partID) {
+			if err := a.SetChecksum(member.String(), partID, uint64(partID)); err != nil {
+				t.Fatalf("Expected nil, Got: %v", err)
+			}
+		}
+	}
+	if divergent := a.Divergent(); len(divergent) != 0 {
+		t.Fatalf("Expected no divergent partitions, Got: %v", divergent)
+	}
+
+	replicas := c.GetPartitionOwnerAndBackups(42)
+	if err := a.SetChecksum(replicas[2].String(), 42, 1); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	// Checksums of members without a replica are ignored.
+	for _, member := range testMembers(6) {
+		if !containsMember(c.GetPartitionOwnerAndBackups(7), member) {
+			if err := a.SetChecksum(member.String(), 7, 1); err != nil {
+				t.Fatalf("Expected nil, Got: %v", err)
+			}
+		}
+	}
+	divergent := a.Divergent()
+	if len(divergent) != 1 || divergent[0].PartitionID != 42 {
+		t.Fatalf("Expected partition 42 to diverge, Got: %v", divergent)
+	}
+	d := divergent[0]
+	if d.Owner.String() != replicas[0].String() || len(d.Repair) != 1 || d.Repair[0].String() != replicas[2].String() {
+		t.Fatalf("Unexpected divergence: %+v", d)
+	}
+	if d.Checksums[replicas[2].String()] != 1 || len(d.Checksums) != 3 {
+		t.Fatalf("Unexpected checksums: %v", d.Checksums)
+	}
+
+	a.Forget(replicas[1].String())
+	for _, d := range a.Divergent() {
+		if !containsMember(d.Repair, replicas[1]) {
+			t.Fatalf("Expected %s to need a repair of partition %d", replicas[1], d.PartitionID)
+		}
+	}
+	// Without a checksum of the owner, partition 42 cannot be compared.
+	a.Forget(replicas[0].String())
+	for _, d := range a.Divergent() {
+		if d.PartitionID == 42 {
+			t.Fatalf("Expected partition 42 to be left out")
+		}
+	}
+	if err := a.SetChecksum("node0.olric", 271, 0); err != ErrInvalidPartitionID {
+		t.Fatalf("Expected ErrInvalidPartitionID, Got: %v", err)
+	}
+}
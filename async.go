@@ -0,0 +1,220 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"context"
+	"time"
+)
+
+// bumpVersion increments the version of the partition table and wakes up the goroutines blocked in
+// WaitForVersion. It's not thread-safe.
+func (c *Consistent) bumpVersion() {
+	c.version++
+	c.recordSnapshot()
+	c.autoSave()
+	if c.versionCh != nil {
+		if c.publishDelay() > 0 {
+			c.publishLater(c.version)
+			return
+		}
+		close(c.versionCh)
+		c.versionCh = make(chan struct{})
+	}
+}
+
+// LatestVersion returns the version of the partition table which reflects the current membership. It's Version
+// if no distribution is pending, and the version of the next distribution otherwise. Call it after Add or Remove
+// and pass the result to WaitForVersion to wait until the change is visible.
+func (c *Consistent) LatestVersion() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.pending {
+		return c.version + 1
+	}
+	return c.version
+}
+
+// WaitForVersion blocks until the version of the partition table is at least version or ctx is done. It returns
+// ctx.Err() in the latter case.
+func (c *Consistent) WaitForVersion(ctx context.Context, version uint64) error {
+	for {
+		c.mu.RLock()
+		current, ch := c.publishedVersion(), c.versionCh
+		c.mu.RUnlock()
+		if current >= version {
+			return nil
+		}
+
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// startAsyncDistribution starts a background goroutine which distributes the partitions, unless one is
+// already running. It's not thread-safe.
+func (c *Consistent) startAsyncDistribution() {
+	if c.distributing {
+		// The running goroutine sees the new membership change and distributes again.
+		return
+	}
+	c.distributing = true
+	go c.distributeAsync()
+}
+
+// distributeAsync distributes the partitions on a private copy of the ring, without holding the lock, and swaps
+// the new table in. If the membership changed in the meantime, the result is discarded and the distribution
+// runs again, so a table is never swapped in without the latest change. If the distribution fails, the previous
+// table is kept and the distribution stays pending until the next membership change or Flush.
+func (c *Consistent) distributeAsync() {
+	for {
+		c.mu.Lock()
+		if !c.pending {
+			c.distributing = false
+			c.mu.Unlock()
+			return
+		}
+		changes := c.changes
+		// The copy shares the virtual nodes, rebuild them here.
+		c.adaptReplicationFactor()
+		shadow := c.shadow()
+		c.mu.Unlock()
+
+		start := time.Now()
+		err := shadow.distributePartitions()
+
+		c.mu.Lock()
+		if c.pending && c.changes == changes {
+			if err != nil {
+				c.adoptReport(shadow)
+				if c.observed() {
+					c.recordDistribution(start, c.partitions, err)
+				}
+				c.distributing = false
+				c.mu.Unlock()
+				return
+			}
+			previous := c.partitions
+			c.partitions = shadow.partitions
+			c.loads = shadow.loads
+			c.replicas = shadow.replicas
+			c.weightSum = shadow.weightSum
+			c.relaxedLoad, c.loadScale, c.overflowTier = shadow.relaxedLoad, shadow.loadScale, shadow.overflowTier
+			c.failover = nil
+			c.movedAt = shadow.movedAt
+			c.pending = false
+			c.rampAt = shadow.rampAt
+			c.trackHandoffs(previous)
+			c.recordLayout(previous)
+			c.bumpVersion()
+			c.scheduleWarmup()
+			c.adoptReport(shadow)
+			if c.observed() {
+				c.recordDistribution(start, previous, nil)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
+
+// shadow returns a copy of the ring which shares nothing that Add and Remove modify, so its partitions can be
+// distributed without holding the lock. The partition table is shared, a distribution replaces it and never
+// modifies it. It's not thread-safe.
+func (c *Consistent) shadow() *Consistent {
+	s := &Consistent{
+		config:         c.config,
+		hasher:         c.hasher,
+		partitionCount: c.partitionCount,
+		partitions:     c.partitions,
+		sortedSet:      append([]uint64(nil), c.sortedSet...),
+		memberHashes:   append([]uint64(nil), c.memberHashes...),
+		members:        make(map[string]*Member, len(c.members)),
+		ring:           make(map[uint64]*Member, len(c.ring)),
+		hashedMembers:  make(map[uint64]*Member, len(c.hashedMembers)),
+		weighted:       c.weighted,
+		version:        c.version,
+	}
+	// The distribution is recorded when it's swapped in.
+	s.config.MeterProvider = nil
+	s.config.Logger = nil
+	s.config.DeltaHistory = 0
+	s.config.HistorySize = 0
+	s.config.AssignmentSink = nil
+	for name, member := range c.members {
+		s.members[name] = member
+	}
+	if c.normalizedNames != nil {
+		s.normalizedNames = make(map[string]string, len(c.normalizedNames))
+		for normalized, name := range c.normalizedNames {
+			s.normalizedNames[normalized] = name
+		}
+	}
+	for h, member := range c.ring {
+		s.ring[h] = member
+	}
+	for h, member := range c.hashedMembers {
+		s.hashedMembers[h] = member
+	}
+	s.maxLoads = make(map[string]int, len(c.maxLoads))
+	for name, n := range c.maxLoads {
+		s.maxLoads[name] = n
+	}
+	s.overflow = make(map[string]int, len(c.overflow))
+	for name, priority := range c.overflow {
+		s.overflow[name] = priority
+	}
+	s.detached = true
+	s.warming = make(map[string]warmup, len(c.warming))
+	for name, w := range c.warming {
+		s.warming[name] = w
+	}
+	s.affinities = make(map[int]func(Member) bool, len(c.affinities))
+	for partID, selector := range c.affinities {
+		s.affinities[partID] = selector
+	}
+	// SetAffinityGroup and WeightController.Step replace the maps instead of modifying them.
+	s.affinityGroups = c.affinityGroups
+	s.weightScales = c.weightScales
+	s.leaving = make(map[string]float64, len(c.leaving))
+	for name, bound := range c.leaving {
+		s.leaving[name] = bound
+	}
+	s.draining = make(map[string]struct{}, len(c.draining))
+	for name := range c.draining {
+		s.draining[name] = struct{}{}
+	}
+	// updateResidency replaces the map instead of modifying it.
+	s.movedAt = c.movedAt
+	if c.failover != nil {
+		s.failover = make(map[int]Member, len(c.failover))
+		for partID, member := range c.failover {
+			s.failover[partID] = member
+		}
+	}
+	return s
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAsyncDistribution(t *testing.T) {
+	cfg := newConfig()
+	cfg.AsyncDistribution = true
+	c := New([]Member{testMember("node0.olric")}, cfg)
+
+	for i := 1; i < 8; i++ {
+		c.Add(testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c.Remove("node3.olric")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.WaitForVersion(ctx, c.LatestVersion()); err != nil {
+		t.Fatalf("Expected no error, Got: %v", err)
+	}
+	if c.Pending() {
+		t.Fatalf("Expected no pending distribution")
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected no error, Got: %v", err)
+	}
+	expected := New(c.GetMembers(), newConfig())
+	if !c.Equal(expected) {
+		t.Fatalf("Async layout is different from a synchronous one")
+	}
+}
+
+func TestAsyncDistributionBackups(t *testing.T) {
+	cfg := newConfig()
+	cfg.AsyncDistribution = true
+	cfg.BackupCount = 2
+	c := New(nil, cfg)
+	for i := 0; i < 8; i++ {
+		c.Add(testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Expected no error, Got: %v", err)
+	}
+
+	sync := newConfig()
+	sync.BackupCount = 2
+	expected := New(c.GetMembers(), sync)
+	if c.Fingerprint() != expected.Fingerprint() {
+		t.Fatalf("Async layout is different from a synchronous one")
+	}
+}
+
+func TestAsyncDistributionConcurrent(t *testing.T) {
+	cfg := newConfig()
+	cfg.AsyncDistribution = true
+	c := New([]Member{testMember("node0.olric")}, cfg)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 1; i < 32; i++ {
+			c.Add(testMember(fmt.Sprintf("node%d.olric", i)))
+			if i%3 == 0 {
+				c.Remove(fmt.Sprintf("node%d.olric", i-1))
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			if c.LocateKey([]byte(fmt.Sprintf("key-%d", i))) == nil {
+				t.Errorf("Expected an owner")
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.WaitForVersion(ctx, c.LatestVersion()); err != nil {
+		t.Fatalf("Expected no error, Got: %v", err)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected no error, Got: %v", err)
+	}
+}
+
+func TestWaitForVersionContext(t *testing.T) {
+	c := New([]Member{testMember("node0.olric")}, newConfig())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.WaitForVersion(ctx, c.Version()+1); err != context.DeadlineExceeded {
+		t.Fatalf("Expected context.DeadlineExceeded, Got: %v", err)
+	}
+	if err := c.WaitForVersion(context.Background(), c.Version()); err != nil {
+		t.Fatalf("Expected no error, Got: %v", err)
+	}
+
+	done := make(chan error)
+	next := c.Version() + 1
+	go func() {
+		done <- c.WaitForVersion(context.Background(), next)
+	}()
+	c.Add(testMember("node1.olric"))
+	if err := <-done; err != nil {
+		t.Fatalf("Expected no error, Got: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// AutoReplicationFactor scales the number of virtual nodes per member with the member count, see
+// Config.AutoReplicationFactor. Small clusters get more virtual nodes for a smooth distribution, large clusters
+// get fewer to save memory.
+type AutoReplicationFactor struct {
+	// TotalVirtualNodes is the number of virtual nodes the ring aims for. Zero disables the mode.
+	TotalVirtualNodes int
+
+	// Min and Max bound the replication factor. Min defaults to one, zero Max means no upper bound.
+	Min int
+	Max int
+}
+
+// factor returns the replication factor for the given member count. The count is rounded up to a power of two,
+// so the factor only changes when the cluster doubles or halves in size.
+func (a AutoReplicationFactor) factor(members int) int {
+	n := 1
+	for n < members {
+		n <<= 1
+	}
+	factor := a.TotalVirtualNodes / n
+	if a.Max > 0 && factor > a.Max {
+		factor = a.Max
+	}
+	min := a.Min
+	if min < 1 {
+		min = 1
+	}
+	if factor < min {
+		factor = min
+	}
+	return factor
+}
+
+// ReplicationFactor returns the number of virtual nodes per member, see Config.AutoReplicationFactor.
+func (c *Consistent) ReplicationFactor() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.config.ReplicationFactor
+}
+
+// adaptReplicationFactor rebuilds the ring with the replication factor for the current member count if it
+// differs from Config.ReplicationFactor. It's called before every distribution, so a batch of changes
+// rebuilds the ring once. Detached copies are left alone, their ring is discarded. It's not thread-safe.
+func (c *Consistent) adaptReplicationFactor() {
+	if c.detached || c.config.AutoReplicationFactor.TotalVirtualNodes <= 0 || len(c.members) == 0 {
+		return
+	}
+	factor := c.config.AutoReplicationFactor.factor(len(c.members))
+	if factor == c.config.ReplicationFactor {
+		return
+	}
+	c.config.ReplicationFactor = factor
+
+	// Collisions are resolved by priority, so the layout doesn't depend on the order of the members.
+	// Members added by AddWithReplicas keep their count.
+	c.ring = make(map[uint64]*Member, len(c.members)*factor)
+	c.salts = make(map[uint64]int)
+	c.vnodes = make(map[string][]uint64, len(c.members))
+	sortedSet := make([]uint64, 0, len(c.members)*factor)
+	for name, member := range c.members {
+		n := c.replicaCount(name)
+		c.vnodes[name] = make([]uint64, n)
+		for i := 0; i < n; i++ {
+			c.insertVirtualNode(member, i, 0)
+		}
+	}
+	for h := range c.ring {
+		sortedSet = append(sortedSet, h)
+	}
+	sortHashes(sortedSet)
+	c.sortedSet = sortedSet
+	if c.config.Logger != nil {
+		c.config.Logger.Info("replication factor changed", "factor", factor, "members", len(c.members))
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestAutoReplicationFactor(t *testing.T) {
+	a := AutoReplicationFactor{TotalVirtualNodes: 1024, Min: 8, Max: 128}
+	for members, want := range map[int]int{1: 128, 4: 128, 8: 128, 9: 64, 16: 64, 100: 8, 1000: 8} {
+		if got := a.factor(members); got != want {
+			t.Fatalf("Expected factor %d for %d members, got: %d", want, members, got)
+		}
+	}
+}
+
+func TestAutoReplicationFactorRebuild(t *testing.T) {
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.AutoReplicationFactor = AutoReplicationFactor{TotalVirtualNodes: 256, Min: 4, Max: 64}
+	c := New(testMembers(4), cfg)
+	if got := c.ReplicationFactor(); got != 64 {
+		t.Fatalf("Expected factor 64, got: %d", got)
+	}
+	if got := len(c.sortedSet); got != 4*64 {
+		t.Fatalf("Expected %d virtual nodes, got: %d", 4*64, got)
+	}
+
+	for i := 4; i < 16; i++ {
+		c.Add(testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	if got := c.ReplicationFactor(); got != 16 {
+		t.Fatalf("Expected factor 16, got: %d", got)
+	}
+	if got := len(c.sortedSet); got != 16*16 {
+		t.Fatalf("Expected %d virtual nodes, got: %d", 16*16, got)
+	}
+	for name, hashes := range c.vnodes {
+		if len(hashes) != 16 {
+			t.Fatalf("Expected 16 virtual nodes for %s, got: %d", name, len(hashes))
+		}
+	}
+
+	// The layout doesn't depend on how the ring got there.
+	fresh := New(testMembers(16), cfg)
+	for partID, owner := range c.owners() {
+		if owner.String() != fresh.owners()[partID].String() {
+			t.Fatalf("Expected partition %d on %s, got: %s", partID, fresh.owners()[partID], owner)
+		}
+	}
+
+	c.Remove("node15.olric")
+	if got := c.ReplicationFactor(); got != 16 {
+		t.Fatalf("Expected factor 16, got: %d", got)
+	}
+	for i := 8; i < 15; i++ {
+		c.Remove(fmt.Sprintf("node%d.olric", i))
+	}
+	if got := c.ReplicationFactor(); got != 32 {
+		t.Fatalf("Expected factor 32, got: %d", got)
+	}
+}
+
+func TestAutoReplicationFactorAsync(t *testing.T) {
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.AsyncDistribution = true
+	cfg.AutoReplicationFactor = AutoReplicationFactor{TotalVirtualNodes: 256, Min: 4, Max: 64}
+	c := New(testMembers(4), cfg)
+	for i := 4; i < 16; i++ {
+		c.Add(testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	if err := c.WaitForVersion(context.Background(), c.LatestVersion()); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if got := c.ReplicationFactor(); got != 16 {
+		t.Fatalf("Expected factor 16, got: %d", got)
+	}
+	fresh := New(testMembers(16), cfg)
+	if err := fresh.WaitForVersion(context.Background(), fresh.LatestVersion()); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	for partID, owner := range c.owners() {
+		if owner.String() != fresh.owners()[partID].String() {
+			t.Fatalf("Expected partition %d on %s, got: %s", partID, fresh.owners()[partID], owner)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"time"
+)
+
+// partitionIndex returns the index of the first virtual node at or after the point of the partition on the ring.
+// bs is a scratch buffer of 8 bytes. It's not thread-safe.
+func (c *Consistent) partitionIndex(partID int, bs []byte) int {
+	return c.search(c.partitionPoint(partID, bs))
+}
+
+// partitionPoint returns the point of the partition on the ring. bs is a scratch buffer of 8 bytes.
+func (c *Consistent) partitionPoint(partID int, bs []byte) uint64 {
+	return c.hasher.Sum64(c.partitionKey(partID, bs))
+}
+
+// successors returns the owner of the partition followed by the next count-1 distinct members found by walking
+// the virtual nodes clockwise from the point of the partition. Every partition starts the walk at a different
+// point, so the successors of different partitions are spread over the members. The result depends only on the
+// hasher and the member names. It's not thread-safe.
+func (c *Consistent) successors(partID, count int) []Member {
+	var res []Member
+	owner := c.getPartitionOwner(partID)
+	if owner == nil || count <= 0 {
+		return res
+	}
+	res = append(res, owner)
+	if count == 1 {
+		return res
+	}
+
+	seen := map[string]struct{}{owner.String(): {}}
+	idx := c.partitionIndex(partID, make([]byte, 8))
+	for i := 0; i < len(c.sortedSet) && len(res) < count; i++ {
+		member := *c.ring[c.sortedSet[(idx+i)%len(c.sortedSet)]]
+		if _, ok := seen[member.String()]; ok {
+			continue
+		}
+		seen[member.String()] = struct{}{}
+		res = append(res, member)
+	}
+	return res
+}
+
+// LocateKeyNear returns the first member among the owner of the key and its backups from Config.BackupCount
+// which is in the zone of the client, see ZonedMember, e.g. for reads from geo-replicated caches. It falls back
+// to the owner if none of them is in that zone, and returns nil if the ring is empty.
+func (c *Consistent) LocateKeyNear(key []byte, clientZone string) Member {
+	replicas := c.GetPartitionOwnerAndBackups(c.FindPartitionID(key))
+	for _, member := range replicas {
+		if memberZone(member) == clientZone {
+			return member
+		}
+	}
+	if len(replicas) == 0 {
+		return nil
+	}
+	return replicas[0]
+}
+
+// BackupDirection is the direction of the walk which picks the backups of a partition, see Config.BackupDirection.
+type BackupDirection int
+
+const (
+	// Clockwise walks the virtual nodes clockwise from the point of the partition, past the owner. It's the
+	// default.
+	Clockwise BackupDirection = iota
+
+	// CounterClockwise walks the virtual nodes counter-clockwise from the point of the partition, so the backups
+	// are rarely the members which would take over the partition when the owner leaves.
+	CounterClockwise
+)
+
+// ZonedMember is implemented by members which know their zone, e.g. an availability zone or a rack, see
+// Config.MinBackupZones.
+type ZonedMember interface {
+	Member
+	Zone() string
+}
+
+// memberZone returns the zone of the member, empty if it doesn't implement ZonedMember.
+func memberZone(member Member) string {
+	if zm, ok := member.(ZonedMember); ok {
+		return zm.Zone()
+	}
+	return ""
+}
+
+// replicaTable computes the owner and Config.BackupCount backups of every partition. The number of backups is
+// limited by the member count. Backups are picked by walking the virtual nodes in Config.BackupDirection from the
+// point of the partition, skipping the members which already hold it, draining members and those rejected by
+// Config.SkipBackup. Like partition owners, a member is skipped if it already holds its share of the backups
+// assigned so far according to its weight and Config.Load. If every member is over its share, the slot goes to
+// the member which holds the fewest backups relative to its weight. Until the owner and the backups span
+// Config.MinBackupZones zones, members in new zones are preferred. A partition gets fewer backups if there are
+// not enough eligible members. Config.ReplicaStrategy replaces all of this if it's set. It returns nil if
+// BackupCount is zero and ErrDistributionTimeout if the deadline passes. It's not thread-safe.
+func (c *Consistent) replicaTable(deadline time.Time) ([][]Member, error) {
+	if c.config.ReplicaStrategy != nil {
+		return c.replicaTableWith(c.config.ReplicaStrategy, deadline)
+	}
+	if c.config.BackupCount <= 0 {
+		return nil, nil
+	}
+	count := c.config.BackupCount + 1
+	if active := len(c.members) - len(c.draining); count > active {
+		count = active
+	}
+	bs := make([]byte, 8)
+	loads := make(map[string]float64)
+	var assigned float64
+	replicas := make([][]Member, c.partitionCount)
+	for partID := range replicas {
+		if c.expired(deadline, partID) {
+			return nil, ErrDistributionTimeout
+		}
+		res := make([]Member, 0, count)
+		res = append(res, c.getPartitionOwner(partID))
+		idx := c.partitionIndex(partID, bs)
+		for len(res) < count {
+			best := c.pickBackup(res, idx, loads, assigned, c.needZone(res))
+			if best == nil {
+				best = c.pickBackup(res, idx, loads, assigned, false)
+			}
+			if best == nil {
+				break
+			}
+			loads[best.String()]++
+			assigned++
+			res = append(res, best)
+		}
+		replicas[partID] = res
+	}
+	return replicas, nil
+}
+
+// pickBackup walks the ring from idx for the next backup of a partition held by res, as described at
+// replicaTable. If newZone is set, only members in zones which res doesn't cover are eligible. It returns nil if
+// no member is eligible. It's not thread-safe.
+func (c *Consistent) pickBackup(res []Member, idx int, loads map[string]float64, assigned float64, newZone bool) Member {
+	var best Member
+	var bestLoad float64
+	n := len(c.sortedSet)
+	for i := 0; i < n; i++ {
+		pos := (idx + i) % n
+		if c.config.BackupDirection == CounterClockwise {
+			pos = ((idx-1-i)%n + n) % n
+		}
+		candidate := *c.ring[c.sortedSet[pos]]
+		if containsMember(res, candidate) || c.drained(candidate) {
+			continue
+		}
+		if _, ok := c.leavingBound(candidate); ok {
+			continue
+		}
+		if c.config.SkipBackup != nil && c.config.SkipBackup(candidate) {
+			continue
+		}
+		if newZone && containsZone(res, memberZone(candidate)) {
+			continue
+		}
+		load := loads[candidate.String()]
+		if load+1 <= c.capacity(candidate, assigned+1, c.memberLoad(candidate), c.weightSum) {
+			return candidate
+		}
+		if relative := load / c.weight(candidate); best == nil || relative < bestLoad {
+			best, bestLoad = candidate, relative
+		}
+	}
+	return best
+}
+
+// needZone reports whether the owner and backups in res span fewer zones than Config.MinBackupZones.
+func (c *Consistent) needZone(res []Member) bool {
+	if c.config.MinBackupZones <= 1 {
+		return false
+	}
+	zones := make(map[string]struct{}, len(res))
+	for _, member := range res {
+		zones[memberZone(member)] = struct{}{}
+	}
+	return len(zones) < c.config.MinBackupZones
+}
+
+func containsZone(members []Member, zone string) bool {
+	for _, m := range members {
+		if memberZone(m) == zone {
+			return true
+		}
+	}
+	return false
+}
+
+// RefreshBackups computes the backups of every partition again, e.g. after the health of members changed the
+// result of Config.SkipBackup. The owners stay as they are and the version is bumped. It's a no-op while a
+// distribution is pending, which computes the backups anyway. It returns ErrDistributionTimeout if
+// Config.DistributionTimeout runs out, in which case the table is left unchanged.
+func (c *Consistent) RefreshBackups() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if (c.config.BackupCount <= 0 && c.config.ReplicaStrategy == nil) || len(c.partitions) == 0 || c.pending {
+		return nil
+	}
+	c.weightSum = c.totalWeight()
+	replicas, err := c.replicaTable(c.distributionDeadline())
+	if err != nil {
+		return err
+	}
+	c.replicas = replicas
+	c.recordLayout(c.partitions)
+	c.bumpVersion()
+	return nil
+}
+
+func containsMember(members []Member, member Member) bool {
+	for _, m := range members {
+		if m.String() == member.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// BackupLoadDistribution exposes the number of backups each member holds in the table computed for
+// Config.BackupCount. It returns an empty map if BackupCount is zero.
+func (c *Consistent) BackupLoadDistribution() map[string]float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	res := make(map[string]float64)
+	for _, replicas := range c.replicas {
+		for _, member := range replicas[1:] {
+			res[member.String()]++
+		}
+	}
+	return res
+}
+
+// GetPartitionOwnerAndBackups returns the owner of the given partition followed by its backups. The backups are
+// computed at distribution time, there are Config.BackupCount of them unless the ring has fewer members.
+// It returns only the owner if BackupCount is zero and nil if the partition has no owner.
+func (c *Consistent) GetPartitionOwnerAndBackups(partID int) []Member {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.replicas == nil || partID < 0 || partID >= len(c.replicas) {
+		owner := c.getPartitionOwner(partID)
+		if owner == nil {
+			return nil
+		}
+		return []Member{owner}
+	}
+	// Create a thread-safe copy of the replica list.
+	res := make([]Member, len(c.replicas[partID]))
+	copy(res, c.replicas[partID])
+	return res
+}
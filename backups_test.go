@@ -0,0 +1,328 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestConsistentGetPartitionOwnerAndBackups(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+
+	t.Run("Owner followed by distinct backups", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.BackupCount = 2
+		c := New(members, cfg)
+		for partID := 0; partID < cfg.PartitionCount; partID++ {
+			replicas := c.GetPartitionOwnerAndBackups(partID)
+			if len(replicas) != 3 {
+				t.Fatalf("Expected 3 members, Got: %d", len(replicas))
+			}
+			if replicas[0].String() != c.GetPartitionOwner(partID).String() {
+				t.Fatalf("First member should be the partition owner")
+			}
+			seen := make(map[string]struct{})
+			for _, member := range replicas {
+				if _, ok := seen[member.String()]; ok {
+					t.Fatalf("Duplicate member in partition %d: %s", partID, member)
+				}
+				seen[member.String()] = struct{}{}
+			}
+		}
+	})
+
+	t.Run("Backup count is limited by member count", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.BackupCount = 5
+		c := New(members[:3], cfg)
+		if len(c.GetPartitionOwnerAndBackups(0)) != 3 {
+			t.Fatalf("Expected 3 members, Got: %d", len(c.GetPartitionOwnerAndBackups(0)))
+		}
+	})
+
+	t.Run("Only owner without BackupCount", func(t *testing.T) {
+		c := New(members, newConfig())
+		replicas := c.GetPartitionOwnerAndBackups(0)
+		if len(replicas) != 1 || replicas[0].String() != c.GetPartitionOwner(0).String() {
+			t.Fatalf("Expected only the owner, Got: %v", replicas)
+		}
+	})
+
+	t.Run("Empty ring", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.BackupCount = 2
+		c := New(nil, cfg)
+		if replicas := c.GetPartitionOwnerAndBackups(0); replicas != nil {
+			t.Fatalf("Expected nil, Got: %v", replicas)
+		}
+	})
+}
+
+func TestConsistentBackupLoadDistribution(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.BackupCount = 2
+	c := New(members, cfg)
+
+	var total float64
+	maxLoad := math.Ceil(float64(cfg.PartitionCount*cfg.BackupCount) / float64(len(members)) * cfg.Load)
+	for member, load := range c.BackupLoadDistribution() {
+		if load > maxLoad {
+			t.Fatalf("%s exceeds max backup load. Its load: %f, max load: %f", member, load, maxLoad)
+		}
+		total += load
+	}
+	if total != float64(cfg.PartitionCount*cfg.BackupCount) {
+		t.Fatalf("Expected %d backups, Got: %f", cfg.PartitionCount*cfg.BackupCount, total)
+	}
+
+	if len(New(members, newConfig()).BackupLoadDistribution()) != 0 {
+		t.Fatalf("Expected an empty backup load distribution without BackupCount")
+	}
+}
+
+func TestConsistentBackupsSpread(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.BackupCount = 1
+	c := New(members, cfg)
+
+	// The partitions of an owner must not share a single backup, neither in the table nor in GetClosestN.
+	backups := make(map[string]map[string]struct{})
+	closest := make(map[string]map[string]struct{})
+	for partID := 0; partID < cfg.PartitionCount; partID++ {
+		owner := c.GetPartitionOwner(partID).String()
+		if backups[owner] == nil {
+			backups[owner] = make(map[string]struct{})
+			closest[owner] = make(map[string]struct{})
+		}
+		backups[owner][c.GetPartitionOwnerAndBackups(partID)[1].String()] = struct{}{}
+		res, err := c.GetClosestNForPartition(partID, 2)
+		if err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+		if res[0].String() != owner || res[1].String() == owner {
+			t.Fatalf("Expected the owner followed by another member, Got: %v", res)
+		}
+		closest[owner][res[1].String()] = struct{}{}
+	}
+	for owner := range backups {
+		if len(backups[owner]) < 2 || len(closest[owner]) < 2 {
+			t.Fatalf("Partitions of %s are backed up by %d members, closest members: %d",
+				owner, len(backups[owner]), len(closest[owner]))
+		}
+	}
+}
+
+func TestConsistentBackupsDeterministic(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	cfg.BackupCount = 2
+	c := New(members, cfg)
+
+	// Reversed insertion order must not change the backups.
+	other := New(nil, cfg)
+	for i := len(members) - 1; i >= 0; i-- {
+		other.Add(members[i])
+	}
+	for partID := 0; partID < cfg.PartitionCount; partID++ {
+		a, b := c.GetPartitionOwnerAndBackups(partID), other.GetPartitionOwnerAndBackups(partID)
+		for i := range a {
+			if a[i].String() != b[i].String() {
+				t.Fatalf("Backups of partition %d are different: %v, %v", partID, a, b)
+			}
+		}
+		x, _ := c.GetClosestNForPartition(partID, 4)
+		y, _ := other.GetClosestNForPartition(partID, 4)
+		for i := range x {
+			if x[i].String() != y[i].String() {
+				t.Fatalf("Closest members of partition %d are different: %v, %v", partID, x, y)
+			}
+		}
+	}
+}
+
+type zonedMember struct {
+	name string
+	zone string
+}
+
+func (m zonedMember) String() string {
+	return m.name
+}
+
+func (m zonedMember) Zone() string {
+	return m.zone
+}
+
+func TestConsistentBackupsCounterClockwise(t *testing.T) {
+	cfg := newConfig()
+	cfg.BackupCount = 2
+	c := New(testMembers(8), cfg)
+	cfg.BackupDirection = CounterClockwise
+	other := New(testMembers(8), cfg)
+
+	var differ int
+	for partID := 0; partID < cfg.PartitionCount; partID++ {
+		a, b := c.GetPartitionOwnerAndBackups(partID), other.GetPartitionOwnerAndBackups(partID)
+		if a[0].String() != b[0].String() {
+			t.Fatalf("Expected the same owner of partition %d, got: %s, %s", partID, a[0], b[0])
+		}
+		if len(b) != 3 {
+			t.Fatalf("Expected 3 members, got: %v", b)
+		}
+		if a[1].String() != b[1].String() {
+			differ++
+		}
+	}
+	if differ == 0 {
+		t.Fatalf("Expected different backups")
+	}
+}
+
+func TestConsistentSkipBackup(t *testing.T) {
+	down := map[string]bool{"node2.olric": true}
+	cfg := newConfig()
+	cfg.BackupCount = 2
+	cfg.SkipBackup = func(m Member) bool {
+		return down[m.String()]
+	}
+	c := New(testMembers(4), cfg)
+	for partID := 0; partID < cfg.PartitionCount; partID++ {
+		for _, member := range c.GetPartitionOwnerAndBackups(partID)[1:] {
+			if member.String() == "node2.olric" {
+				t.Fatalf("Expected node2.olric to be skipped as a backup of partition %d", partID)
+			}
+		}
+	}
+
+	down = map[string]bool{"node0.olric": true, "node1.olric": true, "node2.olric": true}
+	version := c.Version()
+	if err := c.RefreshBackups(); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if c.Version() != version+1 {
+		t.Fatalf("Expected the version to be bumped")
+	}
+	for partID := 0; partID < cfg.PartitionCount; partID++ {
+		members := c.GetPartitionOwnerAndBackups(partID)
+		for _, member := range members[1:] {
+			if down[member.String()] {
+				t.Fatalf("Expected %s to be skipped as a backup of partition %d", member, partID)
+			}
+		}
+		if len(members) > 2 {
+			t.Fatalf("Expected at most one backup, got: %v", members)
+		}
+	}
+}
+
+func TestConsistentMinBackupZones(t *testing.T) {
+	var members []Member
+	for i := 0; i < 9; i++ {
+		members = append(members, zonedMember{name: fmt.Sprintf("node%d.olric", i), zone: fmt.Sprintf("zone%d", i%3)})
+	}
+	cfg := newConfig()
+	cfg.BackupCount = 2
+	cfg.MinBackupZones = 3
+	c := New(members, cfg)
+	for partID := 0; partID < cfg.PartitionCount; partID++ {
+		zones := make(map[string]struct{})
+		for _, member := range c.GetPartitionOwnerAndBackups(partID) {
+			zones[member.(zonedMember).zone] = struct{}{}
+		}
+		if len(zones) != 3 {
+			t.Fatalf("Expected 3 zones for partition %d, got: %v", partID, c.GetPartitionOwnerAndBackups(partID))
+		}
+	}
+
+	// With fewer zones than required, the backups still fill up.
+	cfg.MinBackupZones = 5
+	c = New(members, cfg)
+	for partID := 0; partID < cfg.PartitionCount; partID++ {
+		if n := len(c.GetPartitionOwnerAndBackups(partID)); n != 3 {
+			t.Fatalf("Expected 3 members for partition %d, got: %d", partID, n)
+		}
+	}
+}
+
+func TestConsistentLocateKeyNear(t *testing.T) {
+	var members []Member
+	for i := 0; i < 6; i++ {
+		members = append(members, zonedMember{name: fmt.Sprintf("node%d.olric", i), zone: fmt.Sprintf("zone%d", i%3)})
+	}
+	cfg := newConfig()
+	cfg.BackupCount = 2
+	cfg.MinBackupZones = 3
+	c := New(members, cfg)
+
+	for i := 0; i < 100; i++ {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		for _, zone := range []string{"zone0", "zone1", "zone2"} {
+			member := c.LocateKeyNear(key, zone)
+			if member.(zonedMember).zone != zone {
+				t.Fatalf("Expected a member in %s for %s, got: %v", zone, key, member)
+			}
+			if !containsMember(c.GetPartitionOwnerAndBackups(c.FindPartitionID(key)), member) {
+				t.Fatalf("Expected the owner or a backup of %s, got: %s", key, member)
+			}
+		}
+		if member := c.LocateKeyNear(key, "elsewhere"); member.String() != c.LocateKey(key).String() {
+			t.Fatalf("Expected the owner of %s, got: %s", key, member)
+		}
+	}
+
+	if member := New(nil, cfg).LocateKeyNear([]byte("my-key"), "zone0"); member != nil {
+		t.Fatalf("Expected nil, got: %v", member)
+	}
+}
+
+func BenchmarkGetPartitionOwnerAndBackups(b *testing.B) {
+	cfg := newConfig()
+	cfg.BackupCount = 2
+	c := New(nil, cfg)
+	for i := 0; i < 10; i++ {
+		c.Add(testMember(fmt.Sprintf("node%d", i)))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.GetPartitionOwnerAndBackups(i % cfg.PartitionCount)
+	}
+}
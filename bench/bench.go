@@ -0,0 +1,196 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package bench provides reproducible workloads to measure the performance of consistent hash rings. Downstream
+// users can compare configurations with it and the project uses it to detect performance regressions.
+//
+// Example Use:
+//
+//	res := bench.Run(bench.LookupHeavy, bench.Options{Config: cfg, Members: 32})
+//	fmt.Println(res)
+package bench
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/buraksezer/consistent"
+)
+
+const (
+	// DefaultMembers is the number of members added before a workload starts.
+	DefaultMembers = 16
+
+	// DefaultSeed is used to generate keys and member names if Options.Seed is zero.
+	DefaultSeed int64 = 1
+
+	// keyCount is the number of pre-generated keys. Workloads cycle through them.
+	keyCount = 1 << 16
+
+	// mixedChurnInterval is the number of operations between two membership changes in the Mixed workload.
+	mixedChurnInterval = 100
+)
+
+// Workload represents a reproducible sequence of operations on a consistent hash ring.
+type Workload int
+
+const (
+	// LookupHeavy only locates keys.
+	LookupHeavy Workload = iota
+
+	// ChurnHeavy adds and removes a member in every operation.
+	ChurnHeavy
+
+	// Mixed locates keys and changes the membership once in every 100 operations.
+	Mixed
+)
+
+func (w Workload) String() string {
+	switch w {
+	case LookupHeavy:
+		return "lookup-heavy"
+	case ChurnHeavy:
+		return "churn-heavy"
+	case Mixed:
+		return "mixed"
+	default:
+		return "unknown"
+	}
+}
+
+// Options controls a benchmark run.
+type Options struct {
+	// Config is used to create the consistent hash ring. A 64-bit FNV-1a hasher is used if Config.Hasher is nil.
+	Config consistent.Config
+
+	// Members is the number of members added before the workload starts. DefaultMembers is used if it is zero.
+	Members int
+
+	// Seed is used to generate keys and member names. DefaultSeed is used if it is zero. Runs with the same
+	// options execute exactly the same operations.
+	Seed int64
+}
+
+// Result represents the outcome of a benchmark run.
+type Result struct {
+	Workload    Workload
+	N           int
+	NsPerOp     int64
+	OpsPerSec   float64
+	AllocsPerOp int64
+	BytesPerOp  int64
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%s: %d ops, %d ns/op, %.0f ops/sec, %d allocs/op, %d B/op",
+		r.Workload, r.N, r.NsPerOp, r.OpsPerSec, r.AllocsPerOp, r.BytesPerOp)
+}
+
+// Regressed reports whether r is slower than the baseline by more than tolerance, or allocates more per operation.
+// A tolerance of 0.1 accepts results up to 10% slower than the baseline.
+func (r Result) Regressed(baseline Result, tolerance float64) bool {
+	if r.AllocsPerOp > baseline.AllocsPerOp {
+		return true
+	}
+	return float64(r.NsPerOp) > float64(baseline.NsPerOp)*(1+tolerance)
+}
+
+type hasher struct{}
+
+func (hasher) Sum64(data []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(data)
+	return h.Sum64()
+}
+
+type member string
+
+func (m member) String() string {
+	return string(m)
+}
+
+// Run executes the workload with the given options and returns its result.
+func Run(w Workload, opts Options) Result {
+	if opts.Config.Hasher == nil {
+		opts.Config.Hasher = hasher{}
+	}
+	if opts.Members == 0 {
+		opts.Members = DefaultMembers
+	}
+	if opts.Seed == 0 {
+		opts.Seed = DefaultSeed
+	}
+
+	r := rand.New(rand.NewSource(opts.Seed))
+	keys := make([][]byte, keyCount)
+	for i := range keys {
+		keys[i] = []byte("key" + strconv.FormatUint(r.Uint64(), 10))
+	}
+	prefix := "node-" + strconv.FormatUint(r.Uint64(), 36) + "-"
+	members := make([]consistent.Member, 0, opts.Members)
+	for i := 0; i < opts.Members; i++ {
+		members = append(members, member(prefix+strconv.Itoa(i)))
+	}
+
+	res := testing.Benchmark(func(b *testing.B) {
+		c := consistent.New(members, opts.Config)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			switch w {
+			case LookupHeavy:
+				c.LocateKey(keys[i%keyCount])
+			case ChurnHeavy:
+				churn(c, prefix)
+			case Mixed:
+				if i%mixedChurnInterval == 0 {
+					churn(c, prefix)
+					continue
+				}
+				c.LocateKey(keys[i%keyCount])
+			}
+		}
+	})
+
+	result := Result{
+		Workload:    w,
+		N:           res.N,
+		NsPerOp:     res.NsPerOp(),
+		AllocsPerOp: res.AllocsPerOp(),
+		BytesPerOp:  res.AllocedBytesPerOp(),
+	}
+	if res.T > 0 {
+		result.OpsPerSec = float64(res.N) / res.T.Seconds()
+	}
+	return result
+}
+
+// churn adds a temporary member to the ring and removes it again.
+func churn(c *consistent.Consistent, prefix string) {
+	m := member(prefix + "churn")
+	c.Add(m)
+	c.Remove(m.String())
+}
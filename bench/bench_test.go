@@ -0,0 +1,66 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package bench
+
+import (
+	"testing"
+
+	"github.com/buraksezer/consistent"
+)
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping benchmark workloads in short mode")
+	}
+	opts := Options{
+		Config: consistent.Config{
+			PartitionCount:    71,
+			ReplicationFactor: 20,
+			Load:              1.25,
+		},
+		Members: 8,
+	}
+	for _, w := range []Workload{LookupHeavy, ChurnHeavy, Mixed} {
+		res := Run(w, opts)
+		if res.Workload != w {
+			t.Fatalf("Expected workload %s, Got: %s", w, res.Workload)
+		}
+		if res.N == 0 || res.NsPerOp <= 0 || res.OpsPerSec <= 0 {
+			t.Fatalf("Unexpected result: %s", res)
+		}
+	}
+}
+
+func TestResultRegressed(t *testing.T) {
+	baseline := Result{NsPerOp: 100, AllocsPerOp: 1}
+	if (Result{NsPerOp: 105, AllocsPerOp: 1}).Regressed(baseline, 0.1) {
+		t.Fatalf("5%% slower result should be within 10%% tolerance")
+	}
+	if !(Result{NsPerOp: 120, AllocsPerOp: 1}).Regressed(baseline, 0.1) {
+		t.Fatalf("20%% slower result should be a regression")
+	}
+	if !(Result{NsPerOp: 90, AllocsPerOp: 2}).Regressed(baseline, 0.1) {
+		t.Fatalf("More allocations should be a regression")
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// AddFromReader adds the members listed in r, one per line, with a single distribution for all of them, e.g.
+// to load a member list from a file or an API response. Blank lines and lines starting with # are skipped,
+// the others are trimmed and converted by parse; if it's nil, the members are plain names. Members are added
+// like Add, following Config.OnDuplicateAdd. Nothing is added if reading or parsing fails or if one of the members
+// can't be added like TryAdd, the error tells the line then, e.g. ErrInvalidMemberName for a member whose name
+// is empty after Config.NormalizeName or ErrMemberAlreadyExists for a duplicate with RejectDuplicate.
+func (c *Consistent) AddFromReader(r io.Reader, parse func(line string) (Member, error)) error {
+	var members []Member
+	var lines []int
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		member, err := parseMember(line, parse)
+		if err == nil && !c.validName(member.String()) {
+			err = ErrInvalidMemberName
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+		members = append(members, member)
+		lines = append(lines, n)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if i, err := c.addMembers(members); err != nil {
+		return fmt.Errorf("line %d: %w", lines[i], err)
+	}
+	return nil
+}
+
+// AddFromJSON adds the members of the JSON array in r like AddFromReader. decode converts the elements; if it's
+// nil, the elements must be strings, which are taken as plain names. The error tells the index of the element
+// which failed.
+func (c *Consistent) AddFromJSON(r io.Reader, decode func(element json.RawMessage) (Member, error)) error {
+	var elements []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&elements); err != nil {
+		return err
+	}
+	members := make([]Member, 0, len(elements))
+	for i, element := range elements {
+		var member Member
+		var err error
+		if decode != nil {
+			member, err = decode(element)
+		} else {
+			var name string
+			if err = json.Unmarshal(element, &name); err == nil {
+				member, err = parseMember(name, nil)
+			}
+		}
+		if err == nil && (member == nil || !c.validName(member.String())) {
+			err = ErrInvalidMemberName
+		}
+		if err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+		members = append(members, member)
+	}
+	if i, err := c.addMembers(members); err != nil {
+		return fmt.Errorf("element %d: %w", i, err)
+	}
+	return nil
+}
+
+// parseMember converts a line of AddFromReader with parse, or to a plain name if parse is nil.
+func parseMember(line string, parse func(line string) (Member, error)) (Member, error) {
+	if parse == nil {
+		return nameMember(line), nil
+	}
+	member, err := parse(line)
+	if err == nil && member == nil {
+		err = ErrInvalidMemberName
+	}
+	return member, err
+}
+
+// addMembers adds the members like Add with one distribution for all of them. If one of them can't be added
+// like TryAdd, nothing is added and it returns the index of the member with the error.
+func (c *Consistent) addMembers(members []Member) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if i, err := c.checkMembers(members); err != nil {
+		return i, err
+	}
+	changes := c.changes
+	c.batching = true
+	var failed int
+	var err error
+	for i, member := range members {
+		// checkMembers rules out the errors, it's a bug if one slips through. The members before it stay.
+		if err = c.tryAdd(member, 0); err != nil {
+			failed = i
+			break
+		}
+	}
+	c.batching = false
+	if c.changes != changes {
+		c.redistribute()
+	}
+	return failed, err
+}
+
+// checkMembers returns the index of the first member which TryAdd would reject, taking the members before it
+// into account, and the error. It's not thread-safe.
+func (c *Consistent) checkMembers(members []Member) (int, error) {
+	// The members of the batch aren't on the ring yet, so memberName can't match them: key them by the
+	// normalized name.
+	added := make(map[string]Member)
+	for i, member := range members {
+		key := c.normalize(member.String())
+		current, ok := added[key]
+		if !ok {
+			if m, exists := c.members[c.memberName(member.String())]; exists {
+				current, ok = *m, true
+			}
+		}
+		if !ok {
+			added[key] = member
+			continue
+		}
+		policy, err := c.duplicatePolicy(current, member)
+		if err != nil {
+			return i, err
+		}
+		if policy == ReplaceDuplicate {
+			added[key] = member
+		}
+	}
+	return 0, nil
+}
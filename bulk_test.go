@@ -0,0 +1,174 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAddFromReader(t *testing.T) {
+	c := New(nil, newConfig())
+	input := `# members
+node0.olric
+
+  node1.olric
+node2.olric:3
+node3.olric:1
+node0.olric
+`
+	parse := func(line string) (Member, error) {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) == 1 {
+			return testMember(parts[0]), nil
+		}
+		weight, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		return weightedMember{name: parts[0], weight: weight}, nil
+	}
+	if err := c.AddFromReader(strings.NewReader(input), parse); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if got := len(c.GetMembers()); got != 4 {
+		t.Fatalf("Expected 4 members, got: %d", got)
+	}
+	if c.Version() != 1 {
+		t.Fatalf("Expected a single distribution, got version: %d", c.Version())
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+
+	err := c.AddFromReader(strings.NewReader("node4.olric\nnode5.olric:x\n"), parse)
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Fatalf("Expected an error on line 2, got: %v", err)
+	}
+	if got := len(c.GetMembers()); got != 4 {
+		t.Fatalf("Expected nothing to be added, got %d members", got)
+	}
+
+	version := c.Version()
+	if err := c.AddFromReader(strings.NewReader("node0.olric\n"), nil); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if c.Version() != version {
+		t.Fatalf("Expected no distribution for existing members")
+	}
+}
+
+func TestAddFromJSON(t *testing.T) {
+	c := New(nil, newConfig())
+	if err := c.AddFromJSON(strings.NewReader(`["node0.olric", "node1.olric", "node2.olric"]`), nil); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if got := len(c.GetMembers()); got != 3 || c.Version() != 1 {
+		t.Fatalf("Expected 3 members in a single distribution, got: %d, version %d", got, c.Version())
+	}
+
+	decode := func(element json.RawMessage) (Member, error) {
+		var m struct {
+			Name   string  `json:"name"`
+			Weight float64 `json:"weight"`
+		}
+		if err := json.Unmarshal(element, &m); err != nil {
+			return nil, err
+		}
+		return weightedMember{name: m.Name, weight: m.Weight}, nil
+	}
+	input := `[{"name": "node3.olric", "weight": 2}, {"name": "", "weight": 1}]`
+	if err := c.AddFromJSON(strings.NewReader(input), decode); !errors.Is(err, ErrInvalidMemberName) {
+		t.Fatalf("Expected ErrInvalidMemberName, got: %v", err)
+	}
+	if err := c.AddFromJSON(strings.NewReader(`{}`), nil); err == nil {
+		t.Fatalf("Expected an error for a JSON object")
+	}
+	input = `[{"name": "node3.olric", "weight": 2}, {"name": "node4.olric", "weight": 1}]`
+	if err := c.AddFromJSON(strings.NewReader(input), decode); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if got := len(c.GetMembers()); got != 5 || c.Version() != 2 {
+		t.Fatalf("Expected 5 members after two distributions, got: %d, version %d", got, c.Version())
+	}
+}
+
+func TestAddFromReaderDuplicate(t *testing.T) {
+	cfg := newConfig()
+	cfg.OnDuplicateAdd = RejectDuplicate
+	c := New(testMembers(2), cfg)
+	version := c.Version()
+
+	err := c.AddFromReader(strings.NewReader("node2.olric\nnode3.olric\nnode2.olric\n"), nil)
+	if !errors.Is(err, ErrMemberAlreadyExists) || !strings.HasPrefix(err.Error(), "line 3:") {
+		t.Fatalf("Expected ErrMemberAlreadyExists on line 3, got: %v", err)
+	}
+	err = c.AddFromReader(strings.NewReader("node2.olric\nnode0.olric\n"), nil)
+	if !errors.Is(err, ErrMemberAlreadyExists) || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Fatalf("Expected ErrMemberAlreadyExists on line 2, got: %v", err)
+	}
+	if got := len(c.GetMembers()); got != 2 || c.Version() != version {
+		t.Fatalf("Expected nothing to be added, got %d members, version %d", got, c.Version())
+	}
+
+	c = New(nil, newConfig())
+	c.Add(generationMember{name: "node0.olric", generation: 2})
+	decode := func(element json.RawMessage) (Member, error) {
+		var m struct {
+			Name       string `json:"name"`
+			Generation uint64 `json:"generation"`
+		}
+		if err := json.Unmarshal(element, &m); err != nil {
+			return nil, err
+		}
+		return generationMember{name: m.Name, generation: m.Generation}, nil
+	}
+	input := `[{"name": "node1.olric", "generation": 1}, {"name": "node0.olric", "generation": 1}]`
+	err = c.AddFromJSON(strings.NewReader(input), decode)
+	if !errors.Is(err, ErrStaleGeneration) || !strings.HasPrefix(err.Error(), "element 1:") {
+		t.Fatalf("Expected ErrStaleGeneration on element 1, got: %v", err)
+	}
+	if got := len(c.GetMembers()); got != 1 {
+		t.Fatalf("Expected nothing to be added, got %d members", got)
+	}
+}
+
+func TestAddFromReaderNormalizedDuplicate(t *testing.T) {
+	cfg := newConfig()
+	cfg.NormalizeName = strings.ToLower
+	cfg.OnDuplicateAdd = RejectDuplicate
+	c := New(testMembers(2), cfg)
+	version := c.Version()
+
+	err := c.AddFromReader(strings.NewReader("Node9\nnode9\n"), nil)
+	if !errors.Is(err, ErrMemberAlreadyExists) || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Fatalf("Expected ErrMemberAlreadyExists on line 2, got: %v", err)
+	}
+	if got := len(c.GetMembers()); got != 2 || c.Version() != version {
+		t.Fatalf("Expected nothing to be added, got %d members, version %d", got, c.Version())
+	}
+}
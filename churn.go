@@ -0,0 +1,133 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"time"
+)
+
+// churnRetention is the longest window of ChurnStats.
+const churnRetention = time.Hour
+
+// ChurnStats describes the partition moves in the sliding windows of the last minute, five minutes and hour, as
+// told by Config.Clock, e.g. to alert on abnormal churn. The windows are measured when ChurnStats is called.
+type ChurnStats struct {
+	LastMinute   Churn
+	Last5Minutes Churn
+	LastHour     Churn
+}
+
+// Churn describes the changes of the partition table in a sliding window, see ChurnStats.
+type Churn struct {
+	// Changes is the number of changes of the partition table which moved partitions.
+	Changes int
+
+	// Moved is the number of partitions which moved. A partition which moved twice counts twice.
+	Moved int
+
+	// Members is the number of distinct members which gained or lost partitions.
+	Members int
+
+	// LargestChange is the largest number of partitions moved by a single change.
+	LargestChange int
+}
+
+// churnEvent is a change of the partition table which moved partitions.
+type churnEvent struct {
+	at      time.Time
+	moved   int
+	members []string
+}
+
+// recordChurn remembers the partitions which moved from the previous partition table to the current one for the
+// churn of ChurnStats, and forgets the changes older than an hour. It's not thread-safe.
+func (c *Consistent) recordChurn(previous map[int]*Member) {
+	if c.detached {
+		return
+	}
+	now := c.clock().Now()
+	expired := 0
+	for expired < len(c.churn) && now.Sub(c.churn[expired].at) >= churnRetention {
+		expired++
+	}
+	c.churn = c.churn[expired:]
+
+	affected := make(map[string]struct{})
+	var moved int
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		from, fromOK := previous[partID]
+		to, toOK := c.partitions[partID]
+		if fromOK && toOK && (*from).String() == (*to).String() || !fromOK && !toOK {
+			continue
+		}
+		moved++
+		if fromOK {
+			affected[(*from).String()] = struct{}{}
+		}
+		if toOK {
+			affected[(*to).String()] = struct{}{}
+		}
+	}
+	if moved == 0 {
+		return
+	}
+	e := churnEvent{at: now, moved: moved, members: make([]string, 0, len(affected))}
+	for name := range affected {
+		e.members = append(e.members, name)
+	}
+	c.churn = append(c.churn, e)
+}
+
+// ChurnStats returns the churn of the partition table in the last minute, five minutes and hour. Changes which
+// only moved backups don't count.
+func (c *Consistent) ChurnStats() ChurnStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := c.clock().Now()
+	return ChurnStats{
+		LastMinute:   c.churnSince(now.Add(-time.Minute)),
+		Last5Minutes: c.churnSince(now.Add(-5 * time.Minute)),
+		LastHour:     c.churnSince(now.Add(-churnRetention)),
+	}
+}
+
+// churnSince sums up the changes after the given time. It's not thread-safe.
+func (c *Consistent) churnSince(since time.Time) Churn {
+	var res Churn
+	members := make(map[string]struct{})
+	for i := len(c.churn) - 1; i >= 0 && c.churn[i].at.After(since); i-- {
+		e := c.churn[i]
+		res.Changes++
+		res.Moved += e.moved
+		if e.moved > res.LargestChange {
+			res.LargestChange = e.moved
+		}
+		for _, name := range e.members {
+			members[name] = struct{}{}
+		}
+	}
+	res.Members = len(members)
+	return res
+}
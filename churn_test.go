@@ -0,0 +1,70 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChurnStats(t *testing.T) {
+	clock := newFakeClock()
+	cfg := newConfigWith(271)
+	cfg.Clock = clock
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, cfg)
+
+	clock.Advance(2 * time.Minute)
+	before := c.owners()
+	c.Add(testMember("node2.olric"))
+	var moved int
+	for partID, owner := range c.owners() {
+		if owner.String() != before[partID].String() {
+			moved++
+		}
+	}
+
+	s := c.ChurnStats()
+	if s.LastMinute != (Churn{Changes: 1, Moved: moved, Members: 3, LargestChange: moved}) {
+		t.Fatalf("Unexpected churn in the last minute: %+v", s.LastMinute)
+	}
+	// The initial distribution moved every partition.
+	if s.Last5Minutes != (Churn{Changes: 2, Moved: 271 + moved, Members: 3, LargestChange: 271}) {
+		t.Fatalf("Unexpected churn in the last five minutes: %+v", s.Last5Minutes)
+	}
+
+	clock.Advance(10 * time.Minute)
+	s = c.ChurnStats()
+	if s.LastMinute != (Churn{}) || s.Last5Minutes != (Churn{}) || s.LastHour.Changes != 2 {
+		t.Fatalf("Unexpected churn: %+v", s)
+	}
+
+	clock.Advance(time.Hour)
+	if s := c.ChurnStats(); s.LastHour != (Churn{}) {
+		t.Fatalf("Expected no churn in the last hour, Got: %+v", s.LastHour)
+	}
+	c.Remove("node2.olric")
+	if len(c.churn) != 1 {
+		t.Fatalf("Expected the old changes to be forgotten, Got: %d", len(c.churn))
+	}
+}
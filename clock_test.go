@@ -0,0 +1,170 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose time only moves on Advance, which runs the timers that fire in the meantime.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock  *fakeClock
+	at     time.Time
+	f      func()
+	active bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, at: c.now.Add(d), f: f, active: true}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.active
+	t.active = false
+	return active
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.active
+	t.at, t.active = t.clock.now.Add(d), true
+	return active
+}
+
+// Advance moves the time forward and runs the timers due until then in the order of their deadlines.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+	for {
+		c.mu.Lock()
+		sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].at.Before(c.timers[j].at) })
+		var due *fakeTimer
+		for _, t := range c.timers {
+			if t.active && !t.at.After(end) {
+				due = t
+				break
+			}
+		}
+		if due == nil {
+			c.now = end
+			c.mu.Unlock()
+			return
+		}
+		c.now, due.active = due.at, false
+		c.mu.Unlock()
+		due.f()
+	}
+}
+
+func TestClockWarmup(t *testing.T) {
+	clock := newFakeClock()
+	cfg := newConfigWith(271)
+	cfg.Clock = clock
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, cfg)
+	c.AddWithWarmup(testMember("node2.olric"), 10*time.Minute)
+	if share := c.Warming("node2.olric"); share != DefaultWarmupStart {
+		t.Fatalf("Expected %g, Got: %g", DefaultWarmupStart, share)
+	}
+
+	version := c.Version()
+	clock.Advance(5 * time.Minute)
+	if share := c.Warming("node2.olric"); share < 0.54 || share > 0.56 {
+		t.Fatalf("Expected 0.55, Got: %g", share)
+	}
+	// One distribution at every step.
+	if c.Version() != version+5 {
+		t.Fatalf("Expected version %d, Got: %d", version+5, c.Version())
+	}
+
+	clock.Advance(5 * time.Minute)
+	if c.Warming("node2.olric") != 1 {
+		t.Fatalf("Expected the ramp to be over")
+	}
+	if !c.Equal(New([]Member{testMember("node0.olric"), testMember("node1.olric"), testMember("node2.olric")}, newConfigWith(271))) {
+		t.Fatalf("Expected the layout without warmup")
+	}
+}
+
+func TestClockDebounce(t *testing.T) {
+	clock := newFakeClock()
+	cfg := newConfig()
+	cfg.Clock = clock
+	cfg.DistributionDebounce = time.Second
+	c := New([]Member{testMember("node0.olric")}, cfg)
+	c.Add(testMember("node1.olric"))
+	clock.Advance(500 * time.Millisecond)
+	c.Add(testMember("node2.olric"))
+	clock.Advance(500 * time.Millisecond)
+	if !c.Pending() {
+		t.Fatalf("Expected the distribution to wait for a quiet second")
+	}
+	clock.Advance(500 * time.Millisecond)
+	if c.Pending() || len(c.LoadDistribution()) != 3 {
+		t.Fatalf("Expected the distribution to run")
+	}
+}
+
+func TestClockResidency(t *testing.T) {
+	clock := newFakeClock()
+	cfg := newConfig()
+	cfg.Clock = clock
+	cfg.MinResidency = time.Minute
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, cfg)
+	c.Add(testMember("node2.olric"))
+	if len(c.movedAt) == 0 {
+		t.Fatalf("Expected moved partitions")
+	}
+	for partID, movedAt := range c.movedAt {
+		if !movedAt.Equal(clock.Now()) {
+			t.Fatalf("Partition %d: expected the time of the clock, Got: %v", partID, movedAt)
+		}
+	}
+}
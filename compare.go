@@ -0,0 +1,191 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Report quantifies the difference between two partition layouts, see CompareLayouts.
+type Report struct {
+	// PartitionCount is the larger of the partition counts of the layouts.
+	PartitionCount int
+
+	// Moved is the number of partitions whose owner differs between the layouts. A partition which exists in only
+	// one of the layouts counts as moved.
+	Moved int
+
+	// MovedFraction is Moved divided by PartitionCount.
+	MovedFraction float64
+
+	// BackupsChanged is the number of partitions whose backups differ between the layouts, in any order.
+	BackupsChanged int
+
+	// Gained maps member names to the number of partitions they own in the second layout but not in the first one.
+	Gained map[string]int
+
+	// Lost maps member names to the number of partitions they own in the first layout but not in the second one.
+	Lost map[string]int
+
+	// MaxGained is the largest number of partitions gained by a single member.
+	MaxGained int
+
+	// MaxLost is the largest number of partitions lost by a single member.
+	MaxLost int
+}
+
+// String returns a short summary of the report.
+func (r Report) String() string {
+	return fmt.Sprintf("Report{moved: %d/%d (%.2f%%), backups changed: %d, max gained: %d, max lost: %d}",
+		r.Moved, r.PartitionCount, 100*r.MovedFraction, r.BackupsChanged, r.MaxGained, r.MaxLost)
+}
+
+// CompareLayouts compares the partition tables of two snapshots, e.g. the layouts computed by two versions of the
+// library or by two configurations for the same members, and returns the cost of migrating from a to b. Owners
+// are compared by name.
+func CompareLayouts(a, b Snapshot) Report {
+	n := len(a.Partitions)
+	if len(b.Partitions) > n {
+		n = len(b.Partitions)
+	}
+	r := Report{
+		PartitionCount: n,
+		Gained:         make(map[string]int),
+		Lost:           make(map[string]int),
+	}
+	for partID := 0; partID < n; partID++ {
+		from, to := partitionOwnerName(a, partID), partitionOwnerName(b, partID)
+		if !sameBackups(partitionBackups(a, partID), partitionBackups(b, partID)) {
+			r.BackupsChanged++
+		}
+		if from == to {
+			continue
+		}
+		r.Moved++
+		if from != "" {
+			r.Lost[from]++
+			if r.Lost[from] > r.MaxLost {
+				r.MaxLost = r.Lost[from]
+			}
+		}
+		if to != "" {
+			r.Gained[to]++
+			if r.Gained[to] > r.MaxGained {
+				r.MaxGained = r.Gained[to]
+			}
+		}
+	}
+	if n > 0 {
+		r.MovedFraction = float64(r.Moved) / float64(n)
+	}
+	return r
+}
+
+// Stickiness tells how many partitions kept their owners across a change, see StickinessReport. For a cache
+// whose keys spread evenly over the partitions, it estimates the share of the cached keys which are still served
+// by the member that cached them.
+type Stickiness struct {
+	// Members maps the names of the members which owned partitions before the change to their stickiness.
+	Members map[string]MemberStickiness
+
+	// Retained is the number of partitions which kept their owner.
+	Retained int
+
+	// HitRate is Retained divided by the number of partitions which had an owner before the change, one if
+	// there were none.
+	HitRate float64
+}
+
+// MemberStickiness tells how many of its partitions a member kept across a change.
+type MemberStickiness struct {
+	// Before is the number of partitions the member owned before the change.
+	Before int
+
+	// Retained is the number of those partitions the member still owns after the change.
+	Retained int
+
+	// HitRate is Retained divided by Before.
+	HitRate float64
+}
+
+// StickinessReport compares the owners of the partitions before and after a change, e.g. the snapshots of a ring
+// before and after a member joined, and reports how many partitions every member retained. Owners are compared by
+// name.
+func StickinessReport(before, after Snapshot) Stickiness {
+	r := Stickiness{Members: make(map[string]MemberStickiness)}
+	var owned int
+	for partID, from := range before.Partitions {
+		if from == "" {
+			continue
+		}
+		owned++
+		m := r.Members[from]
+		m.Before++
+		if partitionOwnerName(after, partID) == from {
+			m.Retained++
+			r.Retained++
+		}
+		r.Members[from] = m
+	}
+	for name, m := range r.Members {
+		m.HitRate = float64(m.Retained) / float64(m.Before)
+		r.Members[name] = m
+	}
+	r.HitRate = 1
+	if owned > 0 {
+		r.HitRate = float64(r.Retained) / float64(owned)
+	}
+	return r
+}
+
+// partitionOwnerName returns the name of the owner of the partition in the snapshot, or an empty string.
+func partitionOwnerName(s Snapshot, partID int) string {
+	if partID >= len(s.Partitions) {
+		return ""
+	}
+	return s.Partitions[partID]
+}
+
+// partitionBackups returns the sorted names of the backups of the partition in the snapshot.
+func partitionBackups(s Snapshot, partID int) []string {
+	if partID >= len(s.Backups) || len(s.Backups[partID]) == 0 {
+		return nil
+	}
+	backups := append([]string(nil), s.Backups[partID][1:]...)
+	sort.Strings(backups)
+	return backups
+}
+
+func sameBackups(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
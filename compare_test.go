@@ -0,0 +1,159 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCompareLayouts(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.BackupCount = 1
+	c := New(members, cfg)
+	before := c.Snapshot()
+
+	r := CompareLayouts(before, before)
+	if r.Moved != 0 || r.BackupsChanged != 0 || len(r.Gained) != 0 || len(r.Lost) != 0 {
+		t.Fatalf("Expected no movement, Got: %v", r)
+	}
+
+	c.Add(testMember("node8.olric"))
+	after := c.Snapshot()
+	r = CompareLayouts(before, after)
+	if r.PartitionCount != cfg.PartitionCount {
+		t.Fatalf("Expected %d partitions, Got: %d", cfg.PartitionCount, r.PartitionCount)
+	}
+	moves := len(New(members, cfg).Diff(c))
+	if r.Moved != moves {
+		t.Fatalf("Expected %d moved partitions, Got: %d", moves, r.Moved)
+	}
+	if r.MovedFraction != float64(moves)/float64(cfg.PartitionCount) {
+		t.Fatalf("Unexpected moved fraction: %f", r.MovedFraction)
+	}
+	// The new member gains the most partitions. Bounded loads may move a few between the other members too.
+	if r.Gained["node8.olric"] != r.MaxGained || c.LoadDistribution()["node8.olric"] != float64(r.MaxGained) {
+		t.Fatalf("Expected node8.olric to gain the most partitions, Got: %v", r.Gained)
+	}
+	var gained, lost int
+	for _, count := range r.Gained {
+		gained += count
+	}
+	if gained != r.Moved {
+		t.Fatalf("Expected %d gained partitions, Got: %d", r.Moved, gained)
+	}
+	for member, count := range r.Lost {
+		if count > r.MaxLost {
+			t.Fatalf("%s lost %d partitions, more than MaxLost: %d", member, count, r.MaxLost)
+		}
+		lost += count
+	}
+	if lost != r.Moved {
+		t.Fatalf("Expected %d lost partitions, Got: %d", r.Moved, lost)
+	}
+	if r.BackupsChanged == 0 {
+		t.Fatalf("Expected changed backups")
+	}
+}
+
+func TestCompareLayoutsPartitionCount(t *testing.T) {
+	members := []Member{testMember("node0.olric"), testMember("node1.olric")}
+	a := New(members, newConfig()).Snapshot()
+	cfg := newConfig()
+	cfg.PartitionCount = 29
+	b := New(members, cfg).Snapshot()
+
+	r := CompareLayouts(a, b)
+	if r.PartitionCount != 29 {
+		t.Fatalf("Expected 29 partitions, Got: %d", r.PartitionCount)
+	}
+	var gained int
+	for _, count := range r.Gained {
+		gained += count
+	}
+	if gained != r.Moved {
+		t.Fatalf("Expected %d gained partitions, Got: %d", r.Moved, gained)
+	}
+	if r.Moved < 29-23 {
+		t.Fatalf("Partitions which exist only in b must count as moved, Got: %d", r.Moved)
+	}
+
+	empty := CompareLayouts(Snapshot{}, Snapshot{})
+	if empty.Moved != 0 || empty.MovedFraction != 0 {
+		t.Fatalf("Expected an empty report, Got: %v", empty)
+	}
+}
+
+func TestStickinessReport(t *testing.T) {
+	c := New(testMembers(4), newConfig())
+	before := c.Snapshot()
+	c.Add(testMember("node4.olric"))
+	after := c.Snapshot()
+
+	r := StickinessReport(before, after)
+	moved := CompareLayouts(before, after).Moved
+	if r.Retained != 23-moved {
+		t.Fatalf("Expected %d retained partitions, got: %d", 23-moved, r.Retained)
+	}
+	if r.HitRate != float64(23-moved)/23 {
+		t.Fatalf("Unexpected hit rate: %v", r.HitRate)
+	}
+	if len(r.Members) != 4 {
+		t.Fatalf("Expected 4 members, got: %v", r.Members)
+	}
+	var retained int
+	for name, m := range r.Members {
+		if m.Before != ownedBy(before, name) {
+			t.Fatalf("Expected %s to own %d partitions before, got: %d", name, ownedBy(before, name), m.Before)
+		}
+		if m.HitRate != float64(m.Retained)/float64(m.Before) {
+			t.Fatalf("Unexpected hit rate of %s: %v", name, m.HitRate)
+		}
+		retained += m.Retained
+	}
+	if retained != r.Retained {
+		t.Fatalf("Expected %d retained partitions, got: %d", r.Retained, retained)
+	}
+
+	if r := StickinessReport(after, after); r.HitRate != 1 || r.Retained != 23 {
+		t.Fatalf("Expected every partition to be retained, got: %+v", r)
+	}
+	if r := StickinessReport(Snapshot{}, after); r.HitRate != 1 || len(r.Members) != 0 {
+		t.Fatalf("Expected an empty report, got: %+v", r)
+	}
+}
+
+func ownedBy(s Snapshot, name string) (n int) {
+	for _, owner := range s.Partitions {
+		if owner == name {
+			n++
+		}
+	}
+	return n
+}
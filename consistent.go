@@ -57,12 +57,13 @@
 package consistent
 
 import (
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
+	"reflect"
 	"sort"
 	"sync"
+	"time"
 )
 
 const (
@@ -71,8 +72,104 @@ const (
 	DefaultLoad              float64 = 1.25
 )
 
-// ErrInsufficientMemberCount represents an error which means there are not enough members to complete the task.
-var ErrInsufficientMemberCount = errors.New("insufficient member count")
+// DefaultClassLabel is the label which holds the class of a member for Config.ClassLoads by default.
+const DefaultClassLabel = "class"
+
+var (
+	// ErrInsufficientMemberCount represents an error which means there are not enough members to complete the task.
+	ErrInsufficientMemberCount = errors.New("insufficient member count")
+
+	// ErrNotEnoughRoom represents an error which means the partitions cannot be distributed without exceeding
+	// the average load. See DistributionError for the details of a failed distribution.
+	ErrNotEnoughRoom = errors.New("not enough room to distribute partitions")
+
+	// ErrInvalidGroupCount represents an error which means the partitions cannot be divided into the requested
+	// number of groups.
+	ErrInvalidGroupCount = errors.New("invalid partition group count")
+
+	// ErrInvalidSnapshot represents an error which means a snapshot cannot be restored.
+	ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+	// ErrMemberNotFound represents an error which means there is no member with the given name.
+	ErrMemberNotFound = errors.New("member not found")
+
+	// ErrNoDecommission represents an error which means the member is not being decommissioned.
+	ErrNoDecommission = errors.New("member is not being decommissioned")
+
+	// ErrPlanOutdated represents an error which means the partition table changed since the migration plan
+	// was computed.
+	ErrPlanOutdated = errors.New("migration plan is outdated")
+
+	// ErrDistributionTimeout represents an error which means the partitions couldn't be distributed within
+	// Config.DistributionTimeout.
+	ErrDistributionTimeout = errors.New("partition distribution timed out")
+
+	// ErrVersionMismatch represents an error which means a delta doesn't apply to the version at hand.
+	ErrVersionMismatch = errors.New("version mismatch")
+
+	// ErrVersionUnavailable represents an error which means the requested version of the partition table
+	// isn't retained.
+	ErrVersionUnavailable = errors.New("version is not available")
+
+	// ErrInvalidPartitionID represents an error which means there is no partition with the given ID.
+	ErrInvalidPartitionID = errors.New("invalid partition id")
+
+	// ErrMembershipMismatch represents an error which means the rings passed to NewRingGroup have different members.
+	ErrMembershipMismatch = errors.New("rings have different members")
+
+	// ErrMemberAlreadyExists represents an error which means there is already a member with the given name.
+	ErrMemberAlreadyExists = errors.New("member already exists")
+
+	// ErrInvalidMemberName represents an error which means the member name is empty after normalization.
+	ErrInvalidMemberName = errors.New("invalid member name")
+
+	// ErrInvalidDistribution represents an error which means a Distributor left a partition without an owner or
+	// assigned it to a member which is not on the ring, or a ReplicaStrategy picked an invalid backup.
+	ErrInvalidDistribution = errors.New("invalid distribution")
+
+	// ErrStaleGeneration represents an error which means the member is an older incarnation of the member with the
+	// same name on the ring, see GenerationMember.
+	ErrStaleGeneration = errors.New("stale member generation")
+
+	// ErrEmptyRing represents an error which means the ring has no members.
+	ErrEmptyRing = errors.New("ring is empty")
+)
+
+// DistributionError describes a failed attempt to distribute partitions among members. It wraps ErrNotEnoughRoom.
+type DistributionError struct {
+	// AverageLoad is the load limit computed for the failed distribution.
+	AverageLoad float64
+
+	// PartitionCount is the number of partitions that had to be distributed.
+	PartitionCount int
+
+	// MemberCount is the number of members on the consistent hash ring.
+	MemberCount int
+
+	// Assigned is the number of partitions that had been placed when the distribution failed.
+	Assigned int
+
+	// MinimumLoad is the smallest Load value that would have succeeded with the same partition and member count.
+	MinimumLoad float64
+
+	// Capped is the number of members with a limit set by SetMaxLoad.
+	Capped int
+}
+
+func (e *DistributionError) Error() string {
+	if e.Capped != 0 {
+		return fmt.Sprintf("%s: average load: %g, partition count: %d, member count: %d, assigned: %d, "+
+			"minimum load: %g, capped members: %d", ErrNotEnoughRoom, e.AverageLoad, e.PartitionCount,
+			e.MemberCount, e.Assigned, e.MinimumLoad, e.Capped)
+	}
+	return fmt.Sprintf("%s: average load: %g, partition count: %d, member count: %d, assigned: %d, minimum load: %g",
+		ErrNotEnoughRoom, e.AverageLoad, e.PartitionCount, e.MemberCount, e.Assigned, e.MinimumLoad)
+}
+
+// Unwrap returns ErrNotEnoughRoom.
+func (e *DistributionError) Unwrap() error {
+	return ErrNotEnoughRoom
+}
 
 // Hasher is responsible for generating unsigned, 64-bit hash of provided byte slice.
 // Hasher should minimize collisions (generating same hash for different byte slice)
@@ -82,7 +179,8 @@ type Hasher interface {
 	Sum64([]byte) uint64
 }
 
-// Member interface represents a member in consistent hash ring.
+// Member interface represents a member in consistent hash ring. Any fmt.Stringer is a Member, see
+// StringerMember and NamedMember for adapting other types.
 type Member interface {
 	String() string
 }
@@ -101,52 +199,349 @@ type Config struct {
 	// how many times replicated on the ring.
 	ReplicationFactor int
 
+	// AutoReplicationFactor replaces ReplicationFactor with a factor which scales with the member count, if its
+	// TotalVirtualNodes is set. The ring is rebuilt at distribution time when the cluster doubled or halved in
+	// size. Consistent.ReplicationFactor returns the factor in use.
+	AutoReplicationFactor AutoReplicationFactor
+
 	// Load is used to calculate average load. See the code, the paper and Google's blog post to learn about it.
 	Load float64
+
+	// BackupCount is the number of backup members computed for every partition at distribution time.
+	// GetPartitionOwnerAndBackups returns them without any further computation. Zero disables the table.
+	BackupCount int
+
+	// BackupDirection is the direction of the ring walk which picks the backups. Clockwise is the default.
+	BackupDirection BackupDirection
+
+	// SkipBackup rejects members as backups, e.g. those which are down, if it's set. Draining members are never
+	// picked. It's called at distribution time, call RefreshBackups when its result changes.
+	SkipBackup func(member Member) bool
+
+	// MinBackupZones is the number of distinct zones the owner and the backups of every partition should span, as
+	// far as the members allow. Zones come from ZonedMember, other members are in the empty zone.
+	MinBackupZones int
+
+	// ReplicaStrategy picks the backups of every partition instead of the balanced walk described by
+	// Config.BackupCount, if it's set, e.g. SimpleStrategy or NetworkTopologyStrategy.
+	ReplicaStrategy ReplicaStrategy
+
+	// AutoSavePath is the file the ring is saved to with SaveToFile after every change of the partition table,
+	// if it's set. Errors are logged to Logger. Restore the ring with LoadFromFile.
+	AutoSavePath string
+
+	// TinyClusterFallback bypasses the bounded-load algorithm if there are one or two members. A single member
+	// owns every partition, two members own every other partition. Member weights are ignored in this mode.
+	TinyClusterFallback bool
+
+	// ExpectedMembers is a hint for the number of members. Internal structures are pre-sized for it,
+	// which avoids repeated growth during the initial membership load of a large cluster.
+	ExpectedMembers int
+
+	// KeyCacheSize is the number of results of GetClosestN and GetClosestNForPartition cached by partition and
+	// count. The least recently used entry is evicted when the cache is full, and the whole cache is invalidated
+	// when the partition table changes. It helps workloads which look up the replicas of the same hot keys over
+	// and over. LocateKey isn't cached, the owner of a key is a table lookup already. Zero disables the cache.
+	KeyCacheSize int
+	// DistributionDebounce defers the distribution of partitions after Add and Remove until there has been no
+	// membership change for the given duration, so a burst of changes, e.g. a flapping gossip membership, costs a
+	// single distribution. Lookups are served from the previous partition table meanwhile and Version doesn't
+	// change until the deferred distribution runs. Flush runs it immediately. Zero distributes synchronously.
+	DistributionDebounce time.Duration
+
+	// AsyncDistribution makes Add and Remove return without waiting for the partitions to be distributed.
+	// The new partition table is computed on a background goroutine and swapped in atomically, lookups are
+	// served from the previous table meanwhile. Use LatestVersion and WaitForVersion to observe a change.
+	// Combined with DistributionDebounce, the background distribution starts when the debounce timer fires.
+	AsyncDistribution bool
+
+	// DistributionTimeout limits the time a distribution may hold the write lock. If the partitions aren't
+	// distributed in time, the previous partition table is kept and lookups go on using it. The membership change
+	// stays pending, the next change or Flush tries again, and Config.MeterProvider records a distribution which
+	// failed with ErrDistributionTimeout. Add and Remove don't panic in that case. It doesn't apply to New.
+	// Zero means no limit.
+	DistributionTimeout time.Duration
+
+	// WarmupDuration makes the capacity of a member added after New ramp up over the given duration, so the caches
+	// behind it can warm up without a thundering herd. Its weight starts at WarmupStart times its weight and grows
+	// in WarmupSteps steps, the partitions are distributed again on every step. AddWithWarmup sets the duration
+	// for a single member. Zero disables it.
+	WarmupDuration time.Duration
+
+	// WarmupStart is the share of its capacity a member starts with, see WarmupDuration. Zero means
+	// DefaultWarmupStart.
+	WarmupStart float64
+
+	// WarmupSteps is the number of steps of the warmup ramp. Zero means DefaultWarmupSteps.
+	WarmupSteps int
+
+	// ReadWriteSplit keeps the previous owner of a partition which moved as a read owner until CompleteHandoff
+	// is called, so reads find the data while it's copied to the new owner, see ReadOwners and WriteOwner.
+	ReadWriteSplit bool
+
+	// PartitionKeyEncoding selects the bytes which are hashed to put a partition on the ring, so ports to other
+	// languages can reproduce the placement exactly. LittleEndianKey is the default.
+	PartitionKeyEncoding PartitionKeyEncoding
+
+	// KeyExtractor selects the part of a key which is hashed by the methods which locate keys, if it's set, e.g.
+	// PrefixExtractor to put all rows of a table on the same partition. The whole key is hashed by default.
+	KeyExtractor KeyExtractor
+
+	// NormalizeName maps member names to a canonical form, e.g. lowercase without a port, if it's set. Members
+	// whose normalized names are equal are the same member: adding one while the other is on the ring is a
+	// duplicate, see OnDuplicateAdd, and the methods which take a member name, e.g. Remove, accept any spelling.
+	// Members keep the names they were added with, which are hashed for the placement. Names which are empty
+	// after normalization are rejected, see TryAdd.
+	NormalizeName func(name string) string
+
+	// OnDuplicateAdd tells Add what to do with a member whose name is already on the ring: ignore it, reject it
+	// or replace the existing member. IgnoreDuplicate is the default.
+	OnDuplicateAdd DuplicatePolicy
+
+	// ClassLoads maps member classes to their load factors, e.g. {"ssd": 1.1, "hdd": 1.5}, so the load bound
+	// adapts to heterogeneous hardware without weights for every member. The class of a member is the value of
+	// its ClassLabel label, see LabeledMember. Members without a class in the map use Load.
+	ClassLoads map[string]float64
+
+	// ClassLabel is the label which holds the class of a member for ClassLoads. DefaultClassLabel is used if
+	// it's empty.
+	ClassLabel string
+
+	// RelaxedLoadLimit is the largest load factor a distribution which found no room under Config.Load retries
+	// with, if it's greater than Load. The retries relax Load by 1.1×, 1.25×, 1.5×, 2× and 3×, up to the limit,
+	// and DistributionReport.Load tells which one succeeded. The load factors of ClassLoads relax in proportion. All
+	// retries share the deadline of DistributionTimeout. The relaxed factor stays applied to the load bounds, e.g.
+	// of Validate and AverageLoad, until the next distribution, which starts at Load again.
+	RelaxedLoadLimit float64
+
+	// Distributor assigns the partitions to the members in every distribution, if it's set. The default is the
+	// bounded-load walk of BoundedLoadDistributor. See Distributor.
+	Distributor Distributor
+
+	// Strategy selects the order in which the partitions are assigned to members. PartitionOrder is the default.
+	Strategy AssignmentStrategy
+
+	// Stickiness makes a distribution keep partitions with their current owners. A member keeps its partitions
+	// as long as it holds fewer than Stickiness times its load bound, the rest of the partitions take the ring
+	// walk. One keeps every partition whose owner is under the bound, which moves the fewest partitions but may
+	// leave a new member with few or none, since the others are rarely over the bound. Lower values trade more
+	// moves for a better balance. The layout depends on the history of the ring then, not only on its members.
+	// Zero disables it.
+	Stickiness float64
+
+	// MinResidency is the time a partition stays with its owner after it moved, before a membership change may
+	// move it again, so a flapping membership doesn't bounce the same partitions back and forth. A partition moves
+	// anyway if its owner leaves the ring or has no room under the load bound. Zero disables it.
+	MinResidency time.Duration
+
+	// DeltaHistory is the number of partition table changes retained for DeltaSince. Every change keeps only
+	// the partitions which moved. Zero disables the history.
+	DeltaHistory int
+
+	// HistorySize is the number of snapshots of the ring retained for SnapshotAt and OwnerAt, one per version of
+	// the partition table. DeltaSince falls back to them for versions which are no longer in the history of
+	// DeltaHistory. Every snapshot holds the complete state of the ring, see Snapshot. Zero disables it.
+	HistorySize int
+
+	// RandomCandidates makes the distribution choose the owner of a partition at random among the first
+	// RandomCandidates distinct members under the load bound found by walking the ring, instead of the nearest
+	// one. The choice is weighted by the member weights. Rings which share member names otherwise put the same
+	// partitions on the same members, a different RandomSeed for every ring spreads such hotspots. Values below
+	// two disable it.
+	RandomCandidates int
+
+	// RandomSeed seeds the choice of RandomCandidates. The distribution is deterministic for a given seed.
+	RandomSeed uint64
+
+	// FailoverToBackup makes Remove hand every partition of the removed member to the first backup of the
+	// partition, which already holds a copy of its data, instead of the member found by the ring walk. A partition
+	// takes the ring walk if its backup has no room under the load bound. It suits members removed after a failure.
+	FailoverToBackup bool
+
+	// MeterProvider receives measurements of distributions and lookups, if it's set. See the otelconsistent
+	// module for OpenTelemetry.
+	MeterProvider MeterProvider
+
+	// PanicFree makes the ring return errors instead of panicking. New takes the FNV-1a hasher of WithDefaultHasher
+	// if Hasher is nil, and the defaults for negative PartitionCount, ReplicationFactor and Load. If the members
+	// cannot own all the partitions, New, Add, Remove and the other membership changes keep the previous partition
+	// table, which is empty for New, and the distribution stays pending: Flush returns the error, and the next
+	// change tries again. The methods of the ring don't panic then, unless a Member, the Hasher, the MeterProvider
+	// or the Logger does. The default is the strict behavior of earlier versions.
+	PanicFree bool
+
+	// KeySampler receives every SampleEvery-th key located by LocateKey, if it's set. See KeySampler.
+	KeySampler KeySampler
+
+	// SampleEvery is the sampling interval of KeySampler. Zero and one sample every lookup.
+	SampleEvery int
+
+	// AssignmentSink receives the partitions which moved in every distribution, if it's set. See AssignmentSink.
+	AssignmentSink AssignmentSink
+
+	// Clock tells the time for warmup ramps, MinResidency, DistributionDebounce and DistributionTimeout, if it's
+	// set. The default is the system clock. See Clock.
+	Clock Clock
+
+	// Logger receives the events of the ring, e.g. membership changes and distributions, if it's set. See Logger.
+	Logger Logger
+
+	// TestingKnobs inject faults into distributions and their publication for chaos tests, if it's set. See
+	// TestingKnobs.
+	TestingKnobs *TestingKnobs
 }
 
 // Consistent holds the information about the members of the consistent hash circle.
 type Consistent struct {
 	mu sync.RWMutex
 
-	config         Config
-	hasher         Hasher
-	sortedSet      []uint64
-	partitionCount uint64
-	loads          map[string]float64
-	members        map[string]*Member
-	partitions     map[int]*Member
-	ring           map[uint64]*Member
+	// lookups counts the lookups for Config.SampleEvery. It's updated atomically.
+	lookups uint32
+
+	config           Config
+	hasher           Hasher
+	sortedSet        []uint64
+	partitionCount   uint64
+	loads            map[string]float64
+	members          map[string]*Member
+	normalizedNames  map[string]string
+	memberList       []Member
+	partitions       map[int]*Member
+	ring             map[uint64]*Member
+	vnodes           map[string][]uint64
+	salts            map[uint64]int
+	memberHashes     []uint64
+	hashedMembers    map[uint64]*Member
+	weighted         int
+	weightSum        float64
+	replicas         [][]Member
+	version          uint64
+	collisions       uint64
+	keys             *keyCache
+	maxLoads         map[string]int
+	overflow         map[string]int
+	overflowTier     int
+	churn            []churnEvent
+	latencies        map[string]float64
+	decommissions    map[string]uint64
+	standbys         []Member
+	affinities       map[int]func(Member) bool
+	history          []layoutChange
+	snapshots        []Snapshot
+	loadScale        float64
+	relaxedLoad      float64
+	leaving          map[string]float64
+	leaveTimers      map[string]Timer
+	affinityGroups   map[string][]int
+	weightScales     map[string]float64
+	replicaCounts    map[string]int
+	movedAt          map[int]time.Time
+	warming          map[string]warmup
+	rampAt           time.Time
+	warmupTimer      Timer
+	detached         bool
+	draining         map[string]struct{}
+	failover         map[int]Member
+	walk             walkStats
+	lastDistribution *DistributionReport
+	handoffs         map[int]Member
+	pending          bool
+	timer            Timer
+	changes          uint64
+	distributing     bool
+	batching         bool
+	watchers         map[<-chan MemberEvents]*memberWatcher
+	versionCh        chan struct{}
+	published        uint64
 }
 
-// New creates and returns a new Consistent object.
+// New creates and returns a new Consistent object. It panics if config.Hasher is nil or the members cannot own
+// all the partitions, unless config.PanicFree is set. A Load below MinimumFeasibleLoadFactor for the members is
+// rejected with a *DistributionError before the distribution.
 func New(members []Member, config Config) *Consistent {
 	if config.Hasher == nil {
-		panic("Hasher cannot be nil")
+		if !config.PanicFree {
+			panic("Hasher cannot be nil")
+		}
+		config.Hasher = defaultHasher{}
 	}
-	if config.PartitionCount == 0 {
+	c := newConsistent(config, len(members))
+	seen := make(map[string]struct{}, len(members))
+	for _, member := range members {
+		name := c.normalize(member.String())
+		if _, ok := seen[name]; ok || name == "" {
+			continue
+		}
+		seen[name] = struct{}{}
+		// Sort the virtual nodes once after placing all of them. Sorting after every member
+		// is quadratic for large clusters.
+		c.sortedSet = append(c.sortedSet, c.place(member)...)
+		c.memberHashes = append(c.memberHashes, c.memberHash(member.String()))
+	}
+	sortHashes(c.sortedSet)
+	sortHashes(c.memberHashes)
+	if !c.config.PanicFree && !c.feasibleLoad() {
+		// Reject a load factor no distribution can satisfy before trying one.
+		panic(c.distributionError(0))
+	}
+	if members != nil {
+		if err := c.distributePartitions(); err != nil {
+			if !c.config.PanicFree {
+				panic(err)
+			}
+			// The ring has no partition table yet, Flush tries again.
+			c.pending = true
+		}
+	}
+	return c
+}
+
+// newConsistent applies the defaults to config and returns an empty Consistent object, pre-sized for
+// the given number of members or Config.ExpectedMembers, whichever is larger.
+func newConsistent(config Config, memberCount int) *Consistent {
+	if config.PartitionCount == 0 || config.PanicFree && config.PartitionCount < 0 {
 		config.PartitionCount = DefaultPartitionCount
 	}
-	if config.ReplicationFactor == 0 {
+	if config.ReplicationFactor == 0 || config.PanicFree && config.ReplicationFactor < 0 {
 		config.ReplicationFactor = DefaultReplicationFactor
 	}
-	if config.Load == 0 {
+	if config.Load == 0 || config.PanicFree && config.Load < 0 {
 		config.Load = DefaultLoad
 	}
+	if config.AutoReplicationFactor.TotalVirtualNodes > 0 {
+		config.ReplicationFactor = config.AutoReplicationFactor.factor(memberCount)
+	}
 
+	expected := config.ExpectedMembers
+	if memberCount > expected {
+		expected = memberCount
+	}
 	c := &Consistent{
 		config:         config,
-		members:        make(map[string]*Member),
+		members:        make(map[string]*Member, expected),
+		memberList:     make([]Member, 0, expected),
 		partitionCount: uint64(config.PartitionCount),
-		ring:           make(map[uint64]*Member),
-	}
-
-	c.hasher = config.Hasher
-	for _, member := range members {
-		c.add(member)
+		ring:           make(map[uint64]*Member, expected*config.ReplicationFactor),
+		vnodes:         make(map[string][]uint64, expected),
+		salts:          make(map[uint64]int),
+		memberHashes:   make([]uint64, 0, expected),
+		hashedMembers:  make(map[uint64]*Member, expected),
+		sortedSet:      make([]uint64, 0, expected*config.ReplicationFactor),
+		hasher:         config.Hasher,
+		versionCh:      make(chan struct{}),
+		maxLoads:       make(map[string]int),
+		overflow:       make(map[string]int),
+		latencies:      make(map[string]float64),
+		decommissions:  make(map[string]uint64),
+		draining:       make(map[string]struct{}),
+		affinities:     make(map[int]func(Member) bool),
+		warming:        make(map[string]warmup),
+		handoffs:       make(map[int]Member),
+		leaving:        make(map[string]float64),
+		leaveTimers:    make(map[string]Timer),
 	}
-	if members != nil {
-		c.distributePartitions()
+	if config.KeyCacheSize > 0 {
+		c.keys = newKeyCache(config.KeyCacheSize)
 	}
 	return c
 }
@@ -157,14 +552,50 @@ func (c *Consistent) GetMembers() []Member {
 	defer c.mu.RUnlock()
 
 	// Create a thread-safe copy of member list.
-	members := make([]Member, 0, len(c.members))
-	for _, member := range c.members {
-		members = append(members, *member)
+	members := make([]Member, 0, len(c.memberList))
+	return append(members, c.memberList...)
+}
+
+// AppendMembers appends the members to dst and returns the extended slice. Unlike GetMembers, it doesn't allocate
+// if dst has enough capacity, which makes it suitable for monitoring loops:
+//
+//	buf = c.AppendMembers(buf[:0])
+func (c *Consistent) AppendMembers(dst []Member) []Member {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append(dst, c.memberList...)
+}
+
+// RangeMembers calls f for each member in an immutable snapshot of the member list, until f returns false.
+// The lock is not held while f runs, so f may call other methods of c. Its signature matches iter.Seq[Member],
+// so it can be used in a range-over-func loop as well:
+//
+//	for member := range c.RangeMembers {
+//		...
+//	}
+func (c *Consistent) RangeMembers(f func(Member) bool) {
+	c.mu.RLock()
+	members := c.memberList
+	c.mu.RUnlock()
+
+	for _, member := range members {
+		if !f(member) {
+			return
+		}
 	}
-	return members
 }
 
-// AverageLoad exposes the current average load.
+// Version returns the version of the partition table. It is incremented every time the partitions are
+// redistributed.
+func (c *Consistent) Version() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.version
+}
+
+// AverageLoad exposes the current average load. It's zero if the ring is empty, see ClampedAverageLoad.
 func (c *Consistent) AverageLoad() float64 {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -177,26 +608,32 @@ func (c *Consistent) averageLoad() float64 {
 		return 0
 	}
 
-	avgLoad := float64(c.partitionCount/uint64(len(c.members))) * c.config.Load
+	// Use exact division here. Integer division truncates the average badly if there are
+	// fewer partitions than members, and no Load value can compensate for that.
+	avgLoad := (float64(c.partitionCount) / float64(len(c.members))) * c.loadFactor()
 	return math.Ceil(avgLoad)
 }
 
-func (c *Consistent) distributeWithLoad(partID, idx int, partitions map[int]*Member, loads map[string]float64) {
-	avgLoad := c.averageLoad()
+func (c *Consistent) distributeWithLoad(partID, idx int, partitions map[int]*Member, loads map[string]float64) error {
 	var count int
 	for {
 		count++
 		if count >= len(c.sortedSet) {
+			c.walk.record(count)
 			// User needs to decrease partition count, increase member count or increase load factor.
-			panic("not enough room to distribute partitions")
+			return c.distributionError(len(partitions))
 		}
 		i := c.sortedSet[idx]
 		member := *c.ring[i]
 		load := loads[member.String()]
-		if load+1 <= avgLoad {
+		if load+1 <= c.loadBound(member) {
+			c.walk.record(count)
+			if c.config.RandomCandidates > 1 {
+				member = c.pickCandidate(partID, idx, member, loads)
+			}
 			partitions[partID] = &member
 			loads[member.String()]++
-			return
+			return nil
 		}
 		idx++
 		if idx >= len(c.sortedSet) {
@@ -205,86 +642,406 @@ func (c *Consistent) distributeWithLoad(partID, idx int, partitions map[int]*Mem
 	}
 }
 
-func (c *Consistent) distributePartitions() {
-	loads := make(map[string]float64)
-	partitions := make(map[int]*Member)
+// distributionError describes a failed distribution which had placed assigned partitions. It's not thread-safe.
+func (c *Consistent) distributionError(assigned int) *DistributionError {
+	return &DistributionError{
+		AverageLoad:    c.averageLoad(),
+		PartitionCount: int(c.partitionCount),
+		MemberCount:    len(c.members),
+		Assigned:       assigned,
+		MinimumLoad:    c.minimumLoad(),
+		Capped:         c.cappedCount(),
+	}
+}
+
+// search returns the index of the first virtual node at or after the given hash, wrapping around
+// the end of the ring. It's not thread-safe.
+func (c *Consistent) search(key uint64) int {
+	idx := searchHashes(c.sortedSet, key)
+	if idx >= len(c.sortedSet) {
+		idx = 0
+	}
+	return idx
+}
 
-	bs := make([]byte, 8)
-	for partID := uint64(0); partID < c.partitionCount; partID++ {
-		binary.LittleEndian.PutUint64(bs, partID)
-		key := c.hasher.Sum64(bs)
-		idx := sort.Search(len(c.sortedSet), func(i int) bool {
-			return c.sortedSet[i] >= key
-		})
-		if idx >= len(c.sortedSet) {
-			idx = 0
+// searchHashes returns the index of the first hash at or after key in the sorted hashes, or len(hashes) if there
+// is none. Unlike sort.Search, it doesn't call a closure, and the loop halves the range with a conditional add
+// the compiler can turn into a conditional move, so the read path doesn't stall on mispredicted branches.
+func searchHashes(hashes []uint64, key uint64) int {
+	n := len(hashes)
+	if n == 0 {
+		return 0
+	}
+	base := 0
+	for n > 1 {
+		half := n / 2
+		if hashes[base+half-1] < key {
+			base += half
 		}
-		c.distributeWithLoad(int(partID), idx, partitions, loads)
+		n -= half
+	}
+	if hashes[base] < key {
+		base++
+	}
+	return base
+}
+
+// distributePartitions distributes the partitions and reports the distribution to Config.MeterProvider and
+// Config.Logger. It's not thread-safe.
+func (c *Consistent) distributePartitions() error {
+	if !c.observed() {
+		return c.distribute()
+	}
+	start, previous := time.Now(), c.partitions
+	err := c.distribute()
+	c.recordDistribution(start, previous, err)
+	return err
+}
+
+// distributeOnce distributes the partitions with the current load factor. The deadline is shared by the retries
+// of distribute. It's not thread-safe.
+func (c *Consistent) distributeOnce(deadline time.Time) (err error) {
+	start := time.Now()
+	c.walk = walkStats{}
+	defer func() {
+		c.reportDistribution(start, err)
+	}()
+
+	// Only owners have an entry in loads, there are at most partitionCount of them.
+	size := len(c.members)
+	if size > int(c.partitionCount) {
+		size = int(c.partitionCount)
 	}
+	loads := make(map[string]float64, size)
+	partitions := make(map[int]*Member, c.partitionCount)
+	if len(c.warming) != 0 {
+		c.rampAt = c.clock().Now()
+	}
+	c.weightSum = c.totalWeight()
+	var now time.Time
+	if c.config.MinResidency > 0 {
+		now = c.clock().Now()
+	}
+
+	if err = c.injectFailure(); err != nil {
+		return err
+	}
+	if c.config.Distributor != nil {
+		err = c.distributeWith(c.config.Distributor, partitions, loads, deadline, now)
+	} else {
+		err = c.assign(partitions, loads, deadline, now)
+	}
+	if err != nil {
+		return err
+	}
+	c.injectMoves(partitions, loads)
+	previous, previousLoads := c.partitions, c.loads
 	c.partitions = partitions
 	c.loads = loads
+	replicas, err := c.replicaTable(deadline)
+	if err != nil {
+		c.partitions, c.loads = previous, previousLoads
+		return err
+	}
+	c.failover = nil
+	c.replicas = replicas
+	c.updateResidency(previous, partitions, now)
+	c.trackHandoffs(previous)
+	c.recordLayout(previous)
+	c.bumpVersion()
+	c.scheduleWarmup()
+	return nil
 }
 
-func (c *Consistent) add(member Member) {
-	for i := 0; i < c.config.ReplicationFactor; i++ {
-		key := []byte(fmt.Sprintf("%s%d", member.String(), i))
-		h := c.hasher.Sum64(key)
-		c.ring[h] = &member
-		c.sortedSet = append(c.sortedSet, h)
-	}
-	// sort hashes ascendingly
-	sort.Slice(c.sortedSet, func(i int, j int) bool {
-		return c.sortedSet[i] < c.sortedSet[j]
-	})
+// assign fills the partition table with the bounded-load distribution, see BoundedLoadDistributor. It's not
+// thread-safe.
+func (c *Consistent) assign(partitions map[int]*Member, loads map[string]float64, deadline, now time.Time) error {
+	if c.config.TinyClusterFallback && len(c.members) <= 2 && !c.capped() {
+		c.distributeRoundRobin(partitions, loads)
+		c.walk.roundRobin = true
+		return nil
+	}
+	c.distributeLeaving(partitions, loads)
+	c.distributeFailover(partitions, loads)
+	c.distributeResident(partitions, loads, now)
+	c.distributeAffinity(partitions, loads)
+	c.distributeAffinityGroups(partitions, loads)
+	c.distributeSticky(partitions, loads)
+	if c.config.Strategy == TwoPass {
+		c.distributeFairShare(partitions, loads)
+	}
+	c.walk.preassigned = len(partitions)
+	bs := make([]byte, 8)
+	for i, partID := range c.assignmentOrder() {
+		// Check before skipping preassigned partitions, the check must not depend on which ones they are.
+		if c.expired(deadline, i) {
+			return ErrDistributionTimeout
+		}
+		if _, ok := partitions[partID]; ok {
+			continue
+		}
+		idx := c.partitionIndex(partID, bs)
+		if err := c.distributeWithLoad(partID, idx, partitions, loads); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// distributeRoundRobin assigns the partitions to the members in turn, in the order of the member hashes.
+// It's used for tiny clusters if Config.TinyClusterFallback is set. It's not thread-safe.
+func (c *Consistent) distributeRoundRobin(partitions map[int]*Member, loads map[string]float64) {
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		member := c.hashedMembers[c.memberHashes[partID%len(c.memberHashes)]]
+		partitions[partID] = member
+		loads[(*member).String()]++
+	}
+}
+
+// place puts the virtual nodes of the member on the ring and registers the member. It returns the points which
+// became occupied, the caller is responsible for inserting them into sortedSet. These are the points of the new
+// virtual nodes unless a collision displaced an existing one.
+func (c *Consistent) place(member Member) []uint64 {
+	n := c.replicaCount(member.String())
+	c.vnodes[member.String()] = make([]uint64, n)
+	hashes := make([]uint64, 0, n)
+	for i := 0; i < n; i++ {
+		hashes = append(hashes, c.insertVirtualNode(&member, i, 0))
+	}
+	c.register(&member)
+	return hashes
+}
+
+// register adds the member to the member indexes. The caller is responsible for inserting the hash of
+// its name into memberHashes.
+func (c *Consistent) register(member *Member) {
 	// Storing member at this map is useful to find backup members of a partition.
-	c.members[member.String()] = &member
+	c.members[(*member).String()] = member
+	// Tiny clusters are distributed in the order of the member hashes.
+	c.hashedMembers[c.memberHash((*member).String())] = member
+	if _, ok := (*member).(WeightedMember); ok {
+		c.weighted++
+	}
+	c.indexName((*member).String())
+	// RangeMembers iterates over a snapshot of the member list without holding the lock. Appending is safe,
+	// the elements of the snapshot are never modified.
+	c.memberList = append(c.memberList, *member)
 }
 
-// Add adds a new member to the consistent hash circle.
-func (c *Consistent) Add(member Member) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+func (c *Consistent) add(member Member) {
+	hashes := c.place(member)
+	sortHashes(hashes)
+	c.sortedSet = mergeHashes(c.sortedSet, hashes)
+	c.memberHashes = mergeHashes(c.memberHashes, []uint64{c.memberHash(member.String())})
+	c.logMember("member added", member.String())
+}
 
-	if _, ok := c.members[member.String()]; ok {
-		// We already have this member. Quit immediately.
-		return
+// memberHash returns the hash of the member name.
+func (c *Consistent) memberHash(name string) uint64 {
+	return c.hasher.Sum64([]byte(name))
+}
+
+// mergeHashes merges the sorted hashes into the sorted set in place, starting from the end.
+// This is linear in the size of the set.
+func mergeHashes(set, hashes []uint64) []uint64 {
+	i, j := len(set)-1, len(hashes)-1
+	set = append(set, hashes...)
+	for k := len(set) - 1; j >= 0; k-- {
+		if i >= 0 && set[i] > hashes[j] {
+			set[k] = set[i]
+			i--
+		} else {
+			set[k] = hashes[j]
+			j--
+		}
 	}
-	c.add(member)
-	c.distributePartitions()
+	return set
 }
 
-func (c *Consistent) delSlice(val uint64) {
-	for i := 0; i < len(c.sortedSet); i++ {
-		if c.sortedSet[i] == val {
-			c.sortedSet = append(c.sortedSet[:i], c.sortedSet[i+1:]...)
-			break
+// deleteHashes removes the given hashes from the sorted set in a single pass.
+func deleteHashes(set, hashes []uint64) []uint64 {
+	sorted := make([]uint64, len(hashes))
+	copy(sorted, hashes)
+	sortHashes(sorted)
+
+	res := set[:0]
+	j := 0
+	for _, h := range set {
+		for j < len(sorted) && sorted[j] < h {
+			j++
 		}
+		if j < len(sorted) && sorted[j] == h {
+			continue
+		}
+		res = append(res, h)
+	}
+	return res
+}
+
+// sortHashes sorts hashes ascendingly.
+func sortHashes(hashes []uint64) {
+	sort.Slice(hashes, func(i int, j int) bool {
+		return hashes[i] < hashes[j]
+	})
+}
+
+// Add adds a new member to the consistent hash circle. If a member with the same name exists, it follows
+// Config.OnDuplicateAdd, see TryAdd.
+func (c *Consistent) Add(member Member) {
+	_ = c.TryAdd(member)
+}
+
+// addNew adds a member whose name is not on the ring with the given number of virtual nodes, a non-positive
+// count takes Config.ReplicationFactor. It's not thread-safe.
+func (c *Consistent) addNew(member Member, replicas int) {
+	if i := c.standbyIndex(member.String()); i >= 0 {
+		// The member is active now.
+		c.standbys = append(c.standbys[:i:i], c.standbys[i+1:]...)
+	}
+	if replicas > 0 {
+		c.setReplicaCount(member.String(), replicas)
 	}
+	c.add(member)
+	c.startWarmup(member.String())
+	c.redistribute()
 }
 
-// Remove removes a member from the consistent hash circle.
+// Remove removes a member from the consistent hash circle. If there are standby members, the first one takes
+// its place in the same distribution, see AddStandby.
 func (c *Consistent) Remove(name string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	name = c.memberName(name)
 	if _, ok := c.members[name]; !ok {
 		// There is no member with that name. Quit immediately.
 		return
 	}
+	c.removeMember(name)
+}
+
+// SetMembers makes the given members the members of the ring: members which are not in the list are removed,
+// new ones are added and members whose value changed, e.g. their weight, are replaced. The partitions are
+// distributed once for the whole change, and not at all if nothing changed. Duplicate names in members are
+// ignored, the first one wins. It's meant for reconciling the ring with an external membership source.
+func (c *Consistent) SetMembers(members []Member) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.setMembers(members) {
+		c.membershipChanged()
+	}
+}
+
+// setMembers changes the members like SetMembers without touching the partition table. It reports whether the
+// membership changed. It's not thread-safe.
+func (c *Consistent) setMembers(members []Member) bool {
+	target := make(map[string]Member, len(members))
+	var order []Member
+	for _, member := range members {
+		name := c.normalize(member.String())
+		if _, ok := target[name]; ok || name == "" {
+			continue
+		}
+		target[name] = member
+		order = append(order, member)
+	}
 
-	for i := 0; i < c.config.ReplicationFactor; i++ {
-		key := []byte(fmt.Sprintf("%s%d", name, i))
-		h := c.hasher.Sum64(key)
+	var changed bool
+	// Replaced members keep their warmup ramp, if any, and don't start a new one. They keep their virtual node
+	// count as well.
+	replaced := make(map[string]*warmup)
+	counts := make(map[string]int)
+	for _, member := range append([]Member(nil), c.memberList...) {
+		name := member.String()
+		m, ok := target[c.normalize(name)]
+		if ok && reflect.DeepEqual(m, member) {
+			continue
+		}
+		if ok {
+			var w *warmup
+			if current, warming := c.warming[name]; warming {
+				w = &current
+			}
+			replaced[m.String()] = w
+			if n, custom := c.replicaCounts[name]; custom {
+				counts[m.String()] = n
+			}
+		}
+		c.remove(name)
+		changed = true
+	}
+	for _, member := range order {
+		name := member.String()
+		if _, ok := c.members[name]; ok {
+			continue
+		}
+		if n, ok := counts[name]; ok {
+			c.setReplicaCount(name, n)
+		}
+		c.add(member)
+		if w, ok := replaced[name]; !ok {
+			c.startWarmup(name)
+		} else if w != nil {
+			c.warming[name] = *w
+		}
+		changed = true
+	}
+	return changed
+}
+
+// remove takes the member off the ring and the member indexes without touching the partition table.
+// It's not thread-safe.
+func (c *Consistent) remove(name string) {
+	for _, h := range c.vnodes[name] {
 		delete(c.ring, h)
-		c.delSlice(h)
+		delete(c.salts, h)
+	}
+	c.sortedSet = deleteHashes(c.sortedSet, c.vnodes[name])
+	delete(c.vnodes, name)
+	if len(c.salts) != 0 {
+		// Virtual nodes displaced by a collision may now fit on a freed point.
+		c.resettle()
+	}
+	if _, ok := (*c.members[name]).(WeightedMember); ok {
+		c.weighted--
 	}
+	key := c.memberHash(name)
+	c.memberHashes = deleteHashes(c.memberHashes, []uint64{key})
+	delete(c.hashedMembers, key)
 	delete(c.members, name)
+	c.unindexName(name)
+	delete(c.draining, name)
+	delete(c.warming, name)
+	delete(c.replicaCounts, name)
+	c.dropWeightScale(name)
+	c.dropHandoffs(name)
+	c.dropLeaving(name)
+	memberList := make([]Member, 0, len(c.memberList)-1)
+	for _, member := range c.memberList {
+		if member.String() != name {
+			memberList = append(memberList, member)
+		}
+	}
+	c.memberList = memberList
+	c.logMember("member removed", name)
+}
+
+// membershipChanged updates the partition table after members were added or removed. It's not thread-safe.
+func (c *Consistent) membershipChanged() {
 	if len(c.members) == 0 {
 		// consistent hash ring is empty now. Reset the partition table.
+		previous := c.partitions
 		c.partitions = make(map[int]*Member)
+		c.loads = make(map[string]float64)
+		c.replicas = nil
+		c.failover = nil
+		c.recordLayout(previous)
+		c.bumpVersion()
+		c.pending = false
 		return
 	}
-	c.distributePartitions()
+	c.redistribute()
 }
 
 // LoadDistribution exposes load distribution of members.
@@ -302,11 +1059,31 @@ func (c *Consistent) LoadDistribution() map[string]float64 {
 
 // FindPartitionID returns partition id for given key.
 func (c *Consistent) FindPartitionID(key []byte) int {
-	hkey := c.hasher.Sum64(key)
-	return int(hkey % c.partitionCount)
+	return c.PartitionForHash(c.KeyHash(key))
+}
+
+// KeyHash returns the hash of the key which FindPartitionID and LocateKey use. Systems which store it with their
+// records can find the partition and the owner with PartitionForHash and OwnerForHash without hashing again.
+func (c *Consistent) KeyHash(key []byte) uint64 {
+	return c.hasher.Sum64(c.routingKey(key))
+}
+
+// PartitionForHash returns the partition id of a key with the given KeyHash.
+func (c *Consistent) PartitionForHash(h uint64) int {
+	return int(h % c.partitionCount)
 }
 
-// GetPartitionOwner returns the owner of the given partition.
+// PartitionPoint returns the point of the partition on the ring, where the walk for its owner starts.
+func (c *Consistent) PartitionPoint(partID int) uint64 {
+	return c.partitionPoint(partID, make([]byte, 8))
+}
+
+// OwnerForHash returns the owner of a key with the given KeyHash.
+func (c *Consistent) OwnerForHash(h uint64) Member {
+	return c.GetPartitionOwner(c.PartitionForHash(h))
+}
+
+// GetPartitionOwner returns the owner of the given partition, or nil if the ring is empty. See PartitionOwner.
 func (c *Consistent) GetPartitionOwner(partID int) Member {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -326,10 +1103,56 @@ func (c *Consistent) getPartitionOwner(partID int) Member {
 
 // LocateKey finds a home for given key
 func (c *Consistent) LocateKey(key []byte) Member {
+	if c.config.MeterProvider == nil && c.config.KeySampler == nil {
+		return c.locateKey(key)
+	}
+	start := time.Now()
+	member := c.locateKey(key)
+	if c.config.MeterProvider != nil {
+		c.config.MeterProvider.RecordLookup(time.Since(start))
+	}
+	if c.config.KeySampler != nil {
+		c.sample(key, member)
+	}
+	return member
+}
+
+func (c *Consistent) locateKey(key []byte) Member {
 	partID := c.FindPartitionID(key)
 	return c.GetPartitionOwner(partID)
 }
 
+// LocateKeyAvoiding is like LocateKey, but skips the members for which overloaded returns true. It tries the owner
+// and the backups from Config.BackupCount first, then the rest of the members in the order of GetClosestN.
+// It returns nil if every member is overloaded. The ring is not modified and overloaded is called without
+// holding the lock.
+func (c *Consistent) LocateKeyAvoiding(key []byte, overloaded func(Member) bool) Member {
+	partID := c.FindPartitionID(key)
+	replicas := c.GetPartitionOwnerAndBackups(partID)
+	for _, member := range replicas {
+		if !overloaded(member) {
+			return member
+		}
+	}
+	if len(replicas) == 0 {
+		return nil
+	}
+
+	c.mu.RLock()
+	candidates := c.successors(partID, len(c.members))
+	c.mu.RUnlock()
+	for _, member := range candidates {
+		if containsMember(replicas, member) {
+			// Already checked.
+			continue
+		}
+		if !overloaded(member) {
+			return member
+		}
+	}
+	return nil
+}
+
 func (c *Consistent) getClosestN(partID, count int) ([]Member, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -338,56 +1161,51 @@ func (c *Consistent) getClosestN(partID, count int) ([]Member, error) {
 	if count > len(c.members) {
 		return res, ErrInsufficientMemberCount
 	}
-
-	var ownerKey uint64
-	owner := c.getPartitionOwner(partID)
-	// Hash and sort all the names.
-	var keys []uint64
-	kmems := make(map[uint64]*Member)
-	for name, member := range c.members {
-		key := c.hasher.Sum64([]byte(name))
-		if name == owner.String() {
-			ownerKey = key
-		}
-		keys = append(keys, key)
-		kmems[key] = member
-	}
-	sort.Slice(keys, func(i, j int) bool {
-		return keys[i] < keys[j]
-	})
-
-	// Find the key owner
-	idx := 0
-	for idx < len(keys) {
-		if keys[idx] == ownerKey {
-			key := keys[idx]
-			res = append(res, *kmems[key])
-			break
-		}
-		idx++
-	}
-
-	// Find the closest(replica owners) members.
-	for len(res) < count {
-		idx++
-		if idx >= len(keys) {
-			idx = 0
-		}
-		key := keys[idx]
-		res = append(res, *kmems[key])
+	if c.keys != nil {
+		return c.closestCached(partID, count), nil
 	}
-	return res, nil
+	return c.successors(partID, count), nil
 }
 
-// GetClosestN returns the closest N member to a key in the hash ring.
+// GetClosestN returns the closest N member to a key in the hash ring: the owner of its partition followed by the
+// next distinct members on the virtual node ring, clockwise from the point of the partition.
 // This may be useful to find members for replication.
 func (c *Consistent) GetClosestN(key []byte, count int) ([]Member, error) {
 	partID := c.FindPartitionID(key)
 	return c.getClosestN(partID, count)
 }
 
-// GetClosestNForPartition returns the closest N member for given partition.
+// GetClosestNForPartition returns the closest N member for given partition, see GetClosestN.
 // This may be useful to find members for replication.
 func (c *Consistent) GetClosestNForPartition(partID, count int) ([]Member, error) {
 	return c.getClosestN(partID, count)
 }
+
+// GetNForKey walks the virtual nodes on the hash ring clockwise, starting from the hash of the key, and returns
+// the first n distinct members it meets. Unlike GetClosestN it doesn't use the partition table.
+func (c *Consistent) GetNForKey(key []byte, n int) ([]Member, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if n > len(c.members) {
+		return nil, ErrInsufficientMemberCount
+	}
+	if n <= 0 {
+		return []Member{}, nil
+	}
+	res := make([]Member, 0, n)
+	seen := make(map[string]struct{}, n)
+	idx := c.search(c.KeyHash(key))
+	for i := 0; i < len(c.sortedSet) && len(res) < n; i++ {
+		member := *c.ring[c.sortedSet[idx]]
+		if _, ok := seen[member.String()]; !ok {
+			seen[member.String()] = struct{}{}
+			res = append(res, member)
+		}
+		idx++
+		if idx >= len(c.sortedSet) {
+			idx = 0
+		}
+	}
+	return res, nil
+}
@@ -24,8 +24,11 @@
 package consistent
 
 import (
+	"errors"
 	"fmt"
 	"hash/fnv"
+	"math/rand"
+	"sort"
 	"strconv"
 	"testing"
 )
@@ -75,6 +78,107 @@ func TestConsistentAdd(t *testing.T) {
 	}
 }
 
+func TestConsistentAppendMembers(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		member := testMember(fmt.Sprintf("node%d.olric", i))
+		members = append(members, member)
+	}
+	c := New(members, newConfig())
+
+	buf := make([]Member, 0, 16)
+	buf = c.AppendMembers(buf[:0])
+	if len(buf) != len(members) {
+		t.Fatalf("Expected %d members, Got: %d", len(members), len(buf))
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		buf = c.AppendMembers(buf[:0])
+	})
+	if allocs != 0 {
+		t.Fatalf("Expected no allocations, Got: %f", allocs)
+	}
+
+	var count int
+	c.RangeMembers(func(member Member) bool {
+		// The lock is not held while iterating.
+		c.Remove(member.String())
+		count++
+		return count < 4
+	})
+	if count != 4 {
+		t.Fatalf("Expected 4 iterations, Got: %d", count)
+	}
+	if len(c.GetMembers()) != 4 {
+		t.Fatalf("Expected 4 members, Got: %d", len(c.GetMembers()))
+	}
+}
+
+func TestConsistentExpectedMembers(t *testing.T) {
+	cfg := newConfig()
+	cfg.ExpectedMembers = 100
+	c := New(nil, cfg)
+	if cap(c.sortedSet) != 100*cfg.ReplicationFactor {
+		t.Fatalf("Expected capacity %d, Got: %d", 100*cfg.ReplicationFactor, cap(c.sortedSet))
+	}
+	for i := 0; i < 100; i++ {
+		c.add(testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	if cap(c.sortedSet) != 100*cfg.ReplicationFactor {
+		t.Fatalf("sorted set should not grow, capacity: %d", cap(c.sortedSet))
+	}
+}
+
+func TestConsistentSetMembers(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c := New(members[:4], newConfig())
+
+	// Add node4..node7 and remove node0, node1 with a single distribution.
+	version := c.Version()
+	c.SetMembers(members[2:])
+	if c.Version() != version+1 {
+		t.Fatalf("Expected a single distribution, Got: %d", c.Version()-version)
+	}
+	if !c.Equal(New(members[2:], newConfig())) {
+		t.Fatalf("Layout is different from a ring created with the same members")
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	// Nothing changed, nothing is distributed.
+	c.SetMembers(append(members[2:], members[3]))
+	if c.Version() != version+1 {
+		t.Fatalf("Expected no distribution")
+	}
+
+	// A member whose weight changed is replaced.
+	heavy := weightedMember{name: "node2.olric", weight: 4}
+	c.SetMembers(append([]Member{heavy}, members[3:]...))
+	var replaced bool
+	for _, member := range c.GetMembers() {
+		if m, ok := member.(weightedMember); ok && m == heavy {
+			replaced = true
+		}
+	}
+	if !replaced {
+		t.Fatalf("Expected node2.olric to be replaced: %v", c.GetMembers())
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	c.SetMembers(nil)
+	if len(c.GetMembers()) != 0 || c.LocateKey([]byte("Olric")) != nil {
+		t.Fatalf("Expected an empty ring")
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+}
+
 func TestConsistentRemove(t *testing.T) {
 	var members []Member
 	for i := 0; i < 8; i++ {
@@ -123,6 +227,86 @@ func TestConsistentLoad(t *testing.T) {
 	})
 }
 
+func TestConsistentSmallConfigurations(t *testing.T) {
+	tests := []struct {
+		members    int
+		partitions int
+		load       float64
+	}{
+		{members: 1, partitions: 1, load: 1.25},
+		{members: 2, partitions: 1, load: 1.25},
+		{members: 2, partitions: 5, load: 1.5},
+		{members: 3, partitions: 2, load: 1.25},
+		{members: 4, partitions: 7, load: 1.2},
+		{members: 5, partitions: 3, load: 1.1},
+		{members: 7, partitions: 7, load: 1},
+		{members: 8, partitions: 5, load: 1.25},
+	}
+	for _, tc := range tests {
+		name := fmt.Sprintf("%d members, %d partitions, load %g", tc.members, tc.partitions, tc.load)
+		t.Run(name, func(t *testing.T) {
+			var members []Member
+			for i := 0; i < tc.members; i++ {
+				members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+			}
+			cfg := newConfig()
+			cfg.PartitionCount = tc.partitions
+			cfg.Load = tc.load
+			c := New(members, cfg)
+
+			maxLoad := c.AverageLoad()
+			if maxLoad < 1 {
+				t.Fatalf("AverageLoad should be at least 1. Got: %f", maxLoad)
+			}
+			for partID := 0; partID < tc.partitions; partID++ {
+				if c.GetPartitionOwner(partID) == nil {
+					t.Fatalf("partition %d has no owner", partID)
+				}
+			}
+			for member, load := range c.LoadDistribution() {
+				if load > maxLoad {
+					t.Fatalf("%s exceeds max load. Its load: %f, max load: %f", member, load, maxLoad)
+				}
+			}
+		})
+	}
+}
+
+func TestConsistentTinyClusterFallback(t *testing.T) {
+	cfg := newConfig()
+	cfg.TinyClusterFallback = true
+	c := New([]Member{testMember("node0.olric")}, cfg)
+	if load := c.LoadDistribution()["node0.olric"]; load != float64(cfg.PartitionCount) {
+		t.Fatalf("Single member should own every partition, its load: %f", load)
+	}
+
+	c.Add(testMember("node1.olric"))
+	loads := c.LoadDistribution()
+	if loads["node0.olric"]+loads["node1.olric"] != float64(cfg.PartitionCount) {
+		t.Fatalf("Unexpected load distribution: %v", loads)
+	}
+	if diff := loads["node0.olric"] - loads["node1.olric"]; diff < -1 || diff > 1 {
+		t.Fatalf("Partitions should be split evenly: %v", loads)
+	}
+	for partID := 1; partID < cfg.PartitionCount; partID++ {
+		if c.GetPartitionOwner(partID).String() == c.GetPartitionOwner(partID-1).String() {
+			t.Fatalf("Consecutive partitions %d and %d have the same owner", partID-1, partID)
+		}
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	// The bounded-load algorithm takes over with the third member.
+	c.Add(testMember("node2.olric"))
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if len(c.LoadDistribution()) != 3 {
+		t.Fatalf("Expected 3 owners, Got: %v", c.LoadDistribution())
+	}
+}
+
 func TestConsistentLocateKey(t *testing.T) {
 	cfg := newConfig()
 	c := New(nil, cfg)
@@ -143,6 +327,71 @@ func TestConsistentLocateKey(t *testing.T) {
 	}
 }
 
+func TestConsistentKeyHash(t *testing.T) {
+	c := New(nil, newConfig())
+	if c.OwnerForHash(42) != nil {
+		t.Fatalf("Expected nil on an empty ring")
+	}
+	for i := 0; i < 8; i++ {
+		c.Add(testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	for i := 0; i < 100; i++ {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		h := c.KeyHash(key)
+		if h != (hasher{}).Sum64(key) {
+			t.Fatalf("Expected the hash of the configured hasher")
+		}
+		if c.PartitionForHash(h) != c.FindPartitionID(key) {
+			t.Fatalf("Expected partition %d, Got: %d", c.FindPartitionID(key), c.PartitionForHash(h))
+		}
+		if c.OwnerForHash(h).String() != c.LocateKey(key).String() {
+			t.Fatalf("Expected owner %s, Got: %s", c.LocateKey(key), c.OwnerForHash(h))
+		}
+	}
+	for partID := 0; partID < 23; partID++ {
+		if c.PartitionPoint(partID) != c.partitionPoint(partID, make([]byte, 8)) {
+			t.Fatalf("Expected the point of partition %d", partID)
+		}
+	}
+}
+
+func TestConsistentLocateKeyAvoiding(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	cfg.BackupCount = 1
+	c := New(members, cfg)
+	key := []byte("Olric")
+	replicas := c.GetPartitionOwnerAndBackups(c.FindPartitionID(key))
+
+	none := func(Member) bool { return false }
+	if res := c.LocateKeyAvoiding(key, none); res.String() != replicas[0].String() {
+		t.Fatalf("Expected the owner %s, Got: %s", replicas[0], res)
+	}
+
+	owner := func(m Member) bool { return m.String() == replicas[0].String() }
+	if res := c.LocateKeyAvoiding(key, owner); res.String() != replicas[1].String() {
+		t.Fatalf("Expected the backup %s, Got: %s", replicas[1], res)
+	}
+
+	// The owner and its backup are overloaded, one of the other members must be picked.
+	both := func(m Member) bool { return owner(m) || m.String() == replicas[1].String() }
+	res := c.LocateKeyAvoiding(key, both)
+	if res == nil || both(res) {
+		t.Fatalf("Expected a member which is not overloaded, Got: %v", res)
+	}
+
+	all := func(Member) bool { return true }
+	if res := c.LocateKeyAvoiding(key, all); res != nil {
+		t.Fatalf("Expected nil, Got: %v", res)
+	}
+	if res := New(nil, cfg).LocateKeyAvoiding(key, none); res != nil {
+		t.Fatalf("Expected nil on an empty ring, Got: %v", res)
+	}
+}
+
 func TestConsistentInsufficientMemberCount(t *testing.T) {
 	var members []Member
 	for i := 0; i < 8; i++ {
@@ -183,6 +432,154 @@ func TestConsistentClosestMembers(t *testing.T) {
 	}
 }
 
+func TestConsistentGetNForKey(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		member := testMember(fmt.Sprintf("node%d.olric", i))
+		members = append(members, member)
+	}
+	cfg := newConfig()
+	c := New(members, cfg)
+	key := []byte("Olric")
+
+	_, err := c.GetNForKey(key, 30)
+	if err != ErrInsufficientMemberCount {
+		t.Fatalf("Expected ErrInsufficientMemberCount(%v), Got: %v", ErrInsufficientMemberCount, err)
+	}
+
+	res, err := c.GetNForKey(key, 8)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if len(res) != 8 {
+		t.Fatalf("Expected member count is 8. Got: %d", len(res))
+	}
+	seen := make(map[string]struct{})
+	for _, member := range res {
+		if _, ok := seen[member.String()]; ok {
+			t.Fatalf("Duplicate member: %s", member)
+		}
+		seen[member.String()] = struct{}{}
+	}
+
+	// The first member owns the first virtual node at or after the hash of the key.
+	first := *c.ring[c.sortedSet[c.search(cfg.Hasher.Sum64(key))]]
+	if res[0].String() != first.String() {
+		t.Fatalf("Expected %s, Got: %s", first, res[0])
+	}
+
+	// A shorter list is a prefix of a longer one.
+	short, err := c.GetNForKey(key, 3)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	for i := range short {
+		if short[i].String() != res[i].String() {
+			t.Fatalf("Expected %s, Got: %s", res[i], short[i])
+		}
+	}
+}
+
+func TestConsistentNotEnoughRoom(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		member := testMember(fmt.Sprintf("node%d.olric", i))
+		members = append(members, member)
+	}
+	cfg := newConfig()
+	cfg.Load = 0.5
+
+	var err error
+	func() {
+		defer func() {
+			err, _ = recover().(error)
+		}()
+		New(members, cfg)
+	}()
+	if !errors.Is(err, ErrNotEnoughRoom) {
+		t.Fatalf("Expected ErrNotEnoughRoom, Got: %v", err)
+	}
+	var derr *DistributionError
+	if !errors.As(err, &derr) {
+		t.Fatalf("Expected DistributionError, Got: %T", err)
+	}
+	if derr.PartitionCount != cfg.PartitionCount || derr.MemberCount != len(members) {
+		t.Fatalf("Unexpected partition or member count: %v", derr)
+	}
+	if derr.Assigned >= cfg.PartitionCount {
+		t.Fatalf("Assigned partition count should be less than %d. Got: %d", cfg.PartitionCount, derr.Assigned)
+	}
+	if derr.MinimumLoad <= cfg.Load {
+		t.Fatalf("Minimum load should be greater than %f. Got: %f", cfg.Load, derr.MinimumLoad)
+	}
+
+	// The suggested load must be enough to distribute the partitions.
+	cfg.Load = derr.MinimumLoad
+	c := New(members, cfg)
+	if len(c.GetMembers()) != len(members) {
+		t.Fatalf("inserted member count is different")
+	}
+}
+
+func TestSearchHashes(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 70; n++ {
+		hashes := make([]uint64, n)
+		for i := range hashes {
+			// A small range makes duplicates likely.
+			hashes[i] = uint64(r.Intn(100))
+		}
+		sortHashes(hashes)
+		for key := uint64(0); key <= 101; key++ {
+			want := sort.Search(len(hashes), func(i int) bool {
+				return hashes[i] >= key
+			})
+			if got := searchHashes(hashes, key); got != want {
+				t.Fatalf("Expected %d for key %d in %v, got: %d", want, key, hashes, got)
+			}
+		}
+	}
+}
+
+func benchmarkHashes(n int) ([]uint64, []uint64) {
+	r := rand.New(rand.NewSource(1))
+	hashes := make([]uint64, n)
+	for i := range hashes {
+		hashes[i] = r.Uint64()
+	}
+	sortHashes(hashes)
+	keys := make([]uint64, 1024)
+	for i := range keys {
+		keys[i] = r.Uint64()
+	}
+	return hashes, keys
+}
+
+func BenchmarkSearchHashes(b *testing.B) {
+	for _, n := range []int{1000, 100000, 1000000} {
+		hashes, keys := benchmarkHashes(n)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				searchHashes(hashes, keys[i%len(keys)])
+			}
+		})
+	}
+}
+
+func BenchmarkSortSearch(b *testing.B) {
+	for _, n := range []int{1000, 100000, 1000000} {
+		hashes, keys := benchmarkHashes(n)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				key := keys[i%len(keys)]
+				sort.Search(len(hashes), func(i int) bool {
+					return hashes[i] >= key
+				})
+			}
+		})
+	}
+}
+
 func BenchmarkAddRemove(b *testing.B) {
 	cfg := newConfig()
 	c := New(nil, cfg)
@@ -0,0 +1,110 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// redistribute rebuilds the partition table after a membership change. If Config.DistributionDebounce is set,
+// the distribution is deferred until there has been no membership change for that long. If
+// Config.AsyncDistribution is set, it runs on a background goroutine. It's not thread-safe.
+func (c *Consistent) redistribute() {
+	c.changes++
+	if c.batching {
+		// addMembers distributes once for the whole batch.
+		return
+	}
+	if c.config.DistributionDebounce <= 0 {
+		if c.config.AsyncDistribution {
+			c.pending = true
+			c.startAsyncDistribution()
+			return
+		}
+		if err := c.redistributeNow(); err != nil {
+			if err == ErrDistributionTimeout || c.config.PanicFree {
+				// Keep serving the previous table, the next change or Flush tries again.
+				c.pending = true
+				return
+			}
+			panic(err)
+		}
+		return
+	}
+
+	c.pending = true
+	if c.timer == nil {
+		c.timer = c.clock().AfterFunc(c.config.DistributionDebounce, c.deferredDistribution)
+		return
+	}
+	c.timer.Reset(c.config.DistributionDebounce)
+}
+
+// deferredDistribution runs the pending distribution when the debounce timer fires. If the distribution fails,
+// the previous partition table is kept and the distribution stays pending. The next membership change or
+// Flush tries again.
+func (c *Consistent) deferredDistribution() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.pending {
+		return
+	}
+	if c.config.AsyncDistribution {
+		c.startAsyncDistribution()
+		return
+	}
+	_ = c.redistributeNow()
+}
+
+// Flush runs a pending distribution immediately instead of waiting for Config.DistributionDebounce to elapse
+// or for the background goroutine of Config.AsyncDistribution to finish. It's a no-op if there is no pending
+// distribution. Unlike Add and Remove, it returns the error of a failed distribution instead of panicking.
+func (c *Consistent) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.pending {
+		return nil
+	}
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	return c.redistributeNow()
+}
+
+// redistributeNow distributes the partitions synchronously. The new table reflects any pending membership change
+// as well, so a successful distribution clears it. It's not thread-safe.
+func (c *Consistent) redistributeNow() error {
+	if err := c.distributePartitions(); err != nil {
+		return err
+	}
+	c.pending = false
+	return nil
+}
+
+// Pending reports whether a membership change is waiting for a deferred or background distribution. Lookups are
+// served from the previous partition table meanwhile.
+func (c *Consistent) Pending() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.pending
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDistributionDebounce(t *testing.T) {
+	cfg := newConfig()
+	cfg.DistributionDebounce = 20 * time.Millisecond
+	c := New([]Member{testMember("node0.olric")}, cfg)
+	version := c.Version()
+
+	for i := 1; i < 8; i++ {
+		c.Add(testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c.Remove("node0.olric")
+	if !c.Pending() {
+		t.Fatalf("Expected a pending distribution")
+	}
+	if c.Version() != version {
+		t.Fatalf("Expected version %d until the deferred distribution, Got: %d", version, c.Version())
+	}
+	// Lookups are served from the previous partition table.
+	if owner := c.LocateKey([]byte("Olric")); owner == nil || owner.String() != "node0.olric" {
+		t.Fatalf("Expected node0.olric as owner, Got: %v", owner)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected no error, Got: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Pending() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Deferred distribution didn't run")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if c.Version() != version+1 {
+		t.Fatalf("Expected a single distribution, Got: %d", c.Version()-version)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected no error, Got: %v", err)
+	}
+
+	expected := New(c.GetMembers(), newConfig())
+	if !c.Equal(expected) {
+		t.Fatalf("Debounced layout is different from a synchronous one")
+	}
+}
+
+func TestDistributionDebounceFlush(t *testing.T) {
+	cfg := newConfig()
+	cfg.DistributionDebounce = time.Hour
+	c := New(nil, cfg)
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Expected no error, Got: %v", err)
+	}
+	c.Add(testMember("node1.olric"))
+	c.Add(testMember("node2.olric"))
+	if c.LocateKey([]byte("Olric")) != nil {
+		t.Fatalf("Expected no owner before the distribution")
+	}
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Expected no error, Got: %v", err)
+	}
+	if c.Pending() {
+		t.Fatalf("Expected no pending distribution")
+	}
+	if c.LocateKey([]byte("Olric")) == nil {
+		t.Fatalf("This shouldn't be nil")
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected no error, Got: %v", err)
+	}
+
+	// Snapshot runs the pending distribution.
+	c.Add(testMember("node3.olric"))
+	if _, err := FromSnapshot(c.Snapshot()); err != nil {
+		t.Fatalf("Expected no error, Got: %v", err)
+	}
+
+	// Removing the last member resets the table right away.
+	c.Remove("node1.olric")
+	c.Remove("node2.olric")
+	c.Remove("node3.olric")
+	if c.Pending() {
+		t.Fatalf("Expected no pending distribution on an empty ring")
+	}
+}
+
+func TestDistributionDebounceFlushError(t *testing.T) {
+	cfg := newConfig()
+	cfg.Load = 0.5
+	cfg.DistributionDebounce = time.Hour
+	c := New(nil, cfg)
+	c.Add(testMember("node1.olric"))
+	c.Add(testMember("node2.olric"))
+	if err := c.Flush(); err == nil {
+		t.Fatalf("Expected an error")
+	}
+	if !c.Pending() {
+		t.Fatalf("Expected the distribution to stay pending")
+	}
+}
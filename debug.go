@@ -0,0 +1,83 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// String returns a short summary of the consistent hash ring: member count, configuration, version and load
+// statistics. It doesn't include the partition table, so it is cheap enough to log.
+func (c *Consistent) String() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.summary()
+}
+
+// GoString is like String but also lists the members and their loads in name order.
+func (c *Consistent) GoString() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := make([]string, 0, len(c.members))
+	for name := range c.members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.WriteString(c.summary())
+	buf.WriteString("[")
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "%s: %g", name, c.loads[name])
+	}
+	buf.WriteString("]")
+	return buf.String()
+}
+
+// summary returns the text returned by String. It's not thread-safe.
+func (c *Consistent) summary() string {
+	var minLoad, maxLoad float64
+	first := true
+	for name := range c.members {
+		load := c.loads[name]
+		if first || load < minLoad {
+			minLoad = load
+		}
+		if first || load > maxLoad {
+			maxLoad = load
+		}
+		first = false
+	}
+	return fmt.Sprintf("consistent{members: %d, partitions: %d, replication factor: %d, load: %g, "+
+		"version: %d, average load: %g, min load: %g, max load: %g}",
+		len(c.members), c.partitionCount, c.config.ReplicationFactor, c.config.Load,
+		c.version, c.averageLoad(), minLoad, maxLoad)
+}
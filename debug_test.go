@@ -0,0 +1,61 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestConsistentString(t *testing.T) {
+	c := New(nil, newConfig())
+	if c.Version() != 0 {
+		t.Fatalf("Expected version 0, Got: %d", c.Version())
+	}
+	for i := 0; i < 3; i++ {
+		c.Add(testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	if c.Version() != 3 {
+		t.Fatalf("Expected version 3, Got: %d", c.Version())
+	}
+
+	s := fmt.Sprintf("%v", c)
+	for _, part := range []string{"members: 3", "partitions: 23", "version: 3", "average load: 10"} {
+		if !strings.Contains(s, part) {
+			t.Fatalf("Expected %q in %s", part, s)
+		}
+	}
+	if strings.Contains(s, "node0.olric") {
+		t.Fatalf("String should not list members: %s", s)
+	}
+
+	gs := fmt.Sprintf("%#v", c)
+	if !strings.HasPrefix(gs, s) {
+		t.Fatalf("GoString should start with the summary: %s", gs)
+	}
+	if !strings.Contains(gs, "node0.olric: ") {
+		t.Fatalf("GoString should list members: %s", gs)
+	}
+}
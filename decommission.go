@@ -0,0 +1,148 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// MigrationPlan describes how the partition table changes when a member is decommissioned, see Decommission.
+type MigrationPlan struct {
+	// Member is the name of the decommissioned member.
+	Member string
+
+	// Version is the version of the partition table the plan was computed from.
+	Version uint64
+
+	// Moves are the partitions whose owner changes, ordered by partition ID. Most of them are the partitions of
+	// the decommissioned member, but bounded loads may move a few others as well. To is nil if the member is the
+	// last one on the ring.
+	Moves []PartitionMove
+}
+
+// Partitions returns the IDs of the partitions which move away from the decommissioned member.
+func (p *MigrationPlan) Partitions() []int {
+	var partitions []int
+	for _, move := range p.Moves {
+		if move.From != nil && move.From.String() == p.Member {
+			partitions = append(partitions, move.PartitionID)
+		}
+	}
+	return partitions
+}
+
+// Decommission computes where the partitions go when the member is removed, without removing it. Transfer the
+// data following the plan, then call CompleteDecommission to remove the member. The partition table after
+// CompleteDecommission is exactly the one described by the plan, as long as the partition table doesn't change
+// in the meantime. A pending deferred or background distribution is run first.
+//
+// It returns ErrMemberNotFound if there is no member with that name, and the *DistributionError if the
+// remaining members cannot own all the partitions.
+func (c *Consistent) Decommission(name string) (*MigrationPlan, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name = c.memberName(name)
+	if _, ok := c.members[name]; !ok {
+		return nil, ErrMemberNotFound
+	}
+	if c.pending {
+		if err := c.redistributeNow(); err != nil {
+			return nil, err
+		}
+	}
+
+	next := c.clone()
+	next.remove(name)
+	if len(next.members) == 0 {
+		next.partitions = make(map[int]*Member)
+	} else if err := next.distribute(); err != nil {
+		return nil, err
+	}
+
+	plan := &MigrationPlan{Member: name, Version: c.version}
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		from, to := c.getPartitionOwner(partID), next.getPartitionOwner(partID)
+		if !sameMember(from, to) {
+			plan.Moves = append(plan.Moves, PartitionMove{PartitionID: partID, From: from, To: to})
+		}
+	}
+	c.decommissions[name] = plan.Version
+	return plan, nil
+}
+
+// CompleteDecommission removes a member after Decommission and distributes the partitions as described by the
+// plan. The distribution is synchronous even with Config.DistributionDebounce or Config.AsyncDistribution.
+// It returns ErrNoDecommission if Decommission wasn't called for the member, and ErrPlanOutdated if the
+// partition table changed since then; call Decommission again in that case.
+func (c *Consistent) CompleteDecommission(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name = c.memberName(name)
+	version, ok := c.decommissions[name]
+	if !ok {
+		return ErrNoDecommission
+	}
+	delete(c.decommissions, name)
+	if _, ok := c.members[name]; !ok || c.pending || c.version != version {
+		return ErrPlanOutdated
+	}
+
+	c.remove(name)
+	c.changes++
+	if len(c.members) == 0 {
+		c.membershipChanged()
+		return nil
+	}
+	if err := c.distributePartitions(); err != nil {
+		if !c.config.PanicFree {
+			// Decommission has already distributed the same members successfully.
+			panic(err)
+		}
+		c.pending = true
+		return err
+	}
+	return nil
+}
+
+// clone returns a copy of the ring which can be modified and distributed without affecting c. The partition
+// table is shared, it's replaced and never modified by a distribution. It's not thread-safe.
+func (c *Consistent) clone() *Consistent {
+	s := c.shadow()
+	s.config.MeterProvider = nil
+	s.memberList = append([]Member(nil), c.memberList...)
+	s.vnodes = make(map[string][]uint64, len(c.vnodes))
+	for name, hashes := range c.vnodes {
+		s.vnodes[name] = append([]uint64(nil), hashes...)
+	}
+	s.salts = make(map[uint64]int, len(c.salts))
+	for h, salt := range c.salts {
+		s.salts[h] = salt
+	}
+	s.collisions = c.collisions
+	if len(c.replicaCounts) != 0 {
+		s.replicaCounts = make(map[string]int, len(c.replicaCounts))
+		for name, n := range c.replicaCounts {
+			s.replicaCounts[name] = n
+		}
+	}
+	return s
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDecommission(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	c := New(members, cfg)
+	before := c.owners()
+
+	plan, err := c.Decommission("node3.olric")
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if plan.Member != "node3.olric" || plan.Version != c.Version() {
+		t.Fatalf("Unexpected plan: %+v", plan)
+	}
+	// Nothing changes until the decommission is completed.
+	if len(c.GetMembers()) != len(members) || len(New(members, cfg).Diff(c)) != 0 {
+		t.Fatalf("Decommission modified the ring")
+	}
+	owned := c.LoadDistribution()["node3.olric"]
+	if len(plan.Partitions()) != int(owned) {
+		t.Fatalf("Expected %g partitions to move away from node3.olric, Got: %d", owned, len(plan.Partitions()))
+	}
+
+	if err := c.CompleteDecommission("node3.olric"); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	after := c.owners()
+	moved := make(map[int]PartitionMove)
+	for _, move := range plan.Moves {
+		moved[move.PartitionID] = move
+	}
+	for partID := range after {
+		move, ok := moved[partID]
+		if !ok {
+			if after[partID].String() != before[partID].String() {
+				t.Fatalf("Partition %d moved without being in the plan", partID)
+			}
+			continue
+		}
+		if move.To.String() != after[partID].String() || move.From.String() != before[partID].String() {
+			t.Fatalf("Partition %d doesn't follow the plan: %+v, owner: %s", partID, move, after[partID])
+		}
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if err := c.CompleteDecommission("node3.olric"); err != ErrNoDecommission {
+		t.Fatalf("Expected ErrNoDecommission, Got: %v", err)
+	}
+}
+
+func TestDecommissionOutdated(t *testing.T) {
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, newConfig())
+	if _, err := c.Decommission("node2.olric"); err != ErrMemberNotFound {
+		t.Fatalf("Expected ErrMemberNotFound, Got: %v", err)
+	}
+	if _, err := c.Decommission("node0.olric"); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	c.Add(testMember("node2.olric"))
+	if err := c.CompleteDecommission("node0.olric"); err != ErrPlanOutdated {
+		t.Fatalf("Expected ErrPlanOutdated, Got: %v", err)
+	}
+	if len(c.GetMembers()) != 3 {
+		t.Fatalf("Expected the member to stay on the ring")
+	}
+}
+
+func TestDecommissionLastMember(t *testing.T) {
+	c := New([]Member{testMember("node0.olric")}, newConfig())
+	plan, err := c.Decommission("node0.olric")
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if len(plan.Moves) != 23 || plan.Moves[0].To != nil {
+		t.Fatalf("Expected every partition to lose its owner: %+v", plan.Moves)
+	}
+	if err := c.CompleteDecommission("node0.olric"); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if c.LocateKey([]byte("Olric")) != nil {
+		t.Fatalf("Expected an empty ring")
+	}
+}
+
+func TestDecommissionNotEnoughRoom(t *testing.T) {
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric"), testMember("node2.olric")}, newConfig())
+	// node2.olric may own at most 15 partitions without node0.olric, that leaves no room for 3 of them.
+	if err := c.SetMaxLoad("node1.olric", 5); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if _, err := c.Decommission("node0.olric"); err == nil {
+		t.Fatalf("Expected an error")
+	} else if _, ok := err.(*DistributionError); !ok {
+		t.Fatalf("Expected a DistributionError, Got: %v", err)
+	}
+}
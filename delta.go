@@ -0,0 +1,154 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// LayoutDelta contains the partitions whose owner changed between two versions of the partition table, see
+// DeltaSince.
+type LayoutDelta struct {
+	// From is the version the delta starts from.
+	From uint64
+
+	// To is the version the delta leads to, the current version when it was computed.
+	To uint64
+
+	// Moves are the partitions whose owner differs between the versions, ordered by partition ID. From is the
+	// owner at version From, To the owner at version To. Either is nil if the ring had no members.
+	Moves []PartitionMove
+}
+
+// RoutingDelta returns the delta in the form applied by RoutingTable.Apply.
+func (d LayoutDelta) RoutingDelta() RoutingDelta {
+	rd := RoutingDelta{
+		From:    d.From,
+		To:      d.To,
+		Changed: make(map[int]string, len(d.Moves)),
+	}
+	for _, move := range d.Moves {
+		var name string
+		if move.To != nil {
+			name = move.To.String()
+		}
+		rd.Changed[move.PartitionID] = name
+	}
+	return rd
+}
+
+// layoutChange is an entry of the history kept for DeltaSince. It holds the partitions which moved when the
+// version of the partition table went from from to from+1.
+type layoutChange struct {
+	from  uint64
+	moves []PartitionMove
+}
+
+// recordLayout appends the change from the previous partition table to the current one to the history, if
+// Config.DeltaHistory is set, and passes it to Config.AssignmentSink and the watchers of WatchMember. It must be
+// called right before the version is bumped. It's not thread-safe.
+func (c *Consistent) recordLayout(previous map[int]*Member) {
+	c.recordChurn(previous)
+	c.notifyWatchers(previous)
+	if c.config.DeltaHistory <= 0 && c.config.AssignmentSink == nil {
+		return
+	}
+	change := layoutChange{from: c.version}
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		var from, to Member
+		if m, ok := previous[partID]; ok {
+			from = *m
+		}
+		if m, ok := c.partitions[partID]; ok {
+			to = *m
+		}
+		if !sameMember(from, to) {
+			change.moves = append(change.moves, PartitionMove{PartitionID: partID, From: from, To: to})
+		}
+	}
+	if c.config.AssignmentSink != nil {
+		c.config.AssignmentSink.RecordAssignments(c.clock().Now(), LayoutDelta{
+			From:  change.from,
+			To:    change.from + 1,
+			Moves: change.moves,
+		})
+	}
+	if c.config.DeltaHistory <= 0 {
+		return
+	}
+	if len(c.history) == c.config.DeltaHistory {
+		// Drop the oldest change. Moving the slice forward keeps the retained changes contiguous.
+		copy(c.history, c.history[1:])
+		c.history = c.history[:len(c.history)-1]
+	}
+	c.history = append(c.history, change)
+}
+
+// DeltaSince returns the partitions whose owner changed since the given version of the partition table. Clients
+// which keep a copy of the table only need to fetch the delta after a change. The last Config.DeltaHistory
+// changes are retained, and the snapshots of Config.HistorySize; it returns ErrVersionUnavailable if version is
+// in neither, newer than the current version, or if both are disabled. Fetch the whole table in that case.
+func (c *Consistent) DeltaSince(version uint64) (LayoutDelta, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.deltaSince(version)
+}
+
+// deltaSince computes the delta of DeltaSince. It's not thread-safe.
+func (c *Consistent) deltaSince(version uint64) (LayoutDelta, error) {
+	if version == c.version {
+		return LayoutDelta{From: version, To: version}, nil
+	}
+	if version > c.version {
+		return LayoutDelta{}, ErrVersionUnavailable
+	}
+	start := -1
+	for i, change := range c.history {
+		if change.from == version {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		if d, ok := c.deltaFromSnapshot(version); ok {
+			return d, nil
+		}
+		return LayoutDelta{}, ErrVersionUnavailable
+	}
+
+	// Compose the changes: the first move of a partition carries its old owner, the last one its new owner.
+	moves := make(map[int]PartitionMove)
+	for _, change := range c.history[start:] {
+		for _, move := range change.moves {
+			if m, ok := moves[move.PartitionID]; ok {
+				move.From = m.From
+			}
+			moves[move.PartitionID] = move
+		}
+	}
+	d := LayoutDelta{From: version, To: c.version}
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		if move, ok := moves[partID]; ok && !sameMember(move.From, move.To) {
+			d.Moves = append(d.Moves, move)
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestDeltaSince(t *testing.T) {
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.DeltaHistory = 3
+	var members []Member
+	for i := 0; i < 4; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c := New(members, cfg)
+	base := c.Version()
+	table := c.RoutingTable()
+
+	c.Add(testMember("node4.olric"))
+	c.Remove("node1.olric")
+	delta, err := c.DeltaSince(base)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if delta.From != base || delta.To != c.Version() {
+		t.Fatalf("Unexpected versions: %d..%d", delta.From, delta.To)
+	}
+	for _, move := range delta.Moves {
+		if move.From.String() != table.Owner(move.PartitionID) {
+			t.Fatalf("Partition %d: expected %s as the old owner, Got: %s", move.PartitionID, table.Owner(move.PartitionID), move.From)
+		}
+		if move.To.String() != c.GetPartitionOwner(move.PartitionID).String() || move.From.String() == move.To.String() {
+			t.Fatalf("Unexpected move: %v", move)
+		}
+	}
+	applied, err := table.Apply(delta.RoutingDelta())
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if !reflect.DeepEqual(applied, c.RoutingTable()) {
+		t.Fatalf("Expected the current routing table")
+	}
+
+	if delta, err := c.DeltaSince(c.Version()); err != nil || len(delta.Moves) != 0 {
+		t.Fatalf("Expected an empty delta, Got: %v, %v", delta, err)
+	}
+	if _, err := c.DeltaSince(c.Version() + 1); err != ErrVersionUnavailable {
+		t.Fatalf("Expected ErrVersionUnavailable, Got: %v", err)
+	}
+
+	// Only the last three changes are retained.
+	c.Add(testMember("node5.olric"))
+	c.Add(testMember("node6.olric"))
+	if _, err := c.DeltaSince(base); err != ErrVersionUnavailable {
+		t.Fatalf("Expected ErrVersionUnavailable, Got: %v", err)
+	}
+	if _, err := c.DeltaSince(base + 1); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+}
+
+func TestDeltaSinceDisabled(t *testing.T) {
+	c := New([]Member{testMember("node0.olric")}, newConfig())
+	version := c.Version()
+	c.Add(testMember("node1.olric"))
+	if _, err := c.DeltaSince(version); err != ErrVersionUnavailable {
+		t.Fatalf("Expected ErrVersionUnavailable, Got: %v", err)
+	}
+}
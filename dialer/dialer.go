@@ -0,0 +1,275 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package dialer resolves the owner of a key and shares one connection per member, so a relocation doesn't make
+// every goroutine which routes to the new owner dial it at once:
+//
+//	d := dialer.New(c, dialer.Config[*grpc.ClientConn]{
+//		Dial: func(ctx context.Context, m consistent.Member) (*grpc.ClientConn, error) {
+//			return grpc.DialContext(ctx, m.String())
+//		},
+//		Close: (*grpc.ClientConn).Close,
+//	})
+//	member, conn, err := d.Get(ctx, key)
+//
+// Concurrent dials of the same member for the same version of the partition table are deduplicated with
+// golang.org/x/sync/singleflight. It needs Go 1.18 for the type parameter.
+package dialer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/buraksezer/consistent"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrNoMember represents an error which means that the ring has no member which owns the key, or the member left
+// the ring while it was dialed.
+var ErrNoMember = errors.New("no available member")
+
+// DefaultDialTimeout is the time a dial may take if Config.DialTimeout is zero.
+const DefaultDialTimeout = 10 * time.Second
+
+// Config configures a Dialer. Dial is required.
+type Config[C any] struct {
+	// Dial connects to the member. The callers which wait for the same dial share its result, so the context
+	// isn't the one of a caller: it's canceled after DialTimeout. A caller whose context is done stops waiting.
+	Dial func(ctx context.Context, member consistent.Member) (C, error)
+
+	// DialTimeout bounds a dial. Zero means DefaultDialTimeout.
+	DialTimeout time.Duration
+
+	// Close closes the connection of a member which left the ring or was invalidated, if it's set.
+	Close func(conn C) error
+
+	// OnPartitionsChanged is called with the partitions a member gained and lost, in ascending order, when the
+	// Dialer notices a new version of the partition table, if it's set. It's called without holding a lock.
+	OnPartitionsChanged func(member string, gained, lost []int)
+}
+
+// Dialer resolves the owners of keys and keeps one connection per member. It's safe for concurrent use.
+type Dialer[C any] struct {
+	ring  *consistent.Consistent
+	cfg   Config[C]
+	group singleflight.Group
+
+	mu    sync.Mutex
+	conns map[string]C
+	table consistent.RoutingTable
+}
+
+// New creates a Dialer for the given ring.
+func New[C any](c *consistent.Consistent, cfg Config[C]) *Dialer[C] {
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = DefaultDialTimeout
+	}
+	return &Dialer[C]{
+		ring:  c,
+		cfg:   cfg,
+		conns: make(map[string]C),
+		table: c.RoutingTable(),
+	}
+}
+
+// Get returns the owner of the key and a connection to it, dialing it if there is none yet. It returns
+// ErrNoMember if the ring is empty, the error of Dial if it fails and the error of the context if it's done
+// before the dial finishes.
+func (d *Dialer[C]) Get(ctx context.Context, key []byte) (consistent.Member, C, error) {
+	var zero C
+	version := d.ring.Version()
+	d.mu.Lock()
+	stale := version != d.table.Version
+	d.mu.Unlock()
+	if stale {
+		d.Refresh()
+	}
+
+	member := d.ring.LocateKey(key)
+	if member == nil {
+		return nil, zero, ErrNoMember
+	}
+	conn, err := d.conn(ctx, member, version)
+	if err != nil {
+		return nil, zero, err
+	}
+	return member, conn, nil
+}
+
+// Conn returns a connection to the member, dialing it if there is none yet.
+func (d *Dialer[C]) Conn(ctx context.Context, member consistent.Member) (C, error) {
+	return d.conn(ctx, member, d.ring.Version())
+}
+
+func (d *Dialer[C]) conn(ctx context.Context, member consistent.Member, version uint64) (C, error) {
+	name := member.String()
+	d.mu.Lock()
+	conn, ok := d.conns[name]
+	d.mu.Unlock()
+	if ok {
+		return conn, nil
+	}
+
+	ch := d.group.DoChan(strconv.FormatUint(version, 10)+"/"+name, func() (interface{}, error) {
+		return d.dial(member)
+	})
+	var zero C
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		return res.Val.(C), nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// dial connects to the member and caches the connection unless the member left the ring meanwhile.
+func (d *Dialer[C]) dial(member consistent.Member) (C, error) {
+	var zero C
+	name := member.String()
+	d.mu.Lock()
+	conn, ok := d.conns[name]
+	d.mu.Unlock()
+	if ok {
+		return conn, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d.cfg.DialTimeout)
+	defer cancel()
+	conn, err := d.cfg.Dial(ctx, member)
+	if err != nil {
+		return zero, err
+	}
+
+	// Refresh reads the members under the lock as well, so it either sees the connection or the dial sees
+	// that the member left.
+	d.mu.Lock()
+	cached := d.hasMember(name)
+	if cached {
+		d.conns[name] = conn
+	}
+	d.mu.Unlock()
+	if !cached {
+		if d.cfg.Close != nil {
+			_ = d.cfg.Close(conn)
+		}
+		return zero, ErrNoMember
+	}
+	return conn, nil
+}
+
+// hasMember reports whether the member is on the ring.
+func (d *Dialer[C]) hasMember(name string) bool {
+	for _, member := range d.ring.GetMembers() {
+		if member.String() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Invalidate closes and forgets the connection of the member, e.g. after it broke. The next Get dials again.
+func (d *Dialer[C]) Invalidate(name string) {
+	d.mu.Lock()
+	conn, ok := d.conns[name]
+	delete(d.conns, name)
+	d.mu.Unlock()
+	if ok && d.cfg.Close != nil {
+		_ = d.cfg.Close(conn)
+	}
+}
+
+// Refresh compares the partition table of the ring with the one seen last. It calls OnPartitionsChanged for the
+// members whose partitions changed and closes the connections of the members which left the ring. Get calls it
+// when the version of the ring changes.
+func (d *Dialer[C]) Refresh() {
+	d.mu.Lock()
+	table := d.ring.RoutingTable()
+	members := make(map[string]struct{})
+	for _, member := range d.ring.GetMembers() {
+		members[member.String()] = struct{}{}
+	}
+	if table.Version == d.table.Version {
+		d.mu.Unlock()
+		return
+	}
+	previous := d.table
+	d.table = table
+	var closed []C
+	for name, conn := range d.conns {
+		if _, ok := members[name]; !ok {
+			closed = append(closed, conn)
+			delete(d.conns, name)
+		}
+	}
+	d.mu.Unlock()
+
+	if d.cfg.Close != nil {
+		for _, conn := range closed {
+			_ = d.cfg.Close(conn)
+		}
+	}
+	if d.cfg.OnPartitionsChanged != nil {
+		changes(previous, table, d.cfg.OnPartitionsChanged)
+	}
+}
+
+// changes calls f for every member which gained or lost partitions between the tables, in ascending order of
+// the member names.
+func changes(previous, next consistent.RoutingTable, f func(member string, gained, lost []int)) {
+	gained := make(map[string][]int)
+	lost := make(map[string][]int)
+	for partID, name := range next.Owners {
+		if previous.Owners[partID] != name {
+			gained[name] = append(gained[name], partID)
+			if from := previous.Owners[partID]; from != "" {
+				lost[from] = append(lost[from], partID)
+			}
+		}
+	}
+	for partID, name := range previous.Owners {
+		if _, ok := next.Owners[partID]; !ok {
+			lost[name] = append(lost[name], partID)
+		}
+	}
+	var names []string
+	for name := range gained {
+		names = append(names, name)
+	}
+	for name := range lost {
+		if _, ok := gained[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sort.Ints(gained[name])
+		sort.Ints(lost[name])
+		f(name, gained[name], lost[name])
+	}
+}
@@ -0,0 +1,288 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dialer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/buraksezer/consistent"
+)
+
+type hasher struct{}
+
+func (hasher) Sum64(data []byte) uint64 {
+	h := fnv.New64()
+	_, _ = h.Write(data)
+	return h.Sum64()
+}
+
+type member string
+
+func (m member) String() string {
+	return string(m)
+}
+
+type conn struct {
+	member string
+	closed int32
+}
+
+func newRing() *consistent.Consistent {
+	var members []consistent.Member
+	for i := 0; i < 4; i++ {
+		members = append(members, member(fmt.Sprintf("node%d.olric", i)))
+	}
+	return consistent.New(members, consistent.Config{
+		PartitionCount:    23,
+		ReplicationFactor: 20,
+		Load:              1.25,
+		Hasher:            hasher{},
+	})
+}
+
+func TestDialerDeduplicates(t *testing.T) {
+	c := newRing()
+	var dials int32
+	release := make(chan struct{})
+	d := New(c, Config[*conn]{
+		Dial: func(ctx context.Context, m consistent.Member) (*conn, error) {
+			atomic.AddInt32(&dials, 1)
+			<-release
+			return &conn{member: m.String()}, nil
+		},
+	})
+
+	key := []byte("my-key")
+	var wg sync.WaitGroup
+	conns := make([]*conn, 50)
+	for i := range conns {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m, cn, err := d.Get(context.Background(), key)
+			if err != nil || m.String() != cn.member {
+				t.Errorf("Unexpected result: %v, %v, %v", m, cn, err)
+			}
+			conns[i] = cn
+		}(i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&dials); n != 1 {
+		t.Fatalf("Expected a single dial, Got: %d", n)
+	}
+	for _, cn := range conns {
+		if cn != conns[0] {
+			t.Fatalf("Expected the callers to share the connection")
+		}
+	}
+}
+
+func TestDialerRefresh(t *testing.T) {
+	c := newRing()
+	var mu sync.Mutex
+	gained := make(map[string][]int)
+	lost := make(map[string][]int)
+	d := New(c, Config[*conn]{
+		Dial: func(ctx context.Context, m consistent.Member) (*conn, error) {
+			return &conn{member: m.String()}, nil
+		},
+		Close: func(cn *conn) error {
+			atomic.StoreInt32(&cn.closed, 1)
+			return nil
+		},
+		OnPartitionsChanged: func(name string, g, l []int) {
+			mu.Lock()
+			defer mu.Unlock()
+			gained[name] = g
+			lost[name] = l
+		},
+	})
+
+	var conns []*conn
+	for _, m := range c.GetMembers() {
+		cn, err := d.Conn(context.Background(), m)
+		if err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+		conns = append(conns, cn)
+	}
+	owned := make(map[int]bool)
+	for partID := 0; partID < 23; partID++ {
+		if c.GetPartitionOwner(partID).String() == "node0.olric" {
+			owned[partID] = true
+		}
+	}
+	c.Remove("node0.olric")
+	if _, _, err := d.Get(context.Background(), []byte("my-key")); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(lost["node0.olric"]) != len(owned) || len(gained["node0.olric"]) != 0 {
+		t.Fatalf("Expected node0.olric to lose its %d partitions, Got: %v", len(owned), lost["node0.olric"])
+	}
+	var total int
+	for name, partitions := range gained {
+		for _, partID := range partitions {
+			if owned[partID] {
+				total++
+			}
+		}
+		if name == "node0.olric" && len(partitions) != 0 {
+			t.Fatalf("Expected node0.olric not to gain partitions")
+		}
+	}
+	if total != len(owned) {
+		t.Fatalf("Expected the partitions of node0.olric to be gained, Got: %d", total)
+	}
+	for _, cn := range conns {
+		closed := atomic.LoadInt32(&cn.closed) == 1
+		if closed != (cn.member == "node0.olric") {
+			t.Fatalf("Unexpected state of the connection to %s: closed %v", cn.member, closed)
+		}
+	}
+}
+
+func TestDialerErrors(t *testing.T) {
+	errDown := errors.New("down")
+	var fail int32 = 1
+	d := New(newRing(), Config[*conn]{
+		Dial: func(ctx context.Context, m consistent.Member) (*conn, error) {
+			if atomic.LoadInt32(&fail) == 1 {
+				return nil, errDown
+			}
+			return &conn{member: m.String()}, nil
+		},
+	})
+	if _, _, err := d.Get(context.Background(), []byte("my-key")); err != errDown {
+		t.Fatalf("Expected errDown, Got: %v", err)
+	}
+	atomic.StoreInt32(&fail, 0)
+	m, first, err := d.Get(context.Background(), []byte("my-key"))
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	d.Invalidate(m.String())
+	if _, second, _ := d.Get(context.Background(), []byte("my-key")); second == first {
+		t.Fatalf("Expected a new connection after Invalidate")
+	}
+
+	empty := New(consistent.New(nil, consistent.Config{PartitionCount: 23, ReplicationFactor: 20, Load: 1.25, Hasher: hasher{}}), Config[*conn]{})
+	if _, _, err := empty.Get(context.Background(), []byte("my-key")); err != ErrNoMember {
+		t.Fatalf("Expected ErrNoMember, Got: %v", err)
+	}
+}
+
+func TestDialerDetachedContext(t *testing.T) {
+	c := newRing()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	d := New(c, Config[*conn]{
+		Dial: func(ctx context.Context, m consistent.Member) (*conn, error) {
+			close(started)
+			select {
+			case <-release:
+				return &conn{member: m.String()}, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		},
+	})
+
+	key := []byte("my-key")
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := d.Get(ctx, key)
+		errs <- err
+	}()
+	<-started
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := d.Get(context.Background(), key)
+		done <- err
+	}()
+	cancel()
+	if err := <-errs; err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, Got: %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Expected the other caller to get the connection, Got: %v", err)
+	}
+}
+
+func TestDialerDialTimeout(t *testing.T) {
+	d := New(newRing(), Config[*conn]{
+		Dial: func(ctx context.Context, m consistent.Member) (*conn, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		DialTimeout: 10 * time.Millisecond,
+	})
+	if _, _, err := d.Get(context.Background(), []byte("my-key")); err != context.DeadlineExceeded {
+		t.Fatalf("Expected context.DeadlineExceeded, Got: %v", err)
+	}
+}
+
+func TestDialerMemberLeft(t *testing.T) {
+	c := newRing()
+	key := []byte("my-key")
+	owner := c.LocateKey(key)
+	var dialed *conn
+	d := New(c, Config[*conn]{
+		Dial: func(ctx context.Context, m consistent.Member) (*conn, error) {
+			// The member leaves and the Dialer notices it before the dial finishes.
+			c.Remove(m.String())
+			dialed = &conn{member: m.String()}
+			return dialed, nil
+		},
+		Close: func(cn *conn) error {
+			atomic.StoreInt32(&cn.closed, 1)
+			return nil
+		},
+	})
+	d.Refresh()
+	if _, err := d.Conn(context.Background(), owner); err != ErrNoMember {
+		t.Fatalf("Expected ErrNoMember, Got: %v", err)
+	}
+	if atomic.LoadInt32(&dialed.closed) != 1 {
+		t.Fatalf("Expected the connection to the departed member to be closed")
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.conns[owner.String()]; ok {
+		t.Fatalf("Expected no connection to the departed member")
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/buraksezer/consistent/dialer
+
+go 1.18
+
+require (
+	github.com/buraksezer/consistent v0.0.0
+	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
+)
+
+replace github.com/buraksezer/consistent => ../
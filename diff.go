@@ -0,0 +1,124 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// PartitionMove describes a partition whose owner differs between two consistent hash rings.
+type PartitionMove struct {
+	// PartitionID is the ID of the partition.
+	PartitionID int
+
+	// From is the owner of the partition on the receiver of Diff. It is nil if the partition has no owner there.
+	From Member
+
+	// To is the owner of the partition on the other ring. It is nil if the partition has no owner there.
+	To Member
+}
+
+// owners returns a thread-safe copy of the partition table. Partitions without an owner are nil. A nil ring has
+// no partitions.
+func (c *Consistent) owners() []Member {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	owners := make([]Member, c.partitionCount)
+	for partID := range owners {
+		owners[partID] = c.getPartitionOwner(partID)
+	}
+	return owners
+}
+
+// memberNames returns the names of all members as a set. A nil ring has no members.
+func (c *Consistent) memberNames() map[string]struct{} {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := make(map[string]struct{}, len(c.members))
+	for name := range c.members {
+		names[name] = struct{}{}
+	}
+	return names
+}
+
+// Diff compares the partition tables of c and other and returns the partitions whose owners differ, ordered by
+// partition ID. Partitions that exist on only one of the rings are reported with a nil owner on the other side.
+// Owners are compared by name. A nil ring is an empty one.
+func (c *Consistent) Diff(other *Consistent) []PartitionMove {
+	if c == other {
+		return nil
+	}
+	// Take the copies one by one. Holding both locks at once may deadlock with a concurrent other.Diff(c).
+	a, b := c.owners(), other.owners()
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+
+	var moves []PartitionMove
+	for partID := 0; partID < n; partID++ {
+		var from, to Member
+		if partID < len(a) {
+			from = a[partID]
+		}
+		if partID < len(b) {
+			to = b[partID]
+		}
+		if sameMember(from, to) {
+			continue
+		}
+		moves = append(moves, PartitionMove{PartitionID: partID, From: from, To: to})
+	}
+	return moves
+}
+
+// Equal reports whether c and other have the same members and the same partition table. This is the case when
+// two nodes independently computed the same layout. A nil ring is an empty one.
+func (c *Consistent) Equal(other *Consistent) bool {
+	if c == other {
+		return true
+	}
+	a, b := c.memberNames(), other.memberNames()
+	if len(a) != len(b) {
+		return false
+	}
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			return false
+		}
+	}
+	return len(c.Diff(other)) == 0
+}
+
+// sameMember reports whether a and b are both nil or have the same name.
+func sameMember(a, b Member) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.String() == b.String()
+}
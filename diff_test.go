@@ -0,0 +1,87 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestConsistentDiff(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+
+	t.Run("Same layout", func(t *testing.T) {
+		c1 := New(members, cfg)
+		// Insertion order must not change the layout.
+		reversed := make([]Member, 0, len(members))
+		for i := len(members) - 1; i >= 0; i-- {
+			reversed = append(reversed, members[i])
+		}
+		c2 := New(reversed, cfg)
+		if !c1.Equal(c2) {
+			t.Fatalf("Expected equal layouts")
+		}
+		if moves := c1.Diff(c2); len(moves) != 0 {
+			t.Fatalf("Expected no moves, Got: %v", moves)
+		}
+	})
+
+	t.Run("Added member", func(t *testing.T) {
+		c1 := New(members, cfg)
+		c2 := New(members, cfg)
+		newMember := testMember("node8.olric")
+		c2.Add(newMember)
+		if c1.Equal(c2) {
+			t.Fatalf("Expected different layouts")
+		}
+		moves := c1.Diff(c2)
+		if len(moves) == 0 {
+			t.Fatalf("Expected moves")
+		}
+		for _, move := range moves {
+			if c1.GetPartitionOwner(move.PartitionID).String() != move.From.String() {
+				t.Fatalf("Unexpected source of partition %d: %s", move.PartitionID, move.From)
+			}
+			if c2.GetPartitionOwner(move.PartitionID).String() != move.To.String() {
+				t.Fatalf("Unexpected target of partition %d: %s", move.PartitionID, move.To)
+			}
+		}
+	})
+
+	t.Run("Empty ring", func(t *testing.T) {
+		c1 := New(nil, cfg)
+		c2 := New(members, cfg)
+		moves := c1.Diff(c2)
+		if len(moves) != cfg.PartitionCount {
+			t.Fatalf("Expected %d moves, Got: %d", cfg.PartitionCount, len(moves))
+		}
+		if moves[0].From != nil || moves[0].To == nil {
+			t.Fatalf("Unexpected move: %v", moves[0])
+		}
+	})
+}
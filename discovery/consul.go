@@ -0,0 +1,182 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ConsulWatcher watches the healthy instances of a Consul service with blocking queries.
+type ConsulWatcher struct {
+	// Address is the address of the Consul HTTP API, e.g. http://127.0.0.1:8500.
+	Address string
+
+	// Service is the name of the service.
+	Service string
+
+	// Tag filters the instances by tag, if it's set.
+	Tag string
+
+	// Token is the ACL token, if it's set.
+	Token string
+
+	// WaitTime is the maximum duration of a blocking query. Consul's default is used if it's zero.
+	WaitTime time.Duration
+
+	// RetryInterval is the time to wait after a failed query. It's one second if it's zero.
+	RetryInterval time.Duration
+
+	// OnError is called with the errors of failed queries, if it's set. The watcher retries them.
+	OnError func(error)
+
+	// Client is the HTTP client. http.DefaultClient is used if it's nil.
+	Client *http.Client
+}
+
+type consulEntry struct {
+	Node struct {
+		Address string
+	}
+	Service struct {
+		ID      string
+		Address string
+		Port    int
+		Meta    map[string]string
+	}
+}
+
+// Watch implements Watcher. It runs until ctx is done and returns ctx.Err().
+func (w *ConsulWatcher) Watch(ctx context.Context, update func([]Instance)) error {
+	var index uint64
+	first := true
+	for {
+		instances, next, err := w.query(ctx, index)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if w.OnError != nil {
+				w.OnError(err)
+			}
+			if err := sleep(ctx, w.RetryInterval); err != nil {
+				return err
+			}
+			continue
+		}
+		if next < index {
+			// The index went backwards, e.g. after a Consul restart. Start over.
+			next = 0
+		}
+		if first || next != index {
+			update(instances)
+			first = false
+		}
+		index = next
+	}
+}
+
+// consulWait formats the wait parameter of a blocking query. Consul takes milliseconds, rounding up keeps a
+// sub-millisecond duration from becoming zero, which would make Consul take its default.
+func consulWait(d time.Duration) string {
+	return fmt.Sprintf("%dms", (d+time.Millisecond-1)/time.Millisecond)
+}
+
+func (w *ConsulWatcher) query(ctx context.Context, index uint64) ([]Instance, uint64, error) {
+	q := url.Values{"passing": {"true"}, "index": {strconv.FormatUint(index, 10)}}
+	if w.Tag != "" {
+		q.Set("tag", w.Tag)
+	}
+	if w.WaitTime > 0 {
+		q.Set("wait", consulWait(w.WaitTime))
+	}
+	u := fmt.Sprintf("%s/v1/health/service/%s?%s", w.Address, url.PathEscape(w.Service), q.Encode())
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req = req.WithContext(ctx)
+	if w.Token != "" {
+		req.Header.Set("X-Consul-Token", w.Token)
+	}
+
+	resp, err := httpClient(w.Client).Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("consul: unexpected status %s", resp.Status)
+	}
+	next, err := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("consul: invalid X-Consul-Index: %v", err)
+	}
+
+	var entries []consulEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, 0, fmt.Errorf("consul: %v", err)
+	}
+	instances := make([]Instance, 0, len(entries))
+	for _, e := range entries {
+		host := e.Service.Address
+		if host == "" {
+			host = e.Node.Address
+		}
+		instances = append(instances, Instance{
+			ID:      e.Service.ID,
+			Address: net.JoinHostPort(host, strconv.Itoa(e.Service.Port)),
+			Meta:    e.Service.Meta,
+		})
+	}
+	return instances, next, nil
+}
+
+func httpClient(client *http.Client) *http.Client {
+	if client == nil {
+		return http.DefaultClient
+	}
+	return client
+}
+
+// sleep waits for d, one second if it's zero, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		d = time.Second
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConsulWatcher(t *testing.T) {
+	var mu sync.Mutex
+	index := 10
+	entries := `[{"Node":{"Address":"10.0.0.1"},"Service":{"ID":"cache-1","Port":8080,"Meta":{"weight":"2"}}},
+		{"Node":{"Address":"10.0.0.2"},"Service":{"ID":"cache-2","Address":"10.1.0.2","Port":8080}}]`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/health/service/cache" || r.URL.Query().Get("passing") != "true" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("wait") != "1000ms" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("X-Consul-Token") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Query().Get("index") == fmt.Sprint(index) {
+			// Nothing changed, pretend that the blocking query timed out.
+			time.Sleep(10 * time.Millisecond)
+			index++
+			entries = `[{"Node":{"Address":"10.0.0.1"},"Service":{"ID":"cache-1","Port":8080}}]`
+		}
+		w.Header().Set("X-Consul-Index", fmt.Sprint(index))
+		fmt.Fprint(w, entries)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var updates [][]Instance
+	w := &ConsulWatcher{Address: srv.URL, Service: "cache", Token: "secret", WaitTime: time.Second}
+	err := w.Watch(ctx, func(instances []Instance) {
+		updates = append(updates, instances)
+		if len(updates) == 2 {
+			cancel()
+		}
+	})
+	if err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, Got: %v", err)
+	}
+	if len(updates[0]) != 2 || updates[0][0].Address != "10.0.0.1:8080" || updates[0][1].Address != "10.1.0.2:8080" {
+		t.Fatalf("Unexpected instances: %+v", updates[0])
+	}
+	if updates[0][0].Meta["weight"] != "2" {
+		t.Fatalf("Unexpected meta: %+v", updates[0][0].Meta)
+	}
+	if len(updates[1]) != 1 || updates[1][0].ID != "cache-1" {
+		t.Fatalf("Unexpected instances: %+v", updates[1])
+	}
+}
+
+func TestConsulWait(t *testing.T) {
+	cases := map[time.Duration]string{
+		time.Nanosecond:         "1ms",
+		500 * time.Millisecond:  "500ms",
+		1500 * time.Millisecond: "1500ms",
+		time.Minute:             "60000ms",
+	}
+	for d, want := range cases {
+		if got := consulWait(d); got != want {
+			t.Fatalf("Expected %s for %v, Got: %s", want, d, got)
+		}
+	}
+}
+
+func TestConsulWatcherRetry(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("X-Consul-Index", "1")
+		fmt.Fprint(w, `[]`)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var errs []error
+	w := &ConsulWatcher{
+		Address:       srv.URL,
+		Service:       "cache",
+		RetryInterval: time.Millisecond,
+		OnError:       func(err error) { errs = append(errs, err) },
+	}
+	err := w.Watch(ctx, func(instances []Instance) {
+		if len(instances) != 0 {
+			t.Errorf("Expected no instances, Got: %v", instances)
+		}
+		cancel()
+	})
+	if err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, Got: %v", err)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("Expected a single error, Got: %v", errs)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package discovery reconciles the members of a consistent hash ring with a service registry.
+//
+// A Watcher reports the complete set of instances of a service every time it changes, Sync translates them into
+// members and applies them with SetMembers, which distributes the partitions once per update:
+//
+//	w := &discovery.ConsulWatcher{Address: "http://127.0.0.1:8500", Service: "cache"}
+//	err := discovery.Sync(ctx, ring, w, discovery.Translator{})
+//
+// ConsulWatcher and EtcdWatcher talk to the HTTP APIs of Consul and etcd directly, so the package doesn't pull the
+// client libraries in.
+package discovery
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/buraksezer/consistent"
+)
+
+// Default metadata keys used by Translator.
+const (
+	DefaultWeightKey = "weight"
+	DefaultZoneKey   = "zone"
+)
+
+// Instance is an instance of a service reported by a registry.
+type Instance struct {
+	// ID identifies the instance and becomes the member name on the ring.
+	ID string
+
+	// Address is the address of the instance, usually host:port.
+	Address string
+
+	// Meta is the metadata of the instance, e.g. Consul service meta.
+	Meta map[string]string
+}
+
+// Member is a member of the ring built from an Instance. It implements consistent.WeightedMember.
+type Member struct {
+	Instance
+
+	weight float64
+	zone   string
+}
+
+// String returns the ID of the instance.
+func (m Member) String() string {
+	return m.ID
+}
+
+// Weight returns the weight of the member. It's 1 if the instance has no valid weight in its metadata.
+func (m Member) Weight() float64 {
+	return m.weight
+}
+
+// Zone returns the zone of the member. It's empty if the instance has no zone in its metadata.
+func (m Member) Zone() string {
+	return m.zone
+}
+
+// Translator translates the metadata of instances into member weights and zones.
+type Translator struct {
+	// WeightKey is the metadata key of the weight. Its value must be a positive number. DefaultWeightKey is
+	// used if it's empty.
+	WeightKey string
+
+	// ZoneKey is the metadata key of the zone. DefaultZoneKey is used if it's empty.
+	ZoneKey string
+}
+
+// Member returns the member for the instance.
+func (t Translator) Member(instance Instance) Member {
+	weightKey, zoneKey := t.WeightKey, t.ZoneKey
+	if weightKey == "" {
+		weightKey = DefaultWeightKey
+	}
+	if zoneKey == "" {
+		zoneKey = DefaultZoneKey
+	}
+
+	m := Member{Instance: instance, weight: 1, zone: instance.Meta[zoneKey]}
+	if w, err := strconv.ParseFloat(instance.Meta[weightKey], 64); err == nil && w > 0 {
+		m.weight = w
+	}
+	return m
+}
+
+// Members returns the members for the instances.
+func (t Translator) Members(instances []Instance) []consistent.Member {
+	members := make([]consistent.Member, 0, len(instances))
+	for _, instance := range instances {
+		members = append(members, t.Member(instance))
+	}
+	return members
+}
+
+// Watcher watches a service registry.
+type Watcher interface {
+	// Watch calls update with the complete set of instances every time it changes, until ctx is done or an
+	// unrecoverable error occurs. The first call reports the current instances.
+	Watch(ctx context.Context, update func([]Instance)) error
+}
+
+// Sync makes the instances reported by w the members of ring, until ctx is done or w fails. It returns the error
+// of w.
+func Sync(ctx context.Context, ring *consistent.Consistent, w Watcher, t Translator) error {
+	return w.Watch(ctx, func(instances []Instance) {
+		ring.SetMembers(t.Members(instances))
+	})
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"testing"
+
+	"github.com/buraksezer/consistent"
+)
+
+type hasher struct{}
+
+func (hs hasher) Sum64(data []byte) uint64 {
+	h := fnv.New64()
+	_, _ = h.Write(data)
+	return h.Sum64()
+}
+
+func newRing() *consistent.Consistent {
+	return consistent.New(nil, consistent.Config{
+		PartitionCount:    23,
+		ReplicationFactor: 20,
+		Load:              1.25,
+		Hasher:            hasher{},
+	})
+}
+
+func TestTranslator(t *testing.T) {
+	m := Translator{}.Member(Instance{ID: "a", Meta: map[string]string{"weight": "2.5", "zone": "eu-1"}})
+	if m.String() != "a" || m.Weight() != 2.5 || m.Zone() != "eu-1" {
+		t.Fatalf("Unexpected member: %+v", m)
+	}
+	m = Translator{}.Member(Instance{ID: "b", Meta: map[string]string{"weight": "-1"}})
+	if m.Weight() != 1 || m.Zone() != "" {
+		t.Fatalf("Unexpected member: %+v", m)
+	}
+	tr := Translator{WeightKey: "capacity", ZoneKey: "rack"}
+	m = tr.Member(Instance{ID: "c", Meta: map[string]string{"capacity": "3", "rack": "r1", "weight": "2"}})
+	if m.Weight() != 3 || m.Zone() != "r1" {
+		t.Fatalf("Unexpected member: %+v", m)
+	}
+}
+
+type staticWatcher [][]Instance
+
+func (w staticWatcher) Watch(ctx context.Context, update func([]Instance)) error {
+	for _, instances := range w {
+		update(instances)
+	}
+	return errors.New("done")
+}
+
+func TestSync(t *testing.T) {
+	ring := newRing()
+	w := staticWatcher{
+		{{ID: "a"}, {ID: "b"}, {ID: "c"}},
+		{{ID: "b"}, {ID: "c", Meta: map[string]string{"weight": "2"}}, {ID: "d"}},
+	}
+	if err := Sync(context.Background(), ring, w, Translator{}); err == nil || err.Error() != "done" {
+		t.Fatalf("Expected the error of the watcher, Got: %v", err)
+	}
+	names := make(map[string]float64)
+	for _, m := range ring.GetMembers() {
+		names[m.String()] = m.(Member).Weight()
+	}
+	if len(names) != 3 || names["c"] != 2 {
+		t.Fatalf("Unexpected members: %v", names)
+	}
+	if err := ring.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+}
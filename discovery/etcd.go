@@ -0,0 +1,231 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EtcdWatcher watches the keys under a prefix with the JSON gateway of the etcd v3 API. Every key is an instance,
+// its ID is the key without the prefix. The value is either a JSON object like
+//
+//	{"address": "10.0.0.1:8080", "meta": {"weight": "2", "zone": "eu-west-1a"}}
+//
+// or the plain address of the instance.
+type EtcdWatcher struct {
+	// Endpoint is the address of an etcd member, e.g. http://127.0.0.1:2379.
+	Endpoint string
+
+	// Prefix is the key prefix of the instances.
+	Prefix string
+
+	// RetryInterval is the time to wait after a failed request. It's one second if it's zero.
+	RetryInterval time.Duration
+
+	// OnError is called with the errors of failed requests, if it's set. The watcher retries them.
+	OnError func(error)
+
+	// Client is the HTTP client. http.DefaultClient is used if it's nil.
+	Client *http.Client
+}
+
+type etcdKeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type etcdHeader struct {
+	Revision string `json:"revision"`
+}
+
+type etcdRangeResponse struct {
+	Header etcdHeader     `json:"header"`
+	Kvs    []etcdKeyValue `json:"kvs"`
+}
+
+type etcdWatchResponse struct {
+	Result struct {
+		Header   etcdHeader        `json:"header"`
+		Events   []json.RawMessage `json:"events"`
+		Canceled bool              `json:"canceled"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type etcdValue struct {
+	Address string            `json:"address"`
+	Meta    map[string]string `json:"meta"`
+}
+
+// Watch implements Watcher. It runs until ctx is done and returns ctx.Err().
+func (w *EtcdWatcher) Watch(ctx context.Context, update func([]Instance)) error {
+	for {
+		err := w.watch(ctx, update)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if w.OnError != nil {
+			w.OnError(err)
+		}
+		if err := sleep(ctx, w.RetryInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// watch reads the instances and reports them again after every change, until the watch stream fails.
+func (w *EtcdWatcher) watch(ctx context.Context, update func([]Instance)) error {
+	instances, revision, err := w.list(ctx)
+	if err != nil {
+		return err
+	}
+	update(instances)
+
+	resp, err := w.post(ctx, "/v3/watch", map[string]interface{}{
+		"create_request": map[string]interface{}{
+			"key":            encodeKey(w.Prefix),
+			"range_end":      encodeKey(prefixEnd(w.Prefix)),
+			"start_revision": strconv.FormatInt(revision+1, 10),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg etcdWatchResponse
+		if err := dec.Decode(&msg); err != nil {
+			return fmt.Errorf("etcd: watch: %v", err)
+		}
+		if msg.Error != nil {
+			return fmt.Errorf("etcd: watch: %s", msg.Error.Message)
+		}
+		if msg.Result.Canceled {
+			return fmt.Errorf("etcd: watch canceled")
+		}
+		if len(msg.Result.Events) == 0 {
+			continue
+		}
+		// Read the whole prefix again instead of applying the events, the instances are reported as a set anyway.
+		instances, _, err := w.list(ctx)
+		if err != nil {
+			return err
+		}
+		update(instances)
+	}
+}
+
+// list returns the instances under the prefix and the revision of the store.
+func (w *EtcdWatcher) list(ctx context.Context) ([]Instance, int64, error) {
+	resp, err := w.post(ctx, "/v3/kv/range", map[string]interface{}{
+		"key":       encodeKey(w.Prefix),
+		"range_end": encodeKey(prefixEnd(w.Prefix)),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	var r etcdRangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, 0, fmt.Errorf("etcd: range: %v", err)
+	}
+	revision, err := strconv.ParseInt(r.Header.Revision, 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("etcd: invalid revision: %v", err)
+	}
+
+	instances := make([]Instance, 0, len(r.Kvs))
+	for _, kv := range r.Kvs {
+		key, err := base64.StdEncoding.DecodeString(kv.Key)
+		if err != nil {
+			return nil, 0, fmt.Errorf("etcd: invalid key: %v", err)
+		}
+		value, err := base64.StdEncoding.DecodeString(kv.Value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("etcd: invalid value of %s: %v", key, err)
+		}
+		instance := Instance{ID: strings.TrimPrefix(string(key), w.Prefix)}
+		var v etcdValue
+		if err := json.Unmarshal(value, &v); err == nil {
+			instance.Address, instance.Meta = v.Address, v.Meta
+		} else {
+			instance.Address = string(value)
+		}
+		instances = append(instances, instance)
+	}
+	return instances, revision, nil
+}
+
+func (w *EtcdWatcher) post(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, w.Endpoint+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient(w.Client).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("etcd: unexpected status %s", resp.Status)
+	}
+	return resp, nil
+}
+
+func encodeKey(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// prefixEnd returns the end of the key range of the prefix, like clientv3.GetPrefixRangeEnd.
+func prefixEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+	// The prefix is empty or all 0xff, the range covers every key from the prefix on.
+	return "\x00"
+}
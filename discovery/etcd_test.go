@@ -0,0 +1,148 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package discovery
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeEtcd emulates the range and watch endpoints of the etcd v3 JSON gateway.
+type fakeEtcd struct {
+	mu       sync.Mutex
+	revision int
+	kvs      map[string]string
+	changed  chan struct{}
+}
+
+func (f *fakeEtcd) put(key, value string) {
+	f.mu.Lock()
+	f.kvs[key] = value
+	f.revision++
+	f.mu.Unlock()
+	f.changed <- struct{}{}
+}
+
+func (f *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		start, _ := base64.StdEncoding.DecodeString(req["key"].(string))
+		end, _ := base64.StdEncoding.DecodeString(req["range_end"].(string))
+		f.mu.Lock()
+		var keys []string
+		for k := range f.kvs {
+			if k >= string(start) && k < string(end) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		var kvs []string
+		for _, k := range keys {
+			kvs = append(kvs, fmt.Sprintf(`{"key":%q,"value":%q}`,
+				base64.StdEncoding.EncodeToString([]byte(k)), base64.StdEncoding.EncodeToString([]byte(f.kvs[k]))))
+		}
+		fmt.Fprintf(w, `{"header":{"revision":"%d"},"kvs":[%s]}`, f.revision, strings.Join(kvs, ","))
+		f.mu.Unlock()
+	case "/v3/watch":
+		fmt.Fprint(w, `{"result":{"header":{"revision":"1"},"created":true}}`)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-f.changed:
+				fmt.Fprint(w, `{"result":{"header":{"revision":"2"},"events":[{"type":"PUT"}]}}`)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestEtcdWatcher(t *testing.T) {
+	f := &fakeEtcd{
+		revision: 1,
+		kvs: map[string]string{
+			"/services/cache/a": `{"address":"10.0.0.1:8080","meta":{"weight":"2"}}`,
+			"/services/cache/b": "10.0.0.2:8080",
+			"/services/other/c": "10.0.0.3:8080",
+		},
+		changed: make(chan struct{}, 1),
+	}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var updates [][]Instance
+	w := &EtcdWatcher{Endpoint: srv.URL, Prefix: "/services/cache/"}
+	err := w.Watch(ctx, func(instances []Instance) {
+		updates = append(updates, instances)
+		switch len(updates) {
+		case 1:
+			go f.put("/services/cache/d", "10.0.0.4:8080")
+		case 2:
+			cancel()
+		}
+	})
+	if err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, Got: %v", err)
+	}
+	first := updates[0]
+	if len(first) != 2 || first[0].ID != "a" || first[0].Address != "10.0.0.1:8080" || first[0].Meta["weight"] != "2" {
+		t.Fatalf("Unexpected instances: %+v", first)
+	}
+	if first[1].ID != "b" || first[1].Address != "10.0.0.2:8080" {
+		t.Fatalf("Unexpected instances: %+v", first)
+	}
+	if len(updates[1]) != 3 || updates[1][2].ID != "d" {
+		t.Fatalf("Unexpected instances: %+v", updates[1])
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	tests := map[string]string{
+		"/a/":      "/a0",
+		"a\xff":    "b",
+		"\xff\xff": "\x00",
+		"":         "\x00",
+	}
+	for prefix, want := range tests {
+		if got := prefixEnd(prefix); got != want {
+			t.Fatalf("prefixEnd(%q): expected %q, got %q", prefix, want, got)
+		}
+	}
+}
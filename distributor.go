@@ -0,0 +1,137 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"time"
+)
+
+// Distributor assigns the partitions to the members, see Config.Distributor. Distribute returns the name of the
+// owner of every partition, indexed by partition ID. It's called with the ring locked, so it must not call the
+// methods of the ring; the DistributionContext tells it everything it needs. If it returns an error, the
+// partition table stays as it was and the error is reported like a failed distribution. The backups of
+// Config.BackupCount are computed from the result as usual.
+//
+// Unlike OptimalDistributor, which plans a table offline for ApplyAssignment, a Distributor runs in every
+// distribution.
+type Distributor interface {
+	Distribute(ctx DistributionContext) (map[int]string, error)
+}
+
+// DistributionContext is the state of the ring a Distributor works on. It's only valid during Distribute.
+type DistributionContext struct {
+	c        *Consistent
+	deadline time.Time
+	now      time.Time
+}
+
+// PartitionCount returns the number of partitions to assign.
+func (d DistributionContext) PartitionCount() int {
+	return int(d.c.partitionCount)
+}
+
+// Members returns the members of the ring in the order of the hashes of their names, which doesn't depend on the
+// order they were added in.
+func (d DistributionContext) Members() []Member {
+	members := make([]Member, 0, len(d.c.memberHashes))
+	for _, key := range d.c.memberHashes {
+		members = append(members, *d.c.hashedMembers[key])
+	}
+	return members
+}
+
+// Owner returns the current owner of the partition, nil if it has none.
+func (d DistributionContext) Owner(partID int) Member {
+	return d.c.getPartitionOwner(partID)
+}
+
+// LoadBound returns the number of partitions the member may own under Config.Load, its weight, warmup and the
+// limits of SetMaxLoad. It's zero for draining members. The bound is advisory, the ring doesn't enforce it on
+// the result of a Distributor.
+func (d DistributionContext) LoadBound(member Member) int {
+	return int(d.c.loadBound(member))
+}
+
+// Walk calls f with the members of the virtual nodes clockwise from the point of the partition, the order the
+// bounded-load walk tries them in. A member appears once for each of its virtual nodes. Walk stops when f
+// returns false or after a full circle.
+func (d DistributionContext) Walk(partID int, f func(member Member) bool) {
+	c := d.c
+	if len(c.sortedSet) == 0 {
+		return
+	}
+	idx := c.partitionIndex(partID, make([]byte, 8))
+	for i := 0; i < len(c.sortedSet); i++ {
+		if !f(*c.ring[c.sortedSet[(idx+i)%len(c.sortedSet)]]) {
+			return
+		}
+	}
+}
+
+// Deadline returns the time Config.DistributionTimeout runs out, the zero time if there is no timeout.
+func (d DistributionContext) Deadline() time.Time {
+	return d.deadline
+}
+
+// BoundedLoadDistributor is the default Distributor: every partition walks the ring clockwise from its point to
+// the first member under its load bound, after the passes of Config.Stickiness, Config.MinResidency, affinities
+// and the other options which keep partitions in place. Custom distributors can wrap it, e.g. to adjust its
+// result.
+type BoundedLoadDistributor struct{}
+
+// Distribute runs the bounded-load distribution.
+func (BoundedLoadDistributor) Distribute(ctx DistributionContext) (map[int]string, error) {
+	c := ctx.c
+	partitions := make(map[int]*Member, c.partitionCount)
+	loads := make(map[string]float64)
+	if err := c.assign(partitions, loads, ctx.deadline, ctx.now); err != nil {
+		return nil, err
+	}
+	owners := make(map[int]string, len(partitions))
+	for partID, member := range partitions {
+		owners[partID] = (*member).String()
+	}
+	return owners, nil
+}
+
+// distributeWith fills the partition table with the result of the Distributor. It returns
+// ErrInvalidDistribution if the result is incomplete or names unknown members. It's not thread-safe.
+func (c *Consistent) distributeWith(d Distributor, partitions map[int]*Member, loads map[string]float64, deadline, now time.Time) error {
+	owners, err := d.Distribute(DistributionContext{c: c, deadline: deadline, now: now})
+	if err != nil {
+		return err
+	}
+	if len(owners) != int(c.partitionCount) {
+		return ErrInvalidDistribution
+	}
+	for partID, name := range owners {
+		member, ok := c.members[name]
+		if !ok || partID < 0 || partID >= int(c.partitionCount) {
+			return ErrInvalidDistribution
+		}
+		partitions[partID] = member
+		loads[name]++
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+type roundRobinDistributor struct{}
+
+func (roundRobinDistributor) Distribute(ctx DistributionContext) (map[int]string, error) {
+	members := ctx.Members()
+	owners := make(map[int]string, ctx.PartitionCount())
+	for partID := 0; partID < ctx.PartitionCount(); partID++ {
+		owners[partID] = members[partID%len(members)].String()
+	}
+	return owners, nil
+}
+
+type brokenDistributor struct{}
+
+func (brokenDistributor) Distribute(ctx DistributionContext) (map[int]string, error) {
+	return map[int]string{0: "unknown"}, nil
+}
+
+func testMembers(n int) []Member {
+	var members []Member
+	for i := 0; i < n; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	return members
+}
+
+func TestBoundedLoadDistributor(t *testing.T) {
+	cfg := newConfig()
+	c := New(testMembers(8), cfg)
+	cfg.Distributor = BoundedLoadDistributor{}
+	d := New(testMembers(8), cfg)
+	if !c.Equal(d) {
+		t.Fatalf("Expected the default layout, diff: %v", c.Diff(d))
+	}
+	c.Remove("node3.olric")
+	d.Remove("node3.olric")
+	if !c.Equal(d) {
+		t.Fatalf("Expected the default layout, diff: %v", c.Diff(d))
+	}
+}
+
+func TestCustomDistributor(t *testing.T) {
+	cfg := newConfig()
+	cfg.Distributor = roundRobinDistributor{}
+	cfg.BackupCount = 1
+	c := New(testMembers(4), cfg)
+
+	members := DistributionContext{c: c}.Members()
+	for partID := 0; partID < cfg.PartitionCount; partID++ {
+		owner := c.GetPartitionOwner(partID)
+		if owner.String() != members[partID%4].String() {
+			t.Fatalf("Expected %s to own partition %d, got: %s", members[partID%4], partID, owner)
+		}
+		if len(c.GetPartitionOwnerAndBackups(partID)) != 2 {
+			t.Fatalf("Expected the backups to be computed")
+		}
+	}
+	if load := c.LoadDistribution()[members[0].String()]; load != 6 {
+		t.Fatalf("Expected 6 partitions, got: %v", load)
+	}
+}
+
+func TestInvalidDistributor(t *testing.T) {
+	cfg := newConfig()
+	cfg.Distributor = brokenDistributor{}
+	cfg.PanicFree = true
+	c := New(testMembers(4), cfg)
+	if !c.Pending() {
+		t.Fatalf("Expected a pending distribution")
+	}
+	if err := c.Flush(); err != ErrInvalidDistribution {
+		t.Fatalf("Expected ErrInvalidDistribution, got: %v", err)
+	}
+}
+
+func TestDistributionContextWalk(t *testing.T) {
+	c := New(testMembers(4), newConfig())
+
+	ctx := DistributionContext{c: c}
+	var first Member
+	var count int
+	ctx.Walk(7, func(member Member) bool {
+		if first == nil {
+			first = member
+		}
+		count++
+		return true
+	})
+	if count != 4*newConfig().ReplicationFactor {
+		t.Fatalf("Expected a full circle, got: %d", count)
+	}
+	if want := *c.ring[c.sortedSet[c.partitionIndex(7, make([]byte, 8))]]; first.String() != want.String() {
+		t.Fatalf("Unexpected first member: %s", first)
+	}
+	if bound := ctx.LoadBound(first); bound != int(c.AverageLoad()) {
+		t.Fatalf("Expected %v, got: %d", c.AverageLoad(), bound)
+	}
+}
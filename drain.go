@@ -0,0 +1,125 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"sort"
+)
+
+// Drain moves every partition and backup away from the member while keeping it on the ring, so it can hand its
+// data over before it's removed. The remaining members share the partitions as if the member had left, and the
+// partitions are distributed again right away. Poll DrainStatus to learn when the member holds nothing anymore.
+// Removing the member ends draining, and so does Undrain.
+//
+// It returns ErrMemberNotFound if there is no member with that name. If the other members don't have room for
+// all partitions, the member isn't drained and Drain returns the *DistributionError.
+func (c *Consistent) Drain(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name = c.memberName(name)
+	if _, ok := c.members[name]; !ok {
+		return ErrMemberNotFound
+	}
+	if _, ok := c.draining[name]; ok {
+		return nil
+	}
+	c.draining[name] = struct{}{}
+	if err := c.redistributeNow(); err != nil {
+		delete(c.draining, name)
+		return err
+	}
+	return nil
+}
+
+// Undrain lets a draining member own partitions again and distributes them right away. It's a no-op if the
+// member isn't draining.
+func (c *Consistent) Undrain(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name = c.memberName(name)
+	if _, ok := c.draining[name]; !ok {
+		return nil
+	}
+	delete(c.draining, name)
+	if err := c.redistributeNow(); err != nil {
+		c.draining[name] = struct{}{}
+		return err
+	}
+	return nil
+}
+
+// Draining returns the names of the draining members in ascending order.
+func (c *Consistent) Draining() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.drainingNames()
+}
+
+// drainingNames returns the names of the draining members in ascending order. It's not thread-safe.
+func (c *Consistent) drainingNames() []string {
+	names := make([]string, 0, len(c.draining))
+	for name := range c.draining {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DrainStatus returns the IDs of the partitions the member still owns or backs up, in ascending order, and
+// whether there are none. A member which isn't on the ring holds nothing. It's meant for orchestration that waits
+// for a draining member to hand over its data before terminating it, e.g. a preStop hook.
+func (c *Consistent) DrainStatus(name string) (remainingPartitions []int, done bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	name = c.memberName(name)
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		if owner, ok := c.partitions[partID]; ok && (*owner).String() == name {
+			remainingPartitions = append(remainingPartitions, partID)
+			continue
+		}
+		if partID >= len(c.replicas) {
+			continue
+		}
+		for _, member := range c.replicas[partID] {
+			if member.String() == name {
+				remainingPartitions = append(remainingPartitions, partID)
+				break
+			}
+		}
+	}
+	return remainingPartitions, len(remainingPartitions) == 0
+}
+
+// drained reports whether the member is draining. It's not thread-safe.
+func (c *Consistent) drained(member Member) bool {
+	if len(c.draining) == 0 {
+		return false
+	}
+	_, ok := c.draining[member.String()]
+	return ok
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestDrain(t *testing.T) {
+	var members []Member
+	for i := 0; i < 4; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.BackupCount = 1
+	c := New(members, cfg)
+
+	if remaining, done := c.DrainStatus("node3.olric"); done || len(remaining) == 0 {
+		t.Fatalf("Expected node3.olric to hold partitions")
+	}
+	if err := c.Drain("node3.olric"); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if remaining, done := c.DrainStatus("node3.olric"); !done || len(remaining) != 0 {
+		t.Fatalf("Expected node3.olric to hold nothing, Got: %v", remaining)
+	}
+	if len(c.GetMembers()) != 4 {
+		t.Fatalf("Expected node3.olric to stay on the ring")
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	// The others share the partitions as if node3.olric had left.
+	expected := New(members[:3], cfg)
+	for partID := 0; partID < cfg.PartitionCount; partID++ {
+		if c.GetPartitionOwner(partID).String() != expected.GetPartitionOwner(partID).String() {
+			t.Fatalf("Partition %d: expected %s, Got: %s", partID, expected.GetPartitionOwner(partID), c.GetPartitionOwner(partID))
+		}
+	}
+
+	s, err := FromSnapshot(c.Snapshot())
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if draining := s.Draining(); len(draining) != 1 || draining[0] != "node3.olric" {
+		t.Fatalf("Unexpected draining members: %v", draining)
+	}
+
+	if err := c.Undrain("node3.olric"); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if !c.Equal(New(members, cfg)) {
+		t.Fatalf("Expected the layout before draining")
+	}
+
+	if err := c.Drain("node9.olric"); err != ErrMemberNotFound {
+		t.Fatalf("Expected ErrMemberNotFound, Got: %v", err)
+	}
+	if _, done := c.DrainStatus("node9.olric"); !done {
+		t.Fatalf("Expected a member which isn't on the ring to hold nothing")
+	}
+}
+
+func TestDrainNotEnoughRoom(t *testing.T) {
+	cfg := newConfig()
+	c := New([]Member{testMember("node0.olric")}, cfg)
+	err := c.Drain("node0.olric")
+	if !errors.Is(err, ErrNotEnoughRoom) {
+		t.Fatalf("Expected ErrNotEnoughRoom, Got: %v", err)
+	}
+	if len(c.Draining()) != 0 {
+		t.Fatalf("Expected no draining members")
+	}
+
+	c.Add(testMember("node1.olric"))
+	if err := c.Drain("node0.olric"); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	// Removing the member ends draining.
+	c.Remove("node0.olric")
+	if len(c.Draining()) != 0 {
+		t.Fatalf("Expected no draining members")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"reflect"
+)
+
+// DuplicatePolicy tells Add what to do with a member whose name is already on the ring, see
+// Config.OnDuplicateAdd.
+type DuplicatePolicy int
+
+const (
+	// IgnoreDuplicate keeps the existing member and ignores the new one. It's the default.
+	IgnoreDuplicate DuplicatePolicy = iota
+
+	// RejectDuplicate keeps the existing member and makes TryAdd return ErrMemberAlreadyExists.
+	RejectDuplicate
+
+	// ReplaceDuplicate replaces the existing member with the new one, e.g. to update its weight or metadata, and
+	// distributes the partitions again if the members differ. A warmup ramp of the member carries over.
+	ReplaceDuplicate
+)
+
+// TryAdd adds a new member to the consistent hash circle like Add. It returns ErrInvalidMemberName if the
+// name of the member is empty after Config.NormalizeName. If a member with the same normalized name exists, it
+// follows Config.OnDuplicateAdd and returns ErrMemberAlreadyExists if the policy is RejectDuplicate, and
+// ErrStaleGeneration if the member is an older incarnation of the existing one, see GenerationMember.
+func (c *Consistent) TryAdd(member Member) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.tryAdd(member, 0)
+}
+
+// tryAdd adds the member like TryAdd with the given number of virtual nodes, see AddWithReplicas. A
+// non-positive count keeps the count of a replaced member or takes Config.ReplicationFactor. It's not
+// thread-safe.
+func (c *Consistent) tryAdd(member Member, replicas int) error {
+	if !c.validName(member.String()) {
+		return ErrInvalidMemberName
+	}
+	if current, ok := c.members[c.memberName(member.String())]; ok {
+		return c.addDuplicate(*current, member, replicas)
+	}
+	c.addNew(member, replicas)
+	return nil
+}
+
+// addDuplicate applies Config.OnDuplicateAdd to a member whose name is taken by current. A newer generation of
+// the member always replaces it, see GenerationMember. It's not thread-safe.
+func (c *Consistent) addDuplicate(current, member Member, replicas int) error {
+	policy, err := c.duplicatePolicy(current, member)
+	if err != nil {
+		return err
+	}
+	if policy == ReplaceDuplicate {
+		n, custom := c.replicaCounts[current.String()]
+		if replicas > 0 {
+			n, custom = replicas, true
+		}
+		if reflect.DeepEqual(current, member) && (!custom || n == c.replicaCount(current.String())) {
+			return nil
+		}
+		w, warming := c.warming[current.String()]
+		c.remove(current.String())
+		if custom {
+			c.setReplicaCount(member.String(), n)
+		}
+		c.add(member)
+		if warming {
+			c.warming[member.String()] = w
+		}
+		c.membershipChanged()
+	}
+	return nil
+}
+
+// duplicatePolicy returns the policy which applies to a member whose name is taken by current, and the error
+// TryAdd returns for it. A newer generation of the member is always replaced, see GenerationMember.
+func (c *Consistent) duplicatePolicy(current, member Member) (DuplicatePolicy, error) {
+	switch compareGenerations(current, member) {
+	case -1:
+		return IgnoreDuplicate, ErrStaleGeneration
+	case 1:
+		return ReplaceDuplicate, nil
+	}
+	if c.config.OnDuplicateAdd == RejectDuplicate {
+		return RejectDuplicate, ErrMemberAlreadyExists
+	}
+	return c.config.OnDuplicateAdd, nil
+}
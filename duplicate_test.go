@@ -0,0 +1,92 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"testing"
+)
+
+func TestConsistentDuplicateIgnore(t *testing.T) {
+	c := New(nil, newConfig())
+	c.Add(weightedMember{name: "node1", weight: 1})
+	c.Add(weightedMember{name: "node2", weight: 1})
+	version := c.Version()
+	if err := c.TryAdd(weightedMember{name: "node1", weight: 3}); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if c.Version() != version {
+		t.Fatalf("Expected no distribution")
+	}
+	if w := c.GetMembers()[0].(weightedMember).weight; w != 1 {
+		t.Fatalf("Expected the first member to stay, got weight: %v", w)
+	}
+}
+
+func TestConsistentDuplicateReject(t *testing.T) {
+	cfg := newConfig()
+	cfg.OnDuplicateAdd = RejectDuplicate
+	c := New(nil, cfg)
+	c.Add(testMember("node1"))
+	if err := c.TryAdd(testMember("node1")); err != ErrMemberAlreadyExists {
+		t.Fatalf("Expected ErrMemberAlreadyExists, got: %v", err)
+	}
+	if err := c.TryAdd(testMember("node2")); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if len(c.GetMembers()) != 2 {
+		t.Fatalf("Expected 2 members, got: %d", len(c.GetMembers()))
+	}
+}
+
+func TestConsistentDuplicateReplace(t *testing.T) {
+	c := NewRing(WithPartitionCount(23), WithReplicationFactor(20), WithLoadFactor(1.25), WithHasher(hasher{}),
+		WithOnDuplicateAdd(ReplaceDuplicate))
+	c.Add(weightedMember{name: "node1", weight: 1})
+	c.Add(weightedMember{name: "node2", weight: 1})
+	c.Add(weightedMember{name: "node3", weight: 1})
+
+	version := c.Version()
+	c.Add(weightedMember{name: "node2", weight: 1})
+	if c.Version() != version {
+		t.Fatalf("Expected no distribution for an equal member")
+	}
+
+	before := c.LoadDistribution()["node2"]
+	c.Add(weightedMember{name: "node2", weight: 3})
+	if c.Version() == version {
+		t.Fatalf("Expected a distribution")
+	}
+	members := c.GetMembers()
+	if len(members) != 3 {
+		t.Fatalf("Expected 3 members, got: %d", len(members))
+	}
+	for _, m := range members {
+		if m.String() == "node2" && m.(weightedMember).weight != 3 {
+			t.Fatalf("Expected the member to be replaced")
+		}
+	}
+	if after := c.LoadDistribution()["node2"]; after <= before {
+		t.Fatalf("Expected node2 to own more partitions, got %v before and %v after", before, after)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// PartitionOwner is like GetPartitionOwner, but reports why there is no owner. It returns ErrInvalidPartitionID
+// if the partition doesn't exist and ErrEmptyRing if the ring has no members.
+func (c *Consistent) PartitionOwner(partID int) (Member, error) {
+	if partID < 0 || partID >= int(c.partitionCount) {
+		return nil, ErrInvalidPartitionID
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	owner := c.getPartitionOwner(partID)
+	if owner == nil {
+		return nil, ErrEmptyRing
+	}
+	return owner, nil
+}
+
+// ClampedAverageLoad is like AverageLoad, but never exceeds the partition count, which a large Config.Load can
+// make the average load do. ok is false if the ring has no members.
+func (c *Consistent) ClampedAverageLoad() (load float64, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if len(c.members) == 0 {
+		return 0, false
+	}
+	load = c.averageLoad()
+	if load > float64(c.partitionCount) {
+		load = float64(c.partitionCount)
+	}
+	return load, true
+}
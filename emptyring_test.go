@@ -0,0 +1,96 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"testing"
+)
+
+func TestEmptyRing(t *testing.T) {
+	fresh := New(nil, newConfig())
+	emptied := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, newConfig())
+	emptied.Remove("node0.olric")
+	emptied.Remove("node1.olric")
+
+	key := []byte("my-key")
+	for name, c := range map[string]*Consistent{"fresh": fresh, "emptied": emptied} {
+		if owner, err := c.PartitionOwner(1); owner != nil || err != ErrEmptyRing {
+			t.Fatalf("%s: Expected nil and ErrEmptyRing, Got: %v, %v", name, owner, err)
+		}
+		if _, err := c.PartitionOwner(23); err != ErrInvalidPartitionID {
+			t.Fatalf("%s: Expected ErrInvalidPartitionID, Got: %v", name, err)
+		}
+		if owner := c.GetPartitionOwner(1); owner != nil {
+			t.Fatalf("%s: Expected nil, Got: %v", name, owner)
+		}
+		if owner := c.LocateKey(key); owner != nil {
+			t.Fatalf("%s: Expected nil, Got: %v", name, owner)
+		}
+		if replicas := c.GetPartitionOwnerAndBackups(1); len(replicas) != 0 {
+			t.Fatalf("%s: Expected no replicas, Got: %v", name, replicas)
+		}
+		if loads := c.LoadDistribution(); loads == nil || len(loads) != 0 {
+			t.Fatalf("%s: Expected an empty map, Got: %v", name, loads)
+		}
+		if load := c.AverageLoad(); load != 0 {
+			t.Fatalf("%s: Expected zero, Got: %v", name, load)
+		}
+		if load, ok := c.ClampedAverageLoad(); load != 0 || ok {
+			t.Fatalf("%s: Expected zero and false, Got: %v, %v", name, load, ok)
+		}
+		if members := c.GetMembers(); len(members) != 0 {
+			t.Fatalf("%s: Expected no members, Got: %v", name, members)
+		}
+		if _, err := c.GetClosestN(key, 1); err != ErrInsufficientMemberCount {
+			t.Fatalf("%s: Expected ErrInsufficientMemberCount, Got: %v", name, err)
+		}
+		if members, err := c.GetClosestN(key, 0); err != nil || len(members) != 0 {
+			t.Fatalf("%s: Expected no members, Got: %v, %v", name, members, err)
+		}
+		if owner := c.LocateKeyAvoiding(key, func(Member) bool { return false }); owner != nil {
+			t.Fatalf("%s: Expected nil, Got: %v", name, owner)
+		}
+		if owner := c.LocateKeyNear(key, "zone0"); owner != nil {
+			t.Fatalf("%s: Expected nil, Got: %v", name, owner)
+		}
+		if err := c.Validate(); err != nil {
+			t.Fatalf("%s: Expected nil, Got: %v", name, err)
+		}
+		if owner := c.Freeze().LocateKey(key); owner != nil {
+			t.Fatalf("%s: Expected nil, Got: %v", name, owner)
+		}
+	}
+}
+
+func TestClampedAverageLoad(t *testing.T) {
+	cfg := newConfig()
+	cfg.Load = 10
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, cfg)
+	if c.AverageLoad() <= 23 {
+		t.Fatalf("Expected the average load to exceed the partition count, Got: %v", c.AverageLoad())
+	}
+	if load, ok := c.ClampedAverageLoad(); load != 23 || !ok {
+		t.Fatalf("Expected 23 and true, Got: %v, %v", load, ok)
+	}
+}
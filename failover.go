@@ -0,0 +1,80 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// prepareFailover records the first backup of every partition owned by the member, before the member is removed,
+// so that the next distribution hands the partition to the member which already holds a copy of its data.
+// It does nothing unless Config.FailoverToBackup is set. It's not thread-safe.
+func (c *Consistent) prepareFailover(name string) {
+	if !c.config.FailoverToBackup {
+		return
+	}
+	if c.failover == nil {
+		c.failover = make(map[int]Member)
+	}
+	for partID, member := range c.failover {
+		if member.String() == name {
+			// The preferred member is going away too, the partition takes the normal ring walk.
+			delete(c.failover, partID)
+		}
+	}
+	for partID, owner := range c.partitions {
+		if (*owner).String() != name {
+			continue
+		}
+		var backups []Member
+		if partID < len(c.replicas) {
+			backups = c.replicas[partID]
+		} else {
+			backups = c.successors(partID, 2)
+		}
+		if len(backups) > 1 {
+			c.failover[partID] = backups[1]
+		}
+	}
+}
+
+// distributeFailover assigns the partitions recorded by prepareFailover to their preferred members, as long as
+// the members are still on the ring and have room under the load bound. The remaining partitions are distributed
+// by the ring walk as usual. It's not thread-safe.
+func (c *Consistent) distributeFailover(partitions map[int]*Member, loads map[string]float64) {
+	if len(c.failover) == 0 {
+		return
+	}
+	// Follow the partition order, so the same partitions fail over if a backup runs out of room.
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		member, ok := c.failover[partID]
+		if !ok {
+			continue
+		}
+		m, ok := c.members[member.String()]
+		if !ok {
+			continue
+		}
+		if loads[member.String()]+1 <= c.loadBound(*m) {
+			partitions[partID] = m
+			loads[member.String()]++
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func failoverMoves(t *testing.T, cfg Config) (moved, toBackup int) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c := New(members, cfg)
+	backups := make(map[int]string)
+	for partID := 0; partID < cfg.PartitionCount; partID++ {
+		replicas := c.GetPartitionOwnerAndBackups(partID)
+		if replicas[0].String() == "node3.olric" {
+			backups[partID] = replicas[1].String()
+		}
+	}
+	c.Remove("node3.olric")
+	for partID, backup := range backups {
+		moved++
+		if c.GetPartitionOwner(partID).String() == backup {
+			toBackup++
+		}
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	return moved, toBackup
+}
+
+func TestFailoverToBackup(t *testing.T) {
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.BackupCount = 1
+	cfg.FailoverToBackup = true
+	moved, toBackup := failoverMoves(t, cfg)
+	if moved == 0 || toBackup != moved {
+		t.Fatalf("Expected all %d partitions on their backups, Got: %d", moved, toBackup)
+	}
+
+	// Without the option, the ring walk decides.
+	cfg.FailoverToBackup = false
+	moved, toBackup = failoverMoves(t, cfg)
+	if toBackup == moved {
+		t.Fatalf("Expected some partitions off their backups")
+	}
+}
+
+func TestFailoverToBackupWithoutBackupTable(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.FailoverToBackup = true
+	c := New(members, cfg)
+	expected := make(map[int]string)
+	for partID := 0; partID < cfg.PartitionCount; partID++ {
+		if c.GetPartitionOwner(partID).String() != "node3.olric" {
+			continue
+		}
+		closest, err := c.GetClosestNForPartition(partID, 2)
+		if err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+		expected[partID] = closest[1].String()
+	}
+	c.Remove("node3.olric")
+	for partID, backup := range expected {
+		if owner := c.GetPartitionOwner(partID).String(); owner != backup {
+			t.Fatalf("Expected %s for partition %d, Got: %s", backup, partID, owner)
+		}
+	}
+}
+
+func TestFailoverToBackupBound(t *testing.T) {
+	var members []Member
+	for i := 0; i < 4; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.BackupCount = 1
+	cfg.FailoverToBackup = true
+	c := New(members, cfg)
+	c.Remove("node0.olric")
+	c.Remove("node1.olric")
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	bound := c.AverageLoad()
+	for member, load := range c.LoadDistribution() {
+		if load > bound {
+			t.Fatalf("%s owns %g partitions, bound is %g", member, load, bound)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import "math"
+
+// MinimumFeasibleLoadFactor returns the smallest Config.Load which lets memberCount members of equal weight own
+// partitionCount partitions. Every member must be able to own the ceiling of partitionCount/memberCount
+// partitions, and the average load is rounded up, so a Load of 1 or more is feasible for any member count.
+// Weights, SetMaxLoad and draining members change the bound, see DistributionError.MinimumLoad for the current
+// members. It returns zero if there are no members or partitions.
+func MinimumFeasibleLoadFactor(partitionCount, memberCount int) float64 {
+	if memberCount <= 0 || partitionCount <= 0 {
+		return 0
+	}
+	required := (partitionCount + memberCount - 1) / memberCount
+	// The bound is ceil(partitionCount/memberCount*Load), it reaches required as soon as the product exceeds
+	// required-1. Step up from there in the same floating point arithmetic as averageLoad.
+	perMember := float64(partitionCount) / float64(memberCount)
+	load := float64(required-1) * float64(memberCount) / float64(partitionCount)
+	for math.Ceil(perMember*load) < float64(required) {
+		load = math.Nextafter(load, math.Inf(1))
+	}
+	return load
+}
+
+// feasibleLoad reports whether Config.Load, or Config.RelaxedLoadLimit if it's higher, lets the current members
+// own all the partitions, see minimumLoad. A Distributor and the tiny cluster fallback don't depend on the load
+// factor, and Config.ClassLoads gives members load factors of their own which a single minimum doesn't capture;
+// the distribution reports if they leave no room. It's not thread-safe.
+func (c *Consistent) feasibleLoad() bool {
+	if c.config.Distributor != nil || c.config.TinyClusterFallback && len(c.members) <= 2 ||
+		len(c.config.ClassLoads) != 0 {
+		return true
+	}
+	return math.Max(c.config.Load, c.config.RelaxedLoadLimit) >= c.minimumLoad()
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestMinimumFeasibleLoadFactor(t *testing.T) {
+	if got := MinimumFeasibleLoadFactor(23, 0); got != 0 {
+		t.Fatalf("Expected 0 without members, got: %v", got)
+	}
+	if got := MinimumFeasibleLoadFactor(23, 30); got <= 0 || got >= 1e-300 {
+		t.Fatalf("Expected a tiny positive factor with more members than partitions, got: %v", got)
+	}
+	for _, partitionCount := range []int{7, 23, 271} {
+		for memberCount := 1; memberCount <= 24; memberCount++ {
+			min := MinimumFeasibleLoadFactor(partitionCount, memberCount)
+			if min > 1 {
+				t.Fatalf("Expected a factor of at most 1 for %d/%d, got: %v", partitionCount, memberCount, min)
+			}
+			cfg := newConfigWith(partitionCount)
+			cfg.ReplicationFactor = 4
+			cfg.Load = min
+			c := New(testMembers(memberCount), cfg)
+			if err := c.Validate(); err != nil {
+				t.Fatalf("Expected a valid ring for %d/%d at %v, got: %v", partitionCount, memberCount, min, err)
+			}
+			if memberCount >= partitionCount {
+				// Any positive factor works, zero takes the default.
+				continue
+			}
+
+			cfg.Load = math.Nextafter(min, 0)
+			var err error
+			func() {
+				defer func() {
+					err, _ = recover().(error)
+				}()
+				New(testMembers(memberCount), cfg)
+			}()
+			var derr *DistributionError
+			if !errors.As(err, &derr) || derr.Assigned != 0 {
+				t.Fatalf("Expected the construction to be rejected for %d/%d at %v, got: %v",
+					partitionCount, memberCount, cfg.Load, err)
+			}
+		}
+	}
+}
+
+func TestMinimumFeasibleLoadFactorReported(t *testing.T) {
+	cfg := newConfig()
+	cfg.Load = 0.5
+	var err error
+	func() {
+		defer func() {
+			err, _ = recover().(error)
+		}()
+		New(testMembers(4), cfg)
+	}()
+	var derr *DistributionError
+	if !errors.As(err, &derr) {
+		t.Fatalf("Expected a *DistributionError, got: %v", err)
+	}
+	if want := MinimumFeasibleLoadFactor(cfg.PartitionCount, 4); derr.MinimumLoad != want {
+		t.Fatalf("Expected the minimum load %v, got: %v", want, derr.MinimumLoad)
+	}
+
+	// The reported minimum is below one and suffices.
+	cfg.Load = 0.9
+	if err := New(testMembers(4), cfg).Validate(); err != nil || derr.MinimumLoad >= 0.9 {
+		t.Fatalf("Expected Load 0.9 to work with the minimum %v, got: %v", derr.MinimumLoad, err)
+	}
+}
+
+func TestMinimumFeasibleLoadFactorPanicFree(t *testing.T) {
+	cfg := newConfig()
+	cfg.PanicFree = true
+	cfg.Load = MinimumFeasibleLoadFactor(cfg.PartitionCount, 4) / 2
+	c := New(testMembers(4), cfg)
+	if !c.Pending() {
+		t.Fatalf("Expected a pending distribution")
+	}
+}
+
+func TestMinimumFeasibleLoadFactorClassLoads(t *testing.T) {
+	cfg := newConfig()
+	cfg.PartitionCount = 10
+	cfg.Load = 0.8
+	cfg.ClassLoads = map[string]float64{"ssd": 1.5}
+	var members []Member
+	for i := 0; i < 3; i++ {
+		members = append(members, labeledMember{
+			name:   fmt.Sprintf("node%d.olric", i),
+			labels: map[string]string{DefaultClassLabel: "ssd"},
+		})
+	}
+	c := New(members, cfg)
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"sort"
+)
+
+// Fingerprint returns a 64-bit FNV-1a hash of the layout: the partition count, the member names, the owner of every
+// partition and the backup table. The layout only depends on the member set and the configuration, not on the
+// order in which members were added or removed. So two processes with the same members and configuration have
+// the same fingerprint, which is a cheap way to compare layouts across nodes and restarts.
+func (c *Consistent) Fingerprint() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.fingerprint()
+}
+
+// fingerprint computes the Fingerprint of the layout. It's not thread-safe.
+func (c *Consistent) fingerprint() uint64 {
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+	writeUint64 := func(v uint64) {
+		binary.LittleEndian.PutUint64(buf, v)
+		_, _ = h.Write(buf)
+	}
+	writeString := func(s string) {
+		writeUint64(uint64(len(s)))
+		_, _ = h.Write([]byte(s))
+	}
+
+	writeUint64(c.partitionCount)
+	names := make([]string, 0, len(c.members))
+	for name := range c.members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	writeUint64(uint64(len(names)))
+	for _, name := range names {
+		writeString(name)
+	}
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		owner := c.getPartitionOwner(partID)
+		if owner == nil {
+			writeString("")
+			continue
+		}
+		writeString(owner.String())
+	}
+	for _, replicas := range c.replicas {
+		writeUint64(uint64(len(replicas)))
+		for _, member := range replicas {
+			writeString(member.String())
+		}
+	}
+	return h.Sum64()
+}
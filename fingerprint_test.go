@@ -0,0 +1,98 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestConsistentFingerprintIsOrderIndependent(t *testing.T) {
+	var members []Member
+	for i := 0; i < 16; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+
+	for _, h := range []Hasher{hasher{}, narrowHasher{}} {
+		t.Run(fmt.Sprintf("%T", h), func(t *testing.T) {
+			cfg := newConfig()
+			cfg.Hasher = h
+			cfg.BackupCount = 2
+			expected := New(members, cfg)
+			fingerprint := expected.Fingerprint()
+
+			r := rand.New(rand.NewSource(42))
+			for i := 0; i < 20; i++ {
+				shuffled := make([]Member, len(members))
+				copy(shuffled, members)
+				r.Shuffle(len(shuffled), func(i, j int) {
+					shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+				})
+
+				// Build the same member set with one by one additions and a few removals.
+				c := New(nil, cfg)
+				for _, member := range shuffled {
+					c.Add(member)
+				}
+				for _, member := range shuffled[:4] {
+					c.Remove(member.String())
+				}
+				if !c.Equal(New(shuffled[4:], cfg)) {
+					t.Fatalf("Layout after removals differs from a fresh ring with the same members")
+				}
+				for _, member := range shuffled[:4] {
+					c.Add(member)
+				}
+
+				if err := c.Validate(); err != nil {
+					t.Fatalf("Expected nil, Got: %v", err)
+				}
+				if c.Fingerprint() != fingerprint {
+					t.Fatalf("Fingerprint depends on the insertion order")
+				}
+				if !c.Equal(expected) {
+					t.Fatalf("Layout depends on the insertion order")
+				}
+			}
+		})
+	}
+}
+
+func TestConsistentFingerprintChanges(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c := New(members, newConfig())
+	fingerprint := c.Fingerprint()
+	c.Add(testMember("node8.olric"))
+	if c.Fingerprint() == fingerprint {
+		t.Fatalf("Fingerprint should change with the membership")
+	}
+	c.Remove("node8.olric")
+	if c.Fingerprint() != fingerprint {
+		t.Fatalf("Fingerprint should be restored with the membership")
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"sort"
+)
+
+// FrozenRing is a read-only copy of the partition table of a ring, see Freeze. Lookups don't take locks, don't
+// use maps and don't allocate besides hashing, e.g. for embedded or edge deployments with a fixed member list.
+// It's safe for concurrent use and never changes.
+type FrozenRing struct {
+	hasher         Hasher
+	extractor      KeyExtractor
+	partitionCount uint64
+	version        uint64
+	owners         []Member
+	replicas       [][]Member
+	members        []Member
+}
+
+// Freeze returns a FrozenRing with the current partition table, owners and backups. Later changes of the ring
+// don't affect it. A pending deferred or background distribution is run first.
+func (c *Consistent) Freeze() *FrozenRing {
+	if c.config.DistributionDebounce > 0 || c.config.AsyncDistribution {
+		_ = c.Flush()
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	f := &FrozenRing{
+		hasher:         c.hasher,
+		extractor:      c.config.KeyExtractor,
+		partitionCount: c.partitionCount,
+		version:        c.version,
+		owners:         make([]Member, c.partitionCount),
+		replicas:       make([][]Member, c.partitionCount),
+	}
+	for partID := range f.owners {
+		f.owners[partID] = c.getPartitionOwner(partID)
+		if partID < len(c.replicas) {
+			f.replicas[partID] = append([]Member(nil), c.replicas[partID]...)
+		} else if f.owners[partID] != nil {
+			f.replicas[partID] = []Member{f.owners[partID]}
+		}
+	}
+	for _, member := range c.members {
+		f.members = append(f.members, *member)
+	}
+	sort.Slice(f.members, func(i, j int) bool {
+		return f.members[i].String() < f.members[j].String()
+	})
+	return f
+}
+
+// Version returns the version of the partition table the ring was frozen at.
+func (f *FrozenRing) Version() uint64 {
+	return f.version
+}
+
+// Members returns the members in ascending order of their names.
+func (f *FrozenRing) Members() []Member {
+	return append([]Member(nil), f.members...)
+}
+
+// FindPartitionID returns partition id for given key, like Consistent.FindPartitionID.
+func (f *FrozenRing) FindPartitionID(key []byte) int {
+	if f.extractor != nil {
+		key = f.extractor(key)
+	}
+	return int(f.hasher.Sum64(key) % f.partitionCount)
+}
+
+// GetPartitionOwner returns the owner of the given partition, or nil if it has none.
+func (f *FrozenRing) GetPartitionOwner(partID int) Member {
+	if partID < 0 || partID >= len(f.owners) {
+		return nil
+	}
+	return f.owners[partID]
+}
+
+// LocateKey finds a home for given key.
+func (f *FrozenRing) LocateKey(key []byte) Member {
+	return f.owners[f.FindPartitionID(key)]
+}
+
+// GetPartitionOwnerAndBackups returns the owner of the given partition followed by its backups. The result is
+// shared by all callers and must not be modified.
+func (f *FrozenRing) GetPartitionOwnerAndBackups(partID int) []Member {
+	if partID < 0 || partID >= len(f.replicas) {
+		return nil
+	}
+	return f.replicas[partID]
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	cfg := newConfigWith(271)
+	cfg.BackupCount = 1
+	c := New(testMembers(6), cfg)
+	f := c.Freeze()
+
+	if f.Version() != c.Version() || len(f.Members()) != 6 {
+		t.Fatalf("Unexpected frozen ring: version %d, %d members", f.Version(), len(f.Members()))
+	}
+	for i := 0; i < 1000; i++ {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		if f.FindPartitionID(key) != c.FindPartitionID(key) || f.LocateKey(key).String() != c.LocateKey(key).String() {
+			t.Fatalf("Expected the same owner of %s", key)
+		}
+	}
+	for partID := 0; partID < 271; partID++ {
+		expected := c.GetPartitionOwnerAndBackups(partID)
+		got := f.GetPartitionOwnerAndBackups(partID)
+		if len(got) != len(expected) || got[0].String() != expected[0].String() || got[1].String() != expected[1].String() {
+			t.Fatalf("Expected %v for partition %d, Got: %v", expected, partID, got)
+		}
+	}
+
+	owners := c.owners()
+	c.Add(testMember("node6.olric"))
+	c.Remove("node0.olric")
+	for partID := 0; partID < 271; partID++ {
+		if f.GetPartitionOwner(partID).String() != owners[partID].String() {
+			t.Fatalf("Expected the frozen ring not to change")
+		}
+	}
+	if f.GetPartitionOwner(271) != nil || f.GetPartitionOwnerAndBackups(-1) != nil {
+		t.Fatalf("Expected nil for an invalid partition")
+	}
+	if allocs := testing.AllocsPerRun(100, func() { f.GetPartitionOwnerAndBackups(42) }); allocs != 0 {
+		t.Fatalf("Expected no allocations, Got: %v", allocs)
+	}
+
+	if owner := New(nil, cfg).Freeze().LocateKey([]byte("key")); owner != nil {
+		t.Fatalf("Expected nil on an empty ring, Got: %v", owner)
+	}
+}
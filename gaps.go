@@ -0,0 +1,133 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"math"
+	"sort"
+)
+
+// hashSpace is the size of the space of 64-bit hashes.
+const hashSpace = float64(1 << 64)
+
+// SpanStats describes the spans of the partitions on the ring, see PartitionSpanStats. Spans are fractions of the
+// hash space.
+type SpanStats struct {
+	// Spans maps partition IDs to the distance from the point of the previous partition on the ring to the point
+	// of the partition.
+	Spans []float64
+
+	// Min is the smallest span.
+	Min float64
+
+	// Max is the largest span.
+	Max float64
+
+	// Mean is the average span, one divided by the partition count.
+	Mean float64
+
+	// StdDev is the standard deviation of the spans.
+	StdDev float64
+}
+
+// HistogramOfVnodeGaps returns the distribution of the gaps between consecutive virtual nodes on the ring, e.g.
+// to compare Hasher and ReplicationFactor choices. The buckets split the range from zero to four times the mean
+// gap evenly, the last one also counts the larger gaps. The gaps of a good hasher follow an exponential
+// distribution. It returns nil if buckets isn't positive or the ring is empty.
+func (c *Consistent) HistogramOfVnodeGaps(buckets int) []int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if buckets <= 0 || len(c.sortedSet) == 0 {
+		return nil
+	}
+	res := make([]int, buckets)
+	width := 4 * hashSpace / float64(len(c.sortedSet)) / float64(buckets)
+	for _, gap := range gaps(c.sortedSet) {
+		idx := int(gap / width)
+		if idx >= buckets {
+			idx = buckets - 1
+		}
+		res[idx]++
+	}
+	return res
+}
+
+// PartitionSpanStats returns the spans of the partitions between their points on the ring. A partition is owned
+// by the member of the first virtual node at or after its point, so uneven spans don't change the number of
+// keys of a partition, but show how evenly the Hasher spreads the partitions over the virtual nodes.
+func (c *Consistent) PartitionSpanStats() SpanStats {
+	points := make([]uint64, c.partitionCount)
+	bs := make([]byte, 8)
+	for partID := range points {
+		points[partID] = c.partitionPoint(partID, bs)
+	}
+	order := make([]int, len(points))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return points[order[i]] < points[order[j]]
+	})
+	sorted := make([]uint64, len(order))
+	for i, partID := range order {
+		sorted[i] = points[partID]
+	}
+
+	s := SpanStats{Spans: make([]float64, len(points))}
+	if len(points) == 0 {
+		return s
+	}
+	// gaps[i] is the distance from sorted[i] to the next point, so the span of sorted[i] is the previous gap.
+	g := gaps(sorted)
+	s.Min = math.Inf(1)
+	for i, partID := range order {
+		span := g[(i+len(g)-1)%len(g)] / hashSpace
+		s.Spans[partID] = span
+		s.Min = math.Min(s.Min, span)
+		s.Max = math.Max(s.Max, span)
+	}
+	s.Mean = 1 / float64(len(points))
+	var variance float64
+	for _, span := range s.Spans {
+		variance += (span - s.Mean) * (span - s.Mean)
+	}
+	s.StdDev = math.Sqrt(variance / float64(len(points)))
+	return s
+}
+
+// gaps returns the distances from every sorted hash to the next one, wrapping around at the end of the hash
+// space.
+func gaps(sorted []uint64) []float64 {
+	res := make([]float64, len(sorted))
+	if len(sorted) == 1 {
+		res[0] = hashSpace
+		return res
+	}
+	for i := range sorted {
+		// The subtraction wraps around for the last hash.
+		res[i] = float64(sorted[(i+1)%len(sorted)] - sorted[i])
+	}
+	return res
+}
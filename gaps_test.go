@@ -0,0 +1,72 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHistogramOfVnodeGaps(t *testing.T) {
+	c := New(testMembers(6), newConfigWith(271))
+	histogram := c.HistogramOfVnodeGaps(8)
+	if len(histogram) != 8 {
+		t.Fatalf("Expected 8 buckets, Got: %d", len(histogram))
+	}
+	var total int
+	for _, count := range histogram {
+		total += count
+	}
+	if total != 6*20 {
+		t.Fatalf("Expected %d gaps, Got: %d", 6*20, total)
+	}
+	// Most gaps are shorter than the mean.
+	if histogram[0]+histogram[1] < total/2 {
+		t.Fatalf("Expected most gaps below the mean, Got: %v", histogram)
+	}
+	if c.HistogramOfVnodeGaps(0) != nil || New(nil, newConfig()).HistogramOfVnodeGaps(8) != nil {
+		t.Fatalf("Expected nil")
+	}
+}
+
+func TestPartitionSpanStats(t *testing.T) {
+	c := New(testMembers(6), newConfigWith(271))
+	s := c.PartitionSpanStats()
+	if len(s.Spans) != 271 {
+		t.Fatalf("Expected 271 spans, Got: %d", len(s.Spans))
+	}
+	var total float64
+	for _, span := range s.Spans {
+		if span < s.Min || span > s.Max {
+			t.Fatalf("Expected %v within [%v, %v]", span, s.Min, s.Max)
+		}
+		total += span
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Fatalf("Expected the spans to cover the hash space, Got: %v", total)
+	}
+	if s.Mean != 1.0/271 || s.StdDev <= 0 || s.Min >= s.Mean || s.Max <= s.Mean {
+		t.Fatalf("Unexpected stats: min %v, max %v, mean %v, stddev %v", s.Min, s.Max, s.Mean, s.StdDev)
+	}
+}
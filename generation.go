@@ -0,0 +1,90 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// GenerationMember is implemented by members with an incarnation number, e.g. the start time of the process, so
+// a restarted member with the same name can be told apart from its previous incarnation. The placement depends
+// on the name only. Adding a member with a newer generation replaces the one on the ring regardless of
+// Config.OnDuplicateAdd; TryAdd returns ErrStaleGeneration for an older one. Lookups return the member with its
+// generation, and Fence rejects requests of stale incarnations.
+type GenerationMember interface {
+	Member
+	Generation() uint64
+}
+
+// memberGeneration returns the generation of the member, zero if it doesn't implement GenerationMember.
+func memberGeneration(member Member) (uint64, bool) {
+	if gm, ok := member.(GenerationMember); ok {
+		return gm.Generation(), true
+	}
+	return 0, false
+}
+
+// compareGenerations returns -1 if member is an older incarnation of current, 1 if it's a newer one and 0 if
+// they have the same generation or one of them has none.
+func compareGenerations(current, member Member) int {
+	a, ok := memberGeneration(current)
+	if !ok {
+		return 0
+	}
+	b, ok := memberGeneration(member)
+	switch {
+	case !ok || a == b:
+		return 0
+	case b < a:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// Generation returns the generation of the member with the given name on the ring. ok is false if there is no
+// such member or it doesn't implement GenerationMember.
+func (c *Consistent) Generation(name string) (generation uint64, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	member, found := c.members[c.memberName(name)]
+	if !found {
+		return 0, false
+	}
+	return memberGeneration(*member)
+}
+
+// Fence checks that the member is the incarnation on the ring, e.g. before accepting a write it sends. It returns
+// ErrMemberNotFound if there is no member with its name, and ErrStaleGeneration if the member on the ring has a
+// newer generation.
+func (c *Consistent) Fence(member Member) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	current, ok := c.members[c.memberName(member.String())]
+	if !ok {
+		return ErrMemberNotFound
+	}
+	if compareGenerations(*current, member) < 0 {
+		return ErrStaleGeneration
+	}
+	return nil
+}
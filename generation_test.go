@@ -0,0 +1,109 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"testing"
+)
+
+type generationMember struct {
+	name       string
+	generation uint64
+}
+
+func (g generationMember) String() string {
+	return g.name
+}
+
+func (g generationMember) Generation() uint64 {
+	return g.generation
+}
+
+func TestGenerationReplace(t *testing.T) {
+	c := New(nil, newConfig())
+	c.Add(generationMember{name: "node1", generation: 1})
+	c.Add(generationMember{name: "node2", generation: 1})
+	partitions := c.owners()
+
+	if err := c.TryAdd(generationMember{name: "node1", generation: 2}); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	generation, ok := c.Generation("node1")
+	if !ok || generation != 2 {
+		t.Fatalf("Expected generation 2, got: %d, %v", generation, ok)
+	}
+	for partID, owner := range c.owners() {
+		if owner.String() != partitions[partID].String() {
+			t.Fatalf("Expected partition %d to stay on %s, got: %s", partID, partitions[partID], owner)
+		}
+	}
+	info := c.LocateKeyWithInfo([]byte("key"))
+	if info.Generation != info.Member.(GenerationMember).Generation() {
+		t.Fatalf("Expected the generation of %s in lookup, got: %d", info.Member, info.Generation)
+	}
+}
+
+func TestGenerationStale(t *testing.T) {
+	c := New(nil, newConfig())
+	c.Add(generationMember{name: "node1", generation: 5})
+	version := c.Version()
+
+	if err := c.TryAdd(generationMember{name: "node1", generation: 4}); err != ErrStaleGeneration {
+		t.Fatalf("Expected ErrStaleGeneration, got: %v", err)
+	}
+	if c.Version() != version {
+		t.Fatalf("Expected no distribution")
+	}
+	if generation, _ := c.Generation("node1"); generation != 5 {
+		t.Fatalf("Expected generation 5, got: %d", generation)
+	}
+	if err := c.TryAdd(generationMember{name: "node1", generation: 5}); err != nil {
+		t.Fatalf("Expected nil for the same generation, got: %v", err)
+	}
+}
+
+func TestGenerationFence(t *testing.T) {
+	c := New(nil, newConfig())
+	c.Add(generationMember{name: "node1", generation: 2})
+	c.Add(testMember("node2"))
+
+	if err := c.Fence(generationMember{name: "node1", generation: 2}); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if err := c.Fence(generationMember{name: "node1", generation: 3}); err != nil {
+		t.Fatalf("Expected nil for a newer generation, got: %v", err)
+	}
+	if err := c.Fence(generationMember{name: "node1", generation: 1}); err != ErrStaleGeneration {
+		t.Fatalf("Expected ErrStaleGeneration, got: %v", err)
+	}
+	if err := c.Fence(testMember("node2")); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if err := c.Fence(testMember("node3")); err != ErrMemberNotFound {
+		t.Fatalf("Expected ErrMemberNotFound, got: %v", err)
+	}
+	if _, ok := c.Generation("node2"); ok {
+		t.Fatalf("Expected no generation for node2")
+	}
+}
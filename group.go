@@ -0,0 +1,172 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"sort"
+	"sync"
+)
+
+// RingGroup keeps several rings over the same membership in step, e.g. separate rings for reads and writes or one
+// ring per keyspace. A membership change applies to all rings or to none of them, and Read never sees the rings
+// in different states. Change the membership through the group only. Lookups made directly on the rings, outside
+// of Read, may see one ring before and another one after a change.
+type RingGroup struct {
+	mu         sync.RWMutex
+	rings      map[string]*Consistent
+	generation uint64
+}
+
+// NewRingGroup returns a group of the given rings, keyed by arbitrary names. It returns ErrMembershipMismatch if
+// the rings don't have the same members.
+func NewRingGroup(rings map[string]*Consistent) (*RingGroup, error) {
+	g := &RingGroup{rings: make(map[string]*Consistent, len(rings))}
+	var names map[string]struct{}
+	for key, c := range rings {
+		current := c.memberNames()
+		if names != nil && !sameNames(names, current) {
+			return nil, ErrMembershipMismatch
+		}
+		names = current
+		g.rings[key] = c
+	}
+	return g, nil
+}
+
+// sameNames reports whether the sets contain the same names.
+func sameNames(a, b map[string]struct{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Read calls f with the rings of the group, keyed by their names. Membership changes wait until f returns, so
+// every ring reflects the same change. f must not change the membership of the rings, and must not keep the map.
+func (g *RingGroup) Read(f func(rings map[string]*Consistent)) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	f(g.rings)
+}
+
+// Ring returns the ring with the given name, or nil if there is none. Use Read to look up several rings at once.
+func (g *RingGroup) Ring(name string) *Consistent {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.rings[name]
+}
+
+// Names returns the names of the rings in ascending order.
+func (g *RingGroup) Names() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	names := make([]string, 0, len(g.rings))
+	for name := range g.rings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Generation returns the number of membership changes applied by the group.
+func (g *RingGroup) Generation() uint64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.generation
+}
+
+// Add adds the member to every ring, see Consistent.Add.
+func (g *RingGroup) Add(member Member) error {
+	return g.apply(func(c *Consistent) {
+		c.Add(member)
+	})
+}
+
+// Remove removes the member from every ring, see Consistent.Remove.
+func (g *RingGroup) Remove(name string) error {
+	return g.apply(func(c *Consistent) {
+		c.Remove(name)
+	})
+}
+
+// SetMembers makes the given members the members of every ring, see Consistent.SetMembers.
+func (g *RingGroup) SetMembers(members []Member) error {
+	return g.apply(func(c *Consistent) {
+		c.SetMembers(members)
+	})
+}
+
+// apply tries the change on copies of the rings first. If the partitions of a ring can't be distributed after the
+// change, it returns the error and no ring changes. Otherwise the change is applied to every ring and the
+// pending distributions of rings with Config.DistributionDebounce or Config.AsyncDistribution run right away.
+// Only a distribution which times out, see Config.DistributionTimeout, can fail then; it stays pending and its
+// error is returned.
+func (g *RingGroup) apply(change func(c *Consistent)) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, c := range g.rings {
+		if err := c.tryChange(change); err != nil {
+			return err
+		}
+	}
+	var failed error
+	for _, c := range g.rings {
+		change(c)
+		if err := c.Flush(); err != nil && failed == nil {
+			failed = err
+		}
+	}
+	g.generation++
+	return failed
+}
+
+// tryChange applies the change to a copy of the ring and returns the error of its distribution, if any.
+func (c *Consistent) tryChange(change func(c *Consistent)) error {
+	c.mu.RLock()
+	next := c.clone()
+	// Remove promotes standby members and fails over to backups. The backup table is replaced, never modified.
+	next.standbys = append([]Member(nil), c.standbys...)
+	next.replicas = c.replicas
+	c.mu.RUnlock()
+
+	// Distribute synchronously and report failures instead of panicking.
+	next.config.DistributionDebounce = 0
+	next.config.AsyncDistribution = false
+	next.config.PanicFree = true
+	change(next)
+	if !next.pending {
+		return nil
+	}
+	return next.distribute()
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newGroup(t *testing.T, members []Member) (*RingGroup, *Consistent, *Consistent) {
+	reads := New(members, newConfig())
+	cfg := newConfig()
+	cfg.PartitionCount = 71
+	writes := New(members, cfg)
+	g, err := NewRingGroup(map[string]*Consistent{"reads": reads, "writes": writes})
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	return g, reads, writes
+}
+
+func TestRingGroup(t *testing.T) {
+	var members []Member
+	for i := 0; i < 3; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	g, reads, writes := newGroup(t, members)
+	if names := g.Names(); len(names) != 2 || names[0] != "reads" || g.Ring("writes") != writes {
+		t.Fatalf("Unexpected rings: %v", names)
+	}
+
+	// Readers never see the rings with different members.
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			g.Read(func(rings map[string]*Consistent) {
+				if len(rings["reads"].GetMembers()) != len(rings["writes"].GetMembers()) {
+					t.Errorf("Expected the same members")
+				}
+			})
+		}
+	}()
+	for i := 3; i < 8; i++ {
+		if err := g.Add(testMember(fmt.Sprintf("node%d.olric", i))); err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+	}
+	if err := g.Remove("node0.olric"); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	close(done)
+	wg.Wait()
+
+	if g.Generation() != 6 || len(reads.GetMembers()) != 7 || len(writes.GetMembers()) != 7 {
+		t.Fatalf("Expected 7 members on both rings after 6 changes")
+	}
+	if err := g.SetMembers(members); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if !reads.Equal(New(members, newConfig())) {
+		t.Fatalf("Expected the layout of the members")
+	}
+}
+
+func TestRingGroupAllOrNothing(t *testing.T) {
+	var members []Member
+	for i := 0; i < 3; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	g, reads, writes := newGroup(t, members)
+	// Without node2, the limits leave room for 50 of the 71 partitions of writes.
+	if err := writes.SetMaxLoad("node0.olric", 25); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if err := writes.SetMaxLoad("node1.olric", 25); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	version := reads.Version()
+	var distErr *DistributionError
+	if err := g.Remove("node2.olric"); !errors.As(err, &distErr) {
+		t.Fatalf("Expected a *DistributionError, Got: %v", err)
+	}
+	if reads.Version() != version || len(reads.GetMembers()) != 3 || len(writes.GetMembers()) != 3 {
+		t.Fatalf("Expected no ring to change")
+	}
+	if g.Generation() != 0 {
+		t.Fatalf("Expected no change")
+	}
+}
+
+func TestRingGroupMismatch(t *testing.T) {
+	a := New([]Member{testMember("node0.olric")}, newConfig())
+	b := New([]Member{testMember("node1.olric")}, newConfig())
+	if _, err := NewRingGroup(map[string]*Consistent{"a": a, "b": b}); err != ErrMembershipMismatch {
+		t.Fatalf("Expected ErrMembershipMismatch, Got: %v", err)
+	}
+}
+
+func TestRingGroupDebounce(t *testing.T) {
+	clock := newFakeClock()
+	cfg := newConfig()
+	cfg.Clock = clock
+	cfg.DistributionDebounce = time.Hour
+	c := New([]Member{testMember("node0.olric")}, cfg)
+	g, err := NewRingGroup(map[string]*Consistent{"c": c})
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if err := g.Add(testMember("node1.olric")); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if c.Pending() || len(c.LoadDistribution()) != 2 {
+		t.Fatalf("Expected the distribution to run right away")
+	}
+}
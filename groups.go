@@ -0,0 +1,99 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// PartitionGroups divides the partitions of a consistent hash ring into a fixed number of groups of consecutive
+// partition IDs, e.g. 271 partitions into 16 migration groups. Group sizes differ by at most one. Ownership queries
+// are answered from the current partition table of the ring.
+type PartitionGroups struct {
+	c     *Consistent
+	count int
+}
+
+// PartitionGroups returns a view which divides the partitions into count groups. count must be between one and
+// the partition count.
+func (c *Consistent) PartitionGroups(count int) (*PartitionGroups, error) {
+	if count <= 0 || count > int(c.partitionCount) {
+		return nil, ErrInvalidGroupCount
+	}
+	return &PartitionGroups{c: c, count: count}, nil
+}
+
+// Count returns the number of groups.
+func (g *PartitionGroups) Count() int {
+	return g.count
+}
+
+// GroupOf returns the group of the given partition. It returns -1 if there is no such partition.
+func (g *PartitionGroups) GroupOf(partID int) int {
+	partitionCount := int(g.c.partitionCount)
+	if partID < 0 || partID >= partitionCount {
+		return -1
+	}
+	return partID * g.count / partitionCount
+}
+
+// Partitions returns the IDs of the partitions in the given group in ascending order.
+func (g *PartitionGroups) Partitions(group int) []int {
+	if group < 0 || group >= g.count {
+		return nil
+	}
+	partitionCount := int(g.c.partitionCount)
+	// The first partition ID p with p*count >= group*partitionCount.
+	start := (group*partitionCount + g.count - 1) / g.count
+	end := ((group+1)*partitionCount + g.count - 1) / g.count
+	res := make([]int, 0, end-start)
+	for partID := start; partID < end; partID++ {
+		res = append(res, partID)
+	}
+	return res
+}
+
+// Owners returns the partitions of the given group, grouped by the names of their owners.
+func (g *PartitionGroups) Owners(group int) map[string][]int {
+	g.c.mu.RLock()
+	defer g.c.mu.RUnlock()
+
+	res := make(map[string][]int)
+	for _, partID := range g.Partitions(group) {
+		owner := g.c.getPartitionOwner(partID)
+		if owner == nil {
+			continue
+		}
+		res[owner.String()] = append(res[owner.String()], partID)
+	}
+	return res
+}
+
+// Diff is like Consistent.Diff, but only reports the partitions of the given group. It can be used to hand off
+// partitions one group at a time.
+func (g *PartitionGroups) Diff(group int, other *Consistent) []PartitionMove {
+	var res []PartitionMove
+	for _, move := range g.c.Diff(other) {
+		if g.GroupOf(move.PartitionID) == group {
+			res = append(res, move)
+		}
+	}
+	return res
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPartitionGroups(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	c := New(members, cfg)
+
+	if _, err := c.PartitionGroups(0); err != ErrInvalidGroupCount {
+		t.Fatalf("Expected ErrInvalidGroupCount, Got: %v", err)
+	}
+	if _, err := c.PartitionGroups(272); err != ErrInvalidGroupCount {
+		t.Fatalf("Expected ErrInvalidGroupCount, Got: %v", err)
+	}
+
+	g, err := c.PartitionGroups(16)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	seen := make(map[int]struct{})
+	for group := 0; group < g.Count(); group++ {
+		partitions := g.Partitions(group)
+		if len(partitions) != 16 && len(partitions) != 17 {
+			t.Fatalf("Unexpected size of group %d: %d", group, len(partitions))
+		}
+		var owned int
+		for _, ids := range g.Owners(group) {
+			owned += len(ids)
+		}
+		if owned != len(partitions) {
+			t.Fatalf("Group %d has %d partitions, %d of them are owned", group, len(partitions), owned)
+		}
+		for _, partID := range partitions {
+			if g.GroupOf(partID) != group {
+				t.Fatalf("Partition %d should be in group %d, Got: %d", partID, group, g.GroupOf(partID))
+			}
+			seen[partID] = struct{}{}
+		}
+	}
+	if len(seen) != cfg.PartitionCount {
+		t.Fatalf("Groups cover %d partitions, expected %d", len(seen), cfg.PartitionCount)
+	}
+
+	other := New(append(members, testMember("node8.olric")), cfg)
+	var moves int
+	for group := 0; group < g.Count(); group++ {
+		for _, move := range g.Diff(group, other) {
+			if g.GroupOf(move.PartitionID) != group {
+				t.Fatalf("Move of partition %d reported in group %d", move.PartitionID, group)
+			}
+			moves++
+		}
+	}
+	if moves != len(c.Diff(other)) {
+		t.Fatalf("Expected %d moves, Got: %d", len(c.Diff(other)), moves)
+	}
+}
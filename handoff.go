@@ -0,0 +1,115 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"sort"
+)
+
+// WriteOwner returns the member which takes the writes of the partition, its current owner. It returns nil if
+// the ring is empty.
+func (c *Consistent) WriteOwner(partID int) Member {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.getPartitionOwner(partID)
+}
+
+// ReadOwners returns the members which serve the reads of the partition. If Config.ReadWriteSplit is set and the
+// partition is handed off, these are the new owner and the previous one, which still holds the data. Otherwise
+// it's the owner alone. It returns nil if the ring is empty.
+func (c *Consistent) ReadOwners(partID int) []Member {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	owner := c.getPartitionOwner(partID)
+	if owner == nil {
+		return nil
+	}
+	if source, ok := c.handoffs[partID]; ok {
+		return []Member{owner, source}
+	}
+	return []Member{owner}
+}
+
+// CompleteHandoff ends the handoff of the partition once the new owner has the data, so the previous owner stops
+// serving reads. It's a no-op if the partition isn't handed off. It returns ErrInvalidPartitionID if there is no
+// such partition.
+func (c *Consistent) CompleteHandoff(partID int) error {
+	if partID < 0 || partID >= int(c.partitionCount) {
+		return ErrInvalidPartitionID
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.handoffs, partID)
+	return nil
+}
+
+// Handoffs returns the IDs of the partitions which are handed off, in ascending order.
+func (c *Consistent) Handoffs() []int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	partitions := make([]int, 0, len(c.handoffs))
+	for partID := range c.handoffs {
+		partitions = append(partitions, partID)
+	}
+	sort.Ints(partitions)
+	return partitions
+}
+
+// trackHandoffs starts a handoff for every partition which moved away from a member still on the ring. A
+// partition which moves again is still read from the member which had it first, and its handoff ends if it
+// moves back there. It's not thread-safe.
+func (c *Consistent) trackHandoffs(previous map[int]*Member) {
+	if !c.config.ReadWriteSplit || c.detached {
+		return
+	}
+	for partID, owner := range c.partitions {
+		name := (*owner).String()
+		if source, ok := c.handoffs[partID]; ok {
+			if source.String() == name {
+				delete(c.handoffs, partID)
+			}
+			continue
+		}
+		old, ok := previous[partID]
+		if !ok || (*old).String() == name {
+			continue
+		}
+		if m, ok := c.members[(*old).String()]; ok {
+			c.handoffs[partID] = *m
+		}
+	}
+}
+
+// dropHandoffs ends the handoffs whose previous owner left the ring. It's not thread-safe.
+func (c *Consistent) dropHandoffs(name string) {
+	for partID, source := range c.handoffs {
+		if source.String() == name {
+			delete(c.handoffs, partID)
+		}
+	}
+}
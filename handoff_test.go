@@ -0,0 +1,98 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestReadWriteSplit(t *testing.T) {
+	cfg := newConfig()
+	cfg.ReadWriteSplit = true
+	var members []Member
+	for i := 0; i < 4; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c := New(members, cfg)
+	if len(c.Handoffs()) != 0 {
+		t.Fatalf("The first distribution doesn't hand off partitions")
+	}
+	before := c.owners()
+
+	c.Add(testMember("node4.olric"))
+	handoffs := c.Handoffs()
+	if len(handoffs) == 0 {
+		t.Fatalf("Expected handoffs")
+	}
+	for partID := 0; partID < cfg.PartitionCount; partID++ {
+		write := c.WriteOwner(partID)
+		reads := c.ReadOwners(partID)
+		if write.String() != c.GetPartitionOwner(partID).String() || reads[0].String() != write.String() {
+			t.Fatalf("Partition %d: the write owner must be the owner and the first read owner", partID)
+		}
+		if write.String() == before[partID].String() {
+			if len(reads) != 1 {
+				t.Fatalf("Partition %d didn't move, Got: %v", partID, reads)
+			}
+			continue
+		}
+		if len(reads) != 2 || reads[1].String() != before[partID].String() {
+			t.Fatalf("Partition %d: expected the previous owner %s to serve reads, Got: %v", partID, before[partID], reads)
+		}
+	}
+
+	if err := c.CompleteHandoff(handoffs[0]); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if len(c.ReadOwners(handoffs[0])) != 1 || len(c.Handoffs()) != len(handoffs)-1 {
+		t.Fatalf("Expected the handoff of partition %d to end", handoffs[0])
+	}
+	if err := c.CompleteHandoff(cfg.PartitionCount); err != ErrInvalidPartitionID {
+		t.Fatalf("Expected ErrInvalidPartitionID, Got: %v", err)
+	}
+
+	// Moving back ends the handoffs, and so does removing the previous owner.
+	c.Remove("node4.olric")
+	if len(c.Handoffs()) != 0 {
+		t.Fatalf("Expected no handoffs, Got: %v", c.Handoffs())
+	}
+	c.Remove("node0.olric")
+	for _, partID := range c.Handoffs() {
+		if c.ReadOwners(partID)[1].String() == "node0.olric" {
+			t.Fatalf("A removed member doesn't serve reads")
+		}
+	}
+}
+
+func TestReadWriteSplitDisabled(t *testing.T) {
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, newConfig())
+	c.Add(testMember("node2.olric"))
+	if len(c.Handoffs()) != 0 {
+		t.Fatalf("Expected no handoffs")
+	}
+	if reads := c.ReadOwners(0); len(reads) != 1 || reads[0].String() != c.WriteOwner(0).String() {
+		t.Fatalf("Expected the owner alone, Got: %v", reads)
+	}
+}
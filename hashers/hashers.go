@@ -0,0 +1,228 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package hashers provides ready-made implementations of consistent.Hasher, so there is no need to copy-paste
+// a wrapper around a hash function from the examples.
+//
+// XXHash64 and Murmur3 are fast with good distribution, prefer them for production use. FNV1a has no setup cost
+// and is fine for short keys. All of them are implemented in pure Go without dependencies and produce the same
+// values as the reference implementations, so layouts are reproducible across processes and languages. MapHash
+// is only stable within a process, see its documentation.
+package hashers
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"hash/maphash"
+	"math/bits"
+
+	"github.com/buraksezer/consistent"
+)
+
+var (
+	_ consistent.Hasher = XXHash64{}
+	_ consistent.Hasher = FNV1a{}
+	_ consistent.Hasher = Murmur3{}
+	_ consistent.Hasher = MapHash{}
+)
+
+// FNV1a implements consistent.Hasher with 64-bit FNV-1a from the standard library.
+type FNV1a struct{}
+
+// Sum64 returns the 64-bit FNV-1a hash of data.
+func (FNV1a) Sum64(data []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(data)
+	return h.Sum64()
+}
+
+// MapHash implements consistent.Hasher with hash/maphash. The seed is fixed for the lifetime of the value, but
+// it is random and cannot be shared between processes. Use MapHash only for rings that live in a single process,
+// e.g. to shard work among goroutines.
+type MapHash struct {
+	seed maphash.Seed
+}
+
+// NewMapHash returns a MapHash with a new random seed.
+func NewMapHash() MapHash {
+	return MapHash{seed: maphash.MakeSeed()}
+}
+
+// Sum64 returns the maphash of data with the seed of m.
+func (m MapHash) Sum64(data []byte) uint64 {
+	var h maphash.Hash
+	h.SetSeed(m.seed)
+	_, _ = h.Write(data)
+	return h.Sum64()
+}
+
+const (
+	xxPrime1 uint64 = 11400714785074694791
+	xxPrime2 uint64 = 14029467366897019727
+	xxPrime3 uint64 = 1609587929392839161
+	xxPrime4 uint64 = 9650029242287828579
+	xxPrime5 uint64 = 2870177450012600261
+)
+
+// XXHash64 implements consistent.Hasher with xxHash64 (XXH64).
+type XXHash64 struct {
+	Seed uint64
+}
+
+func xxRound(acc, input uint64) uint64 {
+	acc += input * xxPrime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * xxPrime1
+}
+
+func xxMergeRound(acc, val uint64) uint64 {
+	acc ^= xxRound(0, val)
+	return acc*xxPrime1 + xxPrime4
+}
+
+// Sum64 returns the xxHash64 of data with the seed of x.
+func (x XXHash64) Sum64(data []byte) uint64 {
+	n := len(data)
+	var h uint64
+	if n >= 32 {
+		v1 := x.Seed + xxPrime1 + xxPrime2
+		v2 := x.Seed + xxPrime2
+		v3 := x.Seed
+		v4 := x.Seed - xxPrime1
+		for len(data) >= 32 {
+			v1 = xxRound(v1, binary.LittleEndian.Uint64(data[0:8]))
+			v2 = xxRound(v2, binary.LittleEndian.Uint64(data[8:16]))
+			v3 = xxRound(v3, binary.LittleEndian.Uint64(data[16:24]))
+			v4 = xxRound(v4, binary.LittleEndian.Uint64(data[24:32]))
+			data = data[32:]
+		}
+		h = bits.RotateLeft64(v1, 1) + bits.RotateLeft64(v2, 7) + bits.RotateLeft64(v3, 12) + bits.RotateLeft64(v4, 18)
+		h = xxMergeRound(h, v1)
+		h = xxMergeRound(h, v2)
+		h = xxMergeRound(h, v3)
+		h = xxMergeRound(h, v4)
+	} else {
+		h = x.Seed + xxPrime5
+	}
+	h += uint64(n)
+
+	for len(data) >= 8 {
+		h ^= xxRound(0, binary.LittleEndian.Uint64(data))
+		h = bits.RotateLeft64(h, 27)*xxPrime1 + xxPrime4
+		data = data[8:]
+	}
+	if len(data) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(data)) * xxPrime1
+		h = bits.RotateLeft64(h, 23)*xxPrime2 + xxPrime3
+		data = data[4:]
+	}
+	for _, b := range data {
+		h ^= uint64(b) * xxPrime5
+		h = bits.RotateLeft64(h, 11) * xxPrime1
+	}
+
+	h ^= h >> 33
+	h *= xxPrime2
+	h ^= h >> 29
+	h *= xxPrime3
+	h ^= h >> 32
+	return h
+}
+
+const (
+	murmurC1 uint64 = 0x87c37b91114253d5
+	murmurC2 uint64 = 0x4cf5ad432745937f
+)
+
+// Murmur3 implements consistent.Hasher with the first 64 bits of MurmurHash3 x64 128.
+type Murmur3 struct {
+	Seed uint32
+}
+
+func murmurFmix(k uint64) uint64 {
+	k ^= k >> 33
+	k *= 0xff51afd7ed558ccd
+	k ^= k >> 33
+	k *= 0xc4ceb9fe1a85ec53
+	k ^= k >> 33
+	return k
+}
+
+// Sum64 returns the first 64 bits of the MurmurHash3 x64 128 of data with the seed of m.
+func (m Murmur3) Sum64(data []byte) uint64 {
+	n := len(data)
+	h1, h2 := uint64(m.Seed), uint64(m.Seed)
+	for len(data) >= 16 {
+		k1 := binary.LittleEndian.Uint64(data[0:8])
+		k2 := binary.LittleEndian.Uint64(data[8:16])
+		data = data[16:]
+
+		k1 *= murmurC1
+		k1 = bits.RotateLeft64(k1, 31)
+		k1 *= murmurC2
+		h1 ^= k1
+		h1 = bits.RotateLeft64(h1, 27)
+		h1 += h2
+		h1 = h1*5 + 0x52dce729
+
+		k2 *= murmurC2
+		k2 = bits.RotateLeft64(k2, 33)
+		k2 *= murmurC1
+		h2 ^= k2
+		h2 = bits.RotateLeft64(h2, 31)
+		h2 += h1
+		h2 = h2*5 + 0x38495ab5
+	}
+
+	// The tail is shorter than 16 bytes. Bytes 8 and above go into k2, the rest into k1.
+	var k1, k2 uint64
+	for i := len(data) - 1; i >= 8; i-- {
+		k2 ^= uint64(data[i]) << (uint(i-8) * 8)
+	}
+	if len(data) > 8 {
+		k2 *= murmurC2
+		k2 = bits.RotateLeft64(k2, 33)
+		k2 *= murmurC1
+		h2 ^= k2
+	}
+	for i := len(data) - 1; i >= 0; i-- {
+		if i < 8 {
+			k1 ^= uint64(data[i]) << (uint(i) * 8)
+		}
+	}
+	if len(data) > 0 {
+		k1 *= murmurC1
+		k1 = bits.RotateLeft64(k1, 31)
+		k1 *= murmurC2
+		h1 ^= k1
+	}
+
+	h1 ^= uint64(n)
+	h2 ^= uint64(n)
+	h1 += h2
+	h2 += h1
+	h1 = murmurFmix(h1)
+	h2 = murmurFmix(h2)
+	h1 += h2
+	return h1
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package hashers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/buraksezer/consistent"
+)
+
+func TestXXHash64(t *testing.T) {
+	tests := []struct {
+		seed uint64
+		data string
+		want uint64
+	}{
+		{0, "", 0xef46db3751d8e999},
+		{0, "abc", 0x44bc2cf5ad770999},
+		{1, "abc", 0xbea9ca8199328908},
+	}
+	for _, tt := range tests {
+		if got := (XXHash64{Seed: tt.seed}).Sum64([]byte(tt.data)); got != tt.want {
+			t.Fatalf("XXHash64{%d}(%q): expected %x, got %x", tt.seed, tt.data, tt.want, got)
+		}
+	}
+}
+
+func TestMurmur3(t *testing.T) {
+	if got := (Murmur3{}).Sum64([]byte("hello")); got != 0xcbd8a7b341bd9b02 {
+		t.Fatalf("Murmur3(hello): expected cbd8a7b341bd9b02, got %x", got)
+	}
+	if got := (Murmur3{}).Sum64(nil); got != 0 {
+		t.Fatalf("Murmur3(\"\"): expected 0, got %x", got)
+	}
+}
+
+func TestFNV1a(t *testing.T) {
+	if got := (FNV1a{}).Sum64([]byte("a")); got != 0xaf63dc4c8601ec8c {
+		t.Fatalf("FNV1a(a): expected af63dc4c8601ec8c, got %x", got)
+	}
+}
+
+func TestMapHash(t *testing.T) {
+	h := NewMapHash()
+	if h.Sum64([]byte("foo")) != h.Sum64([]byte("foo")) {
+		t.Fatalf("MapHash is not stable for the same seed")
+	}
+}
+
+type member string
+
+func (m member) String() string {
+	return string(m)
+}
+
+func TestHashersWithRing(t *testing.T) {
+	for _, h := range []consistent.Hasher{XXHash64{}, FNV1a{}, Murmur3{}, NewMapHash()} {
+		members := []consistent.Member{}
+		for i := 0; i < 8; i++ {
+			members = append(members, member(fmt.Sprintf("node%d.olric", i)))
+		}
+		c := consistent.New(members, consistent.Config{
+			PartitionCount:    271,
+			ReplicationFactor: 20,
+			Load:              1.25,
+			Hasher:            h,
+		})
+		if err := c.Validate(); err != nil {
+			t.Fatalf("%T: %v", h, err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// recordSnapshot appends a snapshot of the current state to the history of Config.HistorySize, dropping the
+// oldest one if the history is full. It must be called right after the version is bumped. It's not thread-safe.
+func (c *Consistent) recordSnapshot() {
+	if c.config.HistorySize <= 0 || c.detached {
+		return
+	}
+	if len(c.snapshots) == c.config.HistorySize {
+		// Moving the slice forward keeps the retained snapshots contiguous and the memory bounded.
+		copy(c.snapshots, c.snapshots[1:])
+		c.snapshots[len(c.snapshots)-1] = Snapshot{}
+		c.snapshots = c.snapshots[:len(c.snapshots)-1]
+	}
+	c.snapshots = append(c.snapshots, c.snapshot())
+}
+
+// Versions returns the versions of the snapshots retained for Config.HistorySize in ascending order.
+func (c *Consistent) Versions() []uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	versions := make([]uint64, 0, len(c.snapshots))
+	for _, s := range c.snapshots {
+		versions = append(versions, s.Version)
+	}
+	return versions
+}
+
+// SnapshotAt returns a copy of the snapshot taken when the partition table reached the given version. It
+// returns ErrVersionUnavailable unless the version is retained, see Config.HistorySize.
+func (c *Consistent) SnapshotAt(version uint64) (Snapshot, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s, ok := c.snapshotAt(version)
+	if !ok {
+		return Snapshot{}, ErrVersionUnavailable
+	}
+	return copySnapshot(*s), nil
+}
+
+// OwnerAt returns the owner the partition had at the given version, nil if the ring had no members then. It
+// returns ErrInvalidPartitionID if there is no such partition and ErrVersionUnavailable unless the version is
+// retained, see Config.HistorySize.
+func (c *Consistent) OwnerAt(partID int, version uint64) (Member, error) {
+	if partID < 0 || partID >= int(c.partitionCount) {
+		return nil, ErrInvalidPartitionID
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s, ok := c.snapshotAt(version)
+	if !ok {
+		return nil, ErrVersionUnavailable
+	}
+	return snapshotOwner(s, partID), nil
+}
+
+// snapshotAt returns the retained snapshot of the version. It's not thread-safe.
+func (c *Consistent) snapshotAt(version uint64) (*Snapshot, bool) {
+	for i := range c.snapshots {
+		if c.snapshots[i].Version == version {
+			return &c.snapshots[i], true
+		}
+	}
+	return nil, false
+}
+
+// snapshotOwner returns the owner of the partition in the snapshot, nil if it has none.
+func snapshotOwner(s *Snapshot, partID int) Member {
+	if partID >= len(s.Partitions) {
+		return nil
+	}
+	name := s.Partitions[partID]
+	for _, member := range s.Members {
+		if member.String() == name {
+			return member
+		}
+	}
+	return nil
+}
+
+// deltaFromSnapshot computes the delta since the version from its retained snapshot, for versions which are no
+// longer in the history of Config.DeltaHistory. It's not thread-safe.
+func (c *Consistent) deltaFromSnapshot(version uint64) (LayoutDelta, bool) {
+	s, ok := c.snapshotAt(version)
+	if !ok {
+		return LayoutDelta{}, false
+	}
+	members := make(map[string]Member, len(s.Members))
+	for _, member := range s.Members {
+		members[member.String()] = member
+	}
+	d := LayoutDelta{From: version, To: c.version}
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		var from Member
+		if partID < len(s.Partitions) {
+			from = members[s.Partitions[partID]]
+		}
+		to := c.getPartitionOwner(partID)
+		if !sameMember(from, to) {
+			d.Moves = append(d.Moves, PartitionMove{PartitionID: partID, From: from, To: to})
+		}
+	}
+	return d, true
+}
+
+// copySnapshot returns a copy of the snapshot which shares no slices or maps with it. Nil stays nil.
+func copySnapshot(s Snapshot) Snapshot {
+	res := s
+	res.Members = append(s.Members[:0:0], s.Members...)
+	res.Partitions = append(s.Partitions[:0:0], s.Partitions...)
+	res.Standbys = append(s.Standbys[:0:0], s.Standbys...)
+	res.Draining = append(s.Draining[:0:0], s.Draining...)
+	if s.Backups != nil {
+		res.Backups = make([][]string, len(s.Backups))
+		for i, names := range s.Backups {
+			res.Backups[i] = append(names[:0:0], names...)
+		}
+	}
+	if s.VirtualNodes != nil {
+		res.VirtualNodes = make(map[string][]uint64, len(s.VirtualNodes))
+		for name, hashes := range s.VirtualNodes {
+			res.VirtualNodes[name] = append(hashes[:0:0], hashes...)
+		}
+	}
+	if s.Salts != nil {
+		res.Salts = make(map[uint64]int, len(s.Salts))
+		for h, salt := range s.Salts {
+			res.Salts[h] = salt
+		}
+	}
+	if s.MaxLoads != nil {
+		res.MaxLoads = make(map[string]int, len(s.MaxLoads))
+		for name, n := range s.MaxLoads {
+			res.MaxLoads[name] = n
+		}
+	}
+	return res
+}
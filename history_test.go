@@ -0,0 +1,113 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConsistentHistory(t *testing.T) {
+	cfg := newConfig()
+	cfg.HistorySize = 3
+	c := New(testMembers(4), cfg)
+	first := c.Version()
+
+	for i := 4; i < 8; i++ {
+		c.Add(testMembers(i + 1)[i])
+	}
+	versions := c.Versions()
+	if want := []uint64{first + 2, first + 3, first + 4}; !reflect.DeepEqual(versions, want) {
+		t.Fatalf("Expected %v, got: %v", want, versions)
+	}
+	if _, err := c.SnapshotAt(first); err != ErrVersionUnavailable {
+		t.Fatalf("Expected ErrVersionUnavailable, got: %v", err)
+	}
+
+	s, err := c.SnapshotAt(c.Version())
+	if err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if !reflect.DeepEqual(s, c.Snapshot()) {
+		t.Fatalf("Expected the current snapshot")
+	}
+	s.Partitions[0] = "changed"
+	if s, _ := c.SnapshotAt(c.Version()); s.Partitions[0] == "changed" {
+		t.Fatalf("Expected a copy of the snapshot")
+	}
+
+	c.Remove("node0.olric")
+	for partID := 0; partID < cfg.PartitionCount; partID++ {
+		previous, err := c.OwnerAt(partID, c.Version()-1)
+		if err != nil {
+			t.Fatalf("Expected nil, got: %v", err)
+		}
+		if previous == nil {
+			t.Fatalf("Expected an owner of partition %d", partID)
+		}
+	}
+	if _, err := c.OwnerAt(-1, c.Version()); err != ErrInvalidPartitionID {
+		t.Fatalf("Expected ErrInvalidPartitionID, got: %v", err)
+	}
+}
+
+func TestConsistentHistoryOwnerAt(t *testing.T) {
+	cfg := newConfig()
+	cfg.HistorySize = 2
+	c := New(testMembers(4), cfg)
+	version := c.Version()
+	owners := c.owners()
+	c.Remove("node1.olric")
+	for partID, owner := range owners {
+		got, err := c.OwnerAt(partID, version)
+		if err != nil {
+			t.Fatalf("Expected nil, got: %v", err)
+		}
+		if got.String() != owner.String() {
+			t.Fatalf("Expected %s to own partition %d at version %d, got: %s", owner, partID, version, got)
+		}
+	}
+}
+
+func TestConsistentHistoryDelta(t *testing.T) {
+	cfg := newConfig()
+	cfg.HistorySize = 4
+	c := New(testMembers(4), cfg)
+	table := c.RoutingTable()
+	c.Add(testMember("node4.olric"))
+	c.Add(testMember("node5.olric"))
+
+	// DeltaHistory is disabled, the delta comes from the snapshot.
+	d, err := c.DeltaSince(table.Version)
+	if err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	table, err = table.Apply(d.RoutingDelta())
+	if err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if !reflect.DeepEqual(table, c.RoutingTable()) {
+		t.Fatalf("Expected the current routing table")
+	}
+}
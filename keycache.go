@@ -0,0 +1,124 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"container/list"
+	"sync"
+)
+
+// keyCache is a bounded LRU cache of the results of GetClosestN, by partition and count. Entries are valid for a
+// single version of the partition table; the cache is dropped as a whole when it sees a new version.
+type keyCache struct {
+	mu      sync.Mutex
+	size    int
+	version uint64
+	order   *list.List
+	items   map[closestKey]*list.Element
+}
+
+type closestKey struct {
+	partID int
+	count  int
+}
+
+type keyCacheEntry struct {
+	key     closestKey
+	members []Member
+}
+
+func newKeyCache(size int) *keyCache {
+	return &keyCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[closestKey]*list.Element, size),
+	}
+}
+
+// reset drops every entry if version is not the version of the cached entries. It's not thread-safe.
+func (k *keyCache) reset(version uint64) {
+	if k.version == version {
+		return
+	}
+	k.version = version
+	k.order.Init()
+	k.items = make(map[closestKey]*list.Element, k.size)
+}
+
+// get returns a copy of the cached members for the given version of the partition table.
+func (k *keyCache) get(key closestKey, version uint64) ([]Member, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.reset(version)
+	elem, ok := k.items[key]
+	if !ok {
+		return nil, false
+	}
+	k.order.MoveToFront(elem)
+	return append([]Member(nil), elem.Value.(*keyCacheEntry).members...), true
+}
+
+// put stores the members for the given version of the partition table and evicts the least recently used entry
+// if the cache is full. The caller must not modify members afterwards.
+func (k *keyCache) put(key closestKey, version uint64, members []Member) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.reset(version)
+	if elem, ok := k.items[key]; ok {
+		elem.Value.(*keyCacheEntry).members = members
+		k.order.MoveToFront(elem)
+		return
+	}
+	if k.order.Len() >= k.size {
+		oldest := k.order.Back()
+		k.order.Remove(oldest)
+		delete(k.items, oldest.Value.(*keyCacheEntry).key)
+	}
+	k.items[key] = k.order.PushFront(&keyCacheEntry{key: key, members: members})
+}
+
+// len returns the number of cached entries.
+func (k *keyCache) len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return k.order.Len()
+}
+
+// closestCached is successors backed by the key cache. While a distribution is pending, the ring is ahead of the
+// partition table and the version doesn't tell the difference, so the cache is bypassed. It's not thread-safe.
+func (c *Consistent) closestCached(partID, count int) []Member {
+	if c.pending {
+		return c.successors(partID, count)
+	}
+	key := closestKey{partID: partID, count: count}
+	if members, ok := c.keys.get(key, c.version); ok {
+		return members
+	}
+	members := c.successors(partID, count)
+	c.keys.put(key, c.version, append([]Member(nil), members...))
+	return members
+}
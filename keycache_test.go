@@ -0,0 +1,178 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeyCacheClosestN(t *testing.T) {
+	cfg := newConfig()
+	cfg.KeyCacheSize = 16
+	cached := New(nil, cfg)
+	plain := New(nil, newConfig())
+	for i := 0; i < 8; i++ {
+		cached.Add(testMember(fmt.Sprintf("node%d.olric", i)))
+		plain.Add(testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+
+	check := func() {
+		for i := 0; i < 100; i++ {
+			key := []byte(fmt.Sprintf("key-%d", i%32))
+			got, err := cached.GetClosestN(key, 3)
+			if err != nil {
+				t.Fatalf("Expected nil, Got: %v", err)
+			}
+			want, _ := plain.GetClosestN(key, 3)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("Cached closest members of %s are different: %v, %v", key, got, want)
+			}
+			// Callers may modify the result.
+			got[0] = testMember("modified")
+		}
+	}
+	check()
+	if cached.keys.len() != 16 {
+		t.Fatalf("Expected 16 cached entries, Got: %d", cached.keys.len())
+	}
+
+	// The cache must not return members from the previous partition table.
+	cached.Remove("node3.olric")
+	plain.Remove("node3.olric")
+	check()
+	cached.Add(testMember("node8.olric"))
+	plain.Add(testMember("node8.olric"))
+	check()
+}
+
+func TestKeyCachePending(t *testing.T) {
+	cfg := newConfig()
+	cfg.KeyCacheSize = 16
+	cfg.DistributionDebounce = time.Hour
+	c := New(testMembers(4), cfg)
+	key := []byte("my-key")
+	if _, err := c.GetClosestN(key, 4); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	// The virtual nodes of the new member are on the ring before the distribution runs.
+	c.Add(testMember("node4.olric"))
+	got, err := c.GetClosestN(key, 5)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	c.mu.RLock()
+	want := c.successors(c.FindPartitionID(key), 5)
+	c.mu.RUnlock()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected the members of the current ring: %v, %v", got, want)
+	}
+	if c.keys.len() != 1 {
+		t.Fatalf("Expected no entries while the distribution is pending, Got: %d", c.keys.len())
+	}
+}
+
+func TestKeyCacheEviction(t *testing.T) {
+	k := newKeyCache(2)
+	k.put(closestKey{partID: 1, count: 1}, 0, []Member{testMember("a")})
+	k.put(closestKey{partID: 2, count: 1}, 0, []Member{testMember("b")})
+	if _, ok := k.get(closestKey{partID: 1, count: 1}, 0); !ok {
+		t.Fatalf("Expected a cached entry for 1")
+	}
+	// 2 is the least recently used entry now.
+	k.put(closestKey{partID: 3, count: 1}, 0, []Member{testMember("c")})
+	if _, ok := k.get(closestKey{partID: 2, count: 1}, 0); ok {
+		t.Fatalf("Expected 2 to be evicted")
+	}
+	if _, ok := k.get(closestKey{partID: 1, count: 2}, 0); ok {
+		t.Fatalf("Expected no entry for another count")
+	}
+	if _, ok := k.get(closestKey{partID: 1, count: 1}, 0); !ok {
+		t.Fatalf("Expected a cached entry for 1")
+	}
+	if _, ok := k.get(closestKey{partID: 1, count: 1}, 1); ok {
+		t.Fatalf("Expected the cache to be dropped on a new version")
+	}
+	if k.len() != 0 {
+		t.Fatalf("Expected an empty cache, Got: %d", k.len())
+	}
+}
+
+func TestKeyCacheEmptyRing(t *testing.T) {
+	cfg := newConfig()
+	cfg.KeyCacheSize = 16
+	c := New(nil, cfg)
+	if _, err := c.GetClosestN([]byte("Olric"), 1); err != ErrInsufficientMemberCount {
+		t.Fatalf("Expected ErrInsufficientMemberCount, Got: %v", err)
+	}
+	c.Add(testMember("node1.olric"))
+	if members, err := c.GetClosestN([]byte("Olric"), 1); err != nil || len(members) != 1 {
+		t.Fatalf("Expected the only member, Got: %v, %v", members, err)
+	}
+}
+
+func TestKeyCacheConcurrent(t *testing.T) {
+	cfg := newConfig()
+	cfg.KeyCacheSize = 8
+	c := New([]Member{testMember("node1.olric"), testMember("node2.olric")}, cfg)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				_, _ = c.GetClosestN([]byte(fmt.Sprintf("key-%d", j%16)), 2)
+			}
+		}(i)
+	}
+	c.Add(testMember("node3.olric"))
+	wg.Wait()
+}
+
+func benchmarkClosestN(b *testing.B, cacheSize int) {
+	cfg := newConfigWith(271)
+	cfg.KeyCacheSize = cacheSize
+	c := New(testMembers(50), cfg)
+	keys := make([][]byte, 256)
+	for i := range keys {
+		keys[i] = []byte(fmt.Sprintf("key%d", i))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = c.GetClosestN(keys[i%len(keys)], 5)
+	}
+}
+
+func BenchmarkGetClosestNHotKeys(b *testing.B) {
+	benchmarkClosestN(b, 0)
+}
+
+func BenchmarkGetClosestNHotKeysCached(b *testing.B) {
+	benchmarkClosestN(b, 1024)
+}
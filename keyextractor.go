@@ -0,0 +1,58 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import "bytes"
+
+// KeyExtractor returns the part of a key which is hashed to find its partition, see Config.KeyExtractor. The
+// result may share the memory of the key.
+type KeyExtractor func(key []byte) []byte
+
+// PrefixExtractor returns a KeyExtractor for composite keys like "tenant/table/row" which keeps the first segments
+// segments, so "tenant/table" with sep '/' and two segments, and all rows of a table land on the same partition.
+// Keys with fewer segments are hashed whole. It panics if segments isn't positive.
+func PrefixExtractor(sep byte, segments int) KeyExtractor {
+	if segments <= 0 {
+		panic("consistent: PrefixExtractor needs a positive segment count")
+	}
+	return func(key []byte) []byte {
+		end := 0
+		for i := 0; i < segments; i++ {
+			idx := bytes.IndexByte(key[end:], sep)
+			if idx < 0 {
+				return key
+			}
+			end += idx + 1
+		}
+		return key[:end-1]
+	}
+}
+
+// routingKey returns the bytes of the key which are hashed.
+func (c *Consistent) routingKey(key []byte) []byte {
+	if c.config.KeyExtractor == nil {
+		return key
+	}
+	return c.config.KeyExtractor(key)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import "testing"
+
+func TestPrefixExtractor(t *testing.T) {
+	extract := PrefixExtractor('/', 2)
+	tests := map[string]string{
+		"tenant/table/row":   "tenant/table",
+		"tenant/table/row/x": "tenant/table",
+		"tenant/table":       "tenant/table",
+		"tenant/table/":      "tenant/table",
+		"tenant":             "tenant",
+		"":                   "",
+	}
+	for key, expected := range tests {
+		if got := string(extract([]byte(key))); got != expected {
+			t.Fatalf("Expected %q for %q, Got: %q", expected, key, got)
+		}
+	}
+}
+
+func TestKeyExtractor(t *testing.T) {
+	cfg := newConfigWith(271)
+	cfg.KeyExtractor = PrefixExtractor('/', 2)
+	c := New(testMembers(6), cfg)
+
+	partID := c.FindPartitionID([]byte("tenant/orders"))
+	owner := c.LocateKey([]byte("tenant/orders"))
+	for _, row := range []string{"1", "2", "3", "4/history"} {
+		key := []byte("tenant/orders/" + row)
+		if got := c.FindPartitionID(key); got != partID {
+			t.Fatalf("Expected partition %d for %s, Got: %d", partID, key, got)
+		}
+		if got := c.LocateKey(key); got.String() != owner.String() {
+			t.Fatalf("Expected %s for %s, Got: %s", owner, key, got)
+		}
+	}
+	closest, err := c.GetClosestN([]byte("tenant/orders/1"), 2)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	expected, _ := c.GetClosestN([]byte("tenant/orders"), 2)
+	for i := range closest {
+		if closest[i].String() != expected[i].String() {
+			t.Fatalf("Expected %v, Got: %v", expected, closest)
+		}
+	}
+}
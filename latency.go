@@ -0,0 +1,86 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"math"
+)
+
+// SetLatency registers the latency score of the member for LocateKeyFastest, e.g. a moving average of round trip
+// times as seen by this node. Lower is better, the unit is up to the caller. A negative or NaN score removes it.
+// Like limits of SetMaxLoad, scores may be set before the member joins and stay in place until they're removed,
+// even if the member leaves the ring. They don't affect the distribution of the partitions and aren't part of
+// snapshots.
+func (c *Consistent) SetLatency(name string, score float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name = c.memberName(name)
+	if score < 0 || math.IsNaN(score) {
+		delete(c.latencies, name)
+		return
+	}
+	c.latencies[name] = score
+}
+
+// Latency returns the latency score of the member set by SetLatency, and whether there is one.
+func (c *Consistent) Latency(name string) (float64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	name = c.memberName(name)
+	score, ok := c.latencies[name]
+	return score, ok
+}
+
+// LocateKeyFastest returns the healthy member with the lowest latency score among the owner of the key and its
+// backups from Config.BackupCount, e.g. for routing reads in geo-distributed caches. Members without a score rank
+// after the others, ties keep the order of GetPartitionOwnerAndBackups. A nil healthy treats every member as
+// healthy, otherwise it's called without holding the lock. It returns nil if none of them is healthy or the
+// ring is empty.
+func (c *Consistent) LocateKeyFastest(key []byte, healthy func(Member) bool) Member {
+	replicas := c.GetPartitionOwnerAndBackups(c.FindPartitionID(key))
+	scores := make([]float64, len(replicas))
+	c.mu.RLock()
+	for i, member := range replicas {
+		score, ok := c.latencies[member.String()]
+		if !ok {
+			score = math.Inf(1)
+		}
+		scores[i] = score
+	}
+	c.mu.RUnlock()
+
+	var best Member
+	bestScore := math.Inf(1)
+	for i, member := range replicas {
+		if healthy != nil && !healthy(member) {
+			continue
+		}
+		if best == nil || scores[i] < bestScore {
+			best, bestScore = member, scores[i]
+		}
+	}
+	return best
+}
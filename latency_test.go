@@ -0,0 +1,84 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestLocateKeyFastest(t *testing.T) {
+	cfg := newConfig()
+	cfg.BackupCount = 2
+	var members []Member
+	for i := 0; i < 5; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c := New(members, cfg)
+	key := []byte("Olric")
+	replicas := c.GetPartitionOwnerAndBackups(c.FindPartitionID(key))
+	if len(replicas) != 3 {
+		t.Fatalf("Expected 3 replicas, Got: %v", replicas)
+	}
+
+	// Without scores, the owner wins.
+	if m := c.LocateKeyFastest(key, nil); m.String() != replicas[0].String() {
+		t.Fatalf("Expected the owner %s, Got: %s", replicas[0], m)
+	}
+
+	c.SetLatency(replicas[0].String(), 30)
+	c.SetLatency(replicas[1].String(), 20)
+	c.SetLatency(replicas[2].String(), 10)
+	// Members which aren't replicas don't count, however fast they are.
+	for _, member := range members {
+		if !containsMember(replicas, member) {
+			c.SetLatency(member.String(), 1)
+		}
+	}
+	if m := c.LocateKeyFastest(key, nil); m.String() != replicas[2].String() {
+		t.Fatalf("Expected the fastest backup %s, Got: %s", replicas[2], m)
+	}
+	unhealthy := replicas[2].String()
+	healthy := func(m Member) bool { return m.String() != unhealthy }
+	if m := c.LocateKeyFastest(key, healthy); m.String() != replicas[1].String() {
+		t.Fatalf("Expected %s, Got: %s", replicas[1], m)
+	}
+
+	// A removed score ranks after the others.
+	c.SetLatency(replicas[1].String(), math.NaN())
+	if _, ok := c.Latency(replicas[1].String()); ok {
+		t.Fatalf("Expected no score")
+	}
+	if m := c.LocateKeyFastest(key, healthy); m.String() != replicas[0].String() {
+		t.Fatalf("Expected %s, Got: %s", replicas[0], m)
+	}
+
+	if m := c.LocateKeyFastest(key, func(Member) bool { return false }); m != nil {
+		t.Fatalf("Expected nil, Got: %s", m)
+	}
+	if m := New(nil, cfg).LocateKeyFastest(key, nil); m != nil {
+		t.Fatalf("Expected nil, Got: %s", m)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"sort"
+	"time"
+)
+
+// RemoveAfter marks the member as leaving and removes it after the grace period, unless CancelRemove takes the
+// mark back first, e.g. because the member was only disconnected for a moment. A leaving member keeps the
+// partitions it owns but gets no new ones, and it isn't picked as a backup anymore. ConfirmRemove removes it before
+// the grace period is over. Calling RemoveAfter again restarts the grace period. A non-positive grace removes
+// the member right away, like Remove. It returns ErrMemberNotFound if there is no member with that name.
+func (c *Consistent) RemoveAfter(name string, grace time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name = c.memberName(name)
+	if _, ok := c.members[name]; !ok {
+		return ErrMemberNotFound
+	}
+	if grace <= 0 {
+		c.removeMember(name)
+		return nil
+	}
+	if timer, ok := c.leaveTimers[name]; ok {
+		timer.Stop()
+	} else {
+		c.leaving[name] = c.loads[name]
+	}
+	var timer Timer
+	timer = c.clock().AfterFunc(grace, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if current, ok := c.leaveTimers[name]; ok && current == timer {
+			c.removeMember(name)
+		}
+	})
+	c.leaveTimers[name] = timer
+	return nil
+}
+
+// ConfirmRemove removes a leaving member right away. It returns ErrMemberNotFound if there is no leaving member
+// with that name.
+func (c *Consistent) ConfirmRemove(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name = c.memberName(name)
+	if _, ok := c.leaving[name]; !ok {
+		return ErrMemberNotFound
+	}
+	c.removeMember(name)
+	return nil
+}
+
+// CancelRemove takes back the leaving mark of RemoveAfter. The member may get new partitions again in the next
+// distribution. It returns ErrMemberNotFound if there is no leaving member with that name.
+func (c *Consistent) CancelRemove(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name = c.memberName(name)
+	if _, ok := c.leaving[name]; !ok {
+		return ErrMemberNotFound
+	}
+	c.dropLeaving(name)
+	return nil
+}
+
+// Leaving returns the names of the leaving members in ascending order.
+func (c *Consistent) Leaving() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := make([]string, 0, len(c.leaving))
+	for name := range c.leaving {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// removeMember removes the member like Remove. The caller must hold the lock.
+func (c *Consistent) removeMember(name string) {
+	c.prepareFailover(name)
+	c.remove(name)
+	c.promoteStandby()
+	c.membershipChanged()
+}
+
+// dropLeaving forgets the leaving mark of the member and stops its timer. It's not thread-safe.
+func (c *Consistent) dropLeaving(name string) {
+	if timer, ok := c.leaveTimers[name]; ok {
+		timer.Stop()
+		delete(c.leaveTimers, name)
+	}
+	delete(c.leaving, name)
+}
+
+// leavingBound returns the number of partitions a leaving member may own: those it owned when it was marked.
+// It's not thread-safe.
+func (c *Consistent) leavingBound(member Member) (float64, bool) {
+	if len(c.leaving) == 0 {
+		return 0, false
+	}
+	bound, ok := c.leaving[member.String()]
+	return bound, ok
+}
+
+// distributeLeaving keeps the partitions of the leaving members with them, the walk of the ring skips them
+// afterwards since they are at their bound. It's not thread-safe.
+func (c *Consistent) distributeLeaving(partitions map[int]*Member, loads map[string]float64) {
+	if len(c.leaving) == 0 {
+		return
+	}
+	for partID, owner := range c.partitions {
+		name := (*owner).String()
+		if _, ok := c.leaving[name]; !ok {
+			continue
+		}
+		member, ok := c.members[name]
+		if !ok || loads[name]+1 > c.leaving[name] {
+			continue
+		}
+		partitions[partID] = member
+		loads[name]++
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConsistentRemoveAfter(t *testing.T) {
+	clock := newFakeClock()
+	cfg := newConfig()
+	cfg.Clock = clock
+	cfg.BackupCount = 1
+	c := New(testMembers(4), cfg)
+
+	owned := make(map[int]bool)
+	for partID, owner := range c.owners() {
+		if owner.String() == "node1.olric" {
+			owned[partID] = true
+		}
+	}
+	if err := c.RemoveAfter("node1.olric", time.Minute); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if !reflect.DeepEqual(c.Leaving(), []string{"node1.olric"}) {
+		t.Fatalf("Expected node1.olric to be leaving, got: %v", c.Leaving())
+	}
+
+	// The leaving member keeps its partitions, but gets no new ones.
+	c.Add(testMember("node4.olric"))
+	c.Remove("node2.olric")
+	for partID, owner := range c.owners() {
+		if owner.String() == "node1.olric" && !owned[partID] {
+			t.Fatalf("Expected no new partitions for node1.olric, got %d", partID)
+		}
+		if owned[partID] && owner.String() != "node1.olric" {
+			t.Fatalf("Expected node1.olric to keep partition %d, got: %s", partID, owner)
+		}
+		for _, backup := range c.GetPartitionOwnerAndBackups(partID)[1:] {
+			if backup.String() == "node1.olric" {
+				t.Fatalf("Expected node1.olric not to be a backup of partition %d", partID)
+			}
+		}
+	}
+
+	clock.Advance(30 * time.Second)
+	if len(c.GetMembers()) != 4 {
+		t.Fatalf("Expected 4 members during the grace period, got: %d", len(c.GetMembers()))
+	}
+	clock.Advance(30 * time.Second)
+	for _, member := range c.GetMembers() {
+		if member.String() == "node1.olric" {
+			t.Fatalf("Expected node1.olric to be removed")
+		}
+	}
+	if len(c.Leaving()) != 0 {
+		t.Fatalf("Expected no leaving members, got: %v", c.Leaving())
+	}
+}
+
+func TestConsistentCancelRemove(t *testing.T) {
+	clock := newFakeClock()
+	cfg := newConfig()
+	cfg.Clock = clock
+	c := New(testMembers(4), cfg)
+
+	if err := c.CancelRemove("node1.olric"); err != ErrMemberNotFound {
+		t.Fatalf("Expected ErrMemberNotFound, got: %v", err)
+	}
+	if err := c.RemoveAfter("node1.olric", time.Minute); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	// Calling it again restarts the grace period.
+	clock.Advance(40 * time.Second)
+	if err := c.RemoveAfter("node1.olric", time.Minute); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	clock.Advance(40 * time.Second)
+	if len(c.GetMembers()) != 4 {
+		t.Fatalf("Expected the grace period to restart")
+	}
+	if err := c.CancelRemove("node1.olric"); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	clock.Advance(time.Hour)
+	if len(c.GetMembers()) != 4 || len(c.Leaving()) != 0 {
+		t.Fatalf("Expected node1.olric to stay")
+	}
+}
+
+func TestConsistentConfirmRemove(t *testing.T) {
+	clock := newFakeClock()
+	cfg := newConfig()
+	cfg.Clock = clock
+	c := New(testMembers(4), cfg)
+
+	if err := c.ConfirmRemove("node1.olric"); err != ErrMemberNotFound {
+		t.Fatalf("Expected ErrMemberNotFound, got: %v", err)
+	}
+	if err := c.RemoveAfter("unknown", time.Minute); err != ErrMemberNotFound {
+		t.Fatalf("Expected ErrMemberNotFound, got: %v", err)
+	}
+	if err := c.RemoveAfter("node1.olric", time.Minute); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if err := c.ConfirmRemove("node1.olric"); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if len(c.GetMembers()) != 3 || len(c.Leaving()) != 0 {
+		t.Fatalf("Expected node1.olric to be removed")
+	}
+	clock.Advance(time.Minute)
+
+	if err := c.RemoveAfter("node2.olric", 0); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if len(c.GetMembers()) != 2 {
+		t.Fatalf("Expected node2.olric to be removed right away")
+	}
+}
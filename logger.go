@@ -0,0 +1,66 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// Logger receives the events of a consistent hash ring, see Config.Logger: membership changes and distributions
+// at debug and info level, failed distributions, relaxed load factors and unsatisfied constraints at warn level.
+// The arguments after the message are alternating keys and values. *slog.Logger implements it. Implementations
+// must be safe for concurrent use, they are called with the lock of the ring held.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+}
+
+// logDistribution logs a distribution of the partitions. It's not thread-safe.
+func (c *Consistent) logDistribution(m DistributionMetrics) {
+	if m.Err != nil {
+		c.config.Logger.Warn("partition distribution failed", "error", m.Err, "members", m.Members)
+		return
+	}
+	c.config.Logger.Debug("partitions distributed", "version", m.Version, "members", m.Members,
+		"relocated", m.Relocated, "duration", m.Duration)
+	if c.config.TinyClusterFallback && len(c.members) <= 2 && !c.capped() {
+		c.config.Logger.Debug("partitions distributed round-robin", "members", m.Members)
+	}
+	if unsatisfied := c.unsatisfiedAffinities(); len(unsatisfied) != 0 {
+		c.config.Logger.Warn("partition affinities not satisfied", "partitions", unsatisfied)
+	}
+}
+
+// logRelaxation logs a distribution which succeeded with relaxed load factors, see Config.RelaxedLoadLimit. It's
+// not thread-safe.
+func (c *Consistent) logRelaxation() {
+	if c.config.Logger != nil {
+		c.config.Logger.Warn("load factor relaxed", "load", c.loadFactor(), "scale", c.loadScale,
+			"overflow_tier", c.overflowTier)
+	}
+}
+
+// logMember logs a membership change. It's not thread-safe.
+func (c *Consistent) logMember(msg, name string) {
+	if c.config.Logger != nil {
+		c.config.Logger.Info(msg, "member", name, "members", len(c.members))
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testLogger struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *testLogger) log(level, msg string, args []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, fmt.Sprintf("%s %s %v", level, msg, args))
+}
+
+func (l *testLogger) Debug(msg string, args ...interface{}) { l.log("DEBUG", msg, args) }
+func (l *testLogger) Info(msg string, args ...interface{})  { l.log("INFO", msg, args) }
+func (l *testLogger) Warn(msg string, args ...interface{})  { l.log("WARN", msg, args) }
+
+// find returns the events which start with prefix.
+func (l *testLogger) find(prefix string) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var res []string
+	for _, event := range l.events {
+		if strings.HasPrefix(event, prefix) {
+			res = append(res, event)
+		}
+	}
+	return res
+}
+
+func TestLogger(t *testing.T) {
+	logger := &testLogger{}
+	cfg := newConfig()
+	cfg.Logger = logger
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, cfg)
+	c.Add(testMember("node2.olric"))
+	c.Remove("node0.olric")
+
+	if added := logger.find("INFO member added"); len(added) != 1 || !strings.Contains(added[0], "node2.olric") {
+		t.Fatalf("Expected the added member to be logged, Got: %v", logger.events)
+	}
+	if removed := logger.find("INFO member removed"); len(removed) != 1 || !strings.Contains(removed[0], "node0.olric") {
+		t.Fatalf("Expected the removed member to be logged, Got: %v", logger.events)
+	}
+	if distributed := logger.find("DEBUG partitions distributed ["); len(distributed) != 3 {
+		t.Fatalf("Expected 3 distributions, Got: %v", logger.events)
+	}
+
+	// Decommission plans on a copy of the ring, which doesn't log.
+	before := len(logger.events)
+	if _, err := c.Decommission("node1.olric"); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if len(logger.events) != before {
+		t.Fatalf("Expected no events, Got: %v", logger.events[before:])
+	}
+}
+
+func TestLoggerWarnings(t *testing.T) {
+	logger := &testLogger{}
+	cfg := newConfig()
+	cfg.Logger = logger
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, cfg)
+
+	if err := c.SetPartitionAffinity(3, func(Member) bool { return false }); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if warnings := logger.find("WARN partition affinities not satisfied"); len(warnings) != 1 || !strings.Contains(warnings[0], "[3]") {
+		t.Fatalf("Expected an unsatisfied affinity, Got: %v", logger.events)
+	}
+
+	var distErr *DistributionError
+	if err := c.SetMaxLoad("node0.olric", 1); !errors.As(err, &distErr) {
+		t.Fatalf("Expected a *DistributionError, Got: %v", err)
+	}
+	if failed := logger.find("WARN partition distribution failed"); len(failed) != 1 {
+		t.Fatalf("Expected a failed distribution, Got: %v", logger.events)
+	}
+}
+
+func TestLoggerRelaxation(t *testing.T) {
+	logger := &testLogger{}
+	cfg := newConfig()
+	cfg.Load = 0.5
+	cfg.RelaxedLoadLimit = 2
+	cfg.Logger = logger
+	New(testMembers(4), cfg)
+	if relaxed := logger.find("WARN load factor relaxed"); len(relaxed) != 1 || !strings.Contains(relaxed[0], "load 1") {
+		t.Fatalf("Expected a relaxation event, got: %v", relaxed)
+	}
+}
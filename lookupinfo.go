@@ -0,0 +1,95 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"math"
+)
+
+// LookupInfo is the location of a key with what a router needs for policy decisions about its owner, see
+// LocateKeyWithInfo.
+type LookupInfo struct {
+	// Member is the owner of the key, nil if the ring has no members.
+	Member Member
+
+	// PartitionID is the partition of the key.
+	PartitionID int
+
+	// Labels is a copy of the labels of the owner, nil unless it implements LabeledMember.
+	Labels map[string]string
+
+	// Weight is the weight of the owner, see WeightedMember.
+	Weight float64
+
+	// Load is the number of partitions the owner owns.
+	Load float64
+
+	// LoadBound is the number of partitions the owner may own under the load factor of the current distribution,
+	// Config.Load or the one relaxed by Config.RelaxedLoadLimit.
+	LoadBound float64
+
+	// OverBound is set if the owner owns more partitions than LoadBound, e.g. after a failover with
+	// Config.FailoverToBackup or a custom Distributor.
+	OverBound bool
+
+	// Relaxed is set if the bound of the owner is relaxed in the current distribution, see
+	// Config.RelaxedLoadLimit and SetOverflowPriority. LoadBound is the relaxed bound then.
+	Relaxed bool
+
+	// Draining is set if the owner is draining, see Drain.
+	Draining bool
+
+	// Generation is the generation of the owner, zero unless it implements GenerationMember.
+	Generation uint64
+}
+
+// LocateKeyWithInfo is like LocateKey, but it returns the owner along with its partition, labels, weight and load.
+func (c *Consistent) LocateKeyWithInfo(key []byte) LookupInfo {
+	partID := c.FindPartitionID(key)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	info := LookupInfo{PartitionID: partID}
+	member := c.getPartitionOwner(partID)
+	if member == nil {
+		return info
+	}
+	info.Member = member
+	if lm, ok := member.(LabeledMember); ok {
+		labels := lm.Labels()
+		info.Labels = make(map[string]string, len(labels))
+		for k, v := range labels {
+			info.Labels[k] = v
+		}
+	}
+	info.Weight = memberWeight(member)
+	info.Load = c.loads[member.String()]
+	info.LoadBound = math.Floor(c.loadBound(member))
+	info.OverBound = info.Load > info.LoadBound
+	info.Relaxed = c.relaxedFor(member)
+	_, info.Draining = c.draining[member.String()]
+	info.Generation, _ = memberGeneration(member)
+	return info
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLocateKeyWithInfo(t *testing.T) {
+	var members []Member
+	for i := 0; i < 4; i++ {
+		labels := map[string]string{"rack": fmt.Sprintf("r%d", i%2)}
+		members = append(members, labeledMember{name: fmt.Sprintf("node%d.olric", i), labels: labels})
+	}
+	c := New(members, newConfig())
+
+	key := []byte("my-key")
+	info := c.LocateKeyWithInfo(key)
+	if info.Member.String() != c.LocateKey(key).String() {
+		t.Fatalf("Expected %s, got: %s", c.LocateKey(key), info.Member)
+	}
+	if info.PartitionID != c.FindPartitionID(key) {
+		t.Fatalf("Expected partition %d, got: %d", c.FindPartitionID(key), info.PartitionID)
+	}
+	if want := info.Member.(labeledMember).labels["rack"]; info.Labels["rack"] != want {
+		t.Fatalf("Expected rack %s, got: %v", want, info.Labels)
+	}
+	info.Labels["rack"] = "changed"
+	if c.LocateKeyWithInfo(key).Labels["rack"] == "changed" {
+		t.Fatalf("Expected a copy of the labels")
+	}
+	if info.Weight != 1 || info.Load != c.LoadDistribution()[info.Member.String()] || info.LoadBound != c.AverageLoad() {
+		t.Fatalf("Unexpected info: %+v", info)
+	}
+	if info.OverBound || info.Draining {
+		t.Fatalf("Unexpected info: %+v", info)
+	}
+}
+
+func TestLocateKeyWithInfoOverBound(t *testing.T) {
+	cfg := newConfig()
+	cfg.Load = 0.5
+	cfg.RelaxedLoadLimit = 2
+	c := New(testMembers(4), cfg)
+
+	// The bound follows the relaxed load factor of the distribution.
+	for i := 0; i < 100; i++ {
+		info := c.LocateKeyWithInfo([]byte(fmt.Sprintf("key-%d", i)))
+		if info.Load > info.LoadBound != info.OverBound {
+			t.Fatalf("Unexpected info: %+v", info)
+		}
+		if info.OverBound || info.LoadBound != 6 || !info.Relaxed {
+			t.Fatalf("Expected the relaxed bound 6, got: %+v", info)
+		}
+	}
+
+	c.config.RelaxedLoadLimit = 0
+	c.config.Load = 1.25
+	c.Add(testMember("node4.olric"))
+	if info := c.LocateKeyWithInfo([]byte("key-0")); info.Relaxed {
+		t.Fatalf("Expected no relaxation, got: %+v", info)
+	}
+}
+
+func TestLocateKeyWithInfoOverflowRelaxed(t *testing.T) {
+	cfg := newConfig()
+	cfg.Load = 0.8
+	cfg.RelaxedLoadLimit = 2
+	c := New(nil, cfg)
+	c.SetOverflowPriority("node0.olric", 1)
+	c.SetMembers(testMembers(4))
+	for i := 0; i < 100; i++ {
+		info := c.LocateKeyWithInfo([]byte(fmt.Sprintf("key-%d", i)))
+		if info.Relaxed != (info.Member.String() == "node0.olric") {
+			t.Fatalf("Expected only the overflow member to be relaxed, got: %+v", info)
+		}
+	}
+}
+
+func TestLocateKeyWithInfoEmpty(t *testing.T) {
+	c := New(nil, newConfig())
+	if info := c.LocateKeyWithInfo([]byte("my-key")); info.Member != nil {
+		t.Fatalf("Expected no member, got: %+v", info)
+	}
+}
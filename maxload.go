@@ -0,0 +1,93 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// SetMaxLoad sets an absolute limit on the number of partitions the member may own, on top of the limit derived
+// from Config.Load. It's useful to ramp up a member which is warming up. A limit below one removes it. The limit
+// may be set before the member joins and stays in place until it's removed by SetMaxLoad, even if the member
+// leaves the ring. It applies to partition ownership, not to backups. Members with a limit are never distributed
+// with Config.TinyClusterFallback.
+//
+// If the member is on the ring, the partitions are distributed again right away. If the limits leave not enough
+// room for all partitions, SetMaxLoad restores the previous limit and returns the *DistributionError, which wraps
+// ErrNotEnoughRoom. Its MinimumLoad is +Inf if no Load value can compensate, i.e. every member has a limit.
+// Add and Remove panic with the same error if the limits make the new membership impossible to distribute.
+func (c *Consistent) SetMaxLoad(name string, n int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name = c.memberName(name)
+	previous, ok := c.maxLoads[name]
+	if n < 1 {
+		delete(c.maxLoads, name)
+	} else {
+		c.maxLoads[name] = n
+	}
+	if _, member := c.members[name]; !member {
+		return nil
+	}
+	if err := c.redistributeNow(); err != nil {
+		if ok {
+			c.maxLoads[name] = previous
+		} else {
+			delete(c.maxLoads, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// MaxLoad returns the limit set by SetMaxLoad for the member, or zero if there is none.
+func (c *Consistent) MaxLoad(name string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	name = c.memberName(name)
+	return c.maxLoads[name]
+}
+
+// maxLoad returns the limit set by SetMaxLoad for the member. It's not thread-safe.
+func (c *Consistent) maxLoad(member Member) (float64, bool) {
+	if len(c.maxLoads) == 0 {
+		return 0, false
+	}
+	n, ok := c.maxLoads[member.String()]
+	return float64(n), ok
+}
+
+// capped reports whether a member on the ring has a limit set by SetMaxLoad or is draining. It's not thread-safe.
+func (c *Consistent) capped() bool {
+	return len(c.draining) != 0 || c.cappedCount() != 0
+}
+
+// cappedCount returns the number of members on the ring with a limit set by SetMaxLoad. It's not thread-safe.
+func (c *Consistent) cappedCount() int {
+	var count int
+	for name := range c.maxLoads {
+		if _, ok := c.members[name]; ok {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestSetMaxLoad(t *testing.T) {
+	var members []Member
+	for i := 0; i < 4; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	cfg.PartitionCount = 71
+	cfg.Load = 2
+	c := New(members, cfg)
+
+	if err := c.SetMaxLoad("node0.olric", 3); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if c.MaxLoad("node0.olric") != 3 {
+		t.Fatalf("Expected max load 3, Got: %d", c.MaxLoad("node0.olric"))
+	}
+	if load := c.LoadDistribution()["node0.olric"]; load > 3 {
+		t.Fatalf("node0.olric owns %g partitions, more than its max load", load)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	// A limit set before a member joins is applied when it joins.
+	if err := c.SetMaxLoad("node4.olric", 1); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	c.Add(testMember("node4.olric"))
+	if load := c.LoadDistribution()["node4.olric"]; load > 1 {
+		t.Fatalf("node4.olric owns %g partitions, more than its max load", load)
+	}
+
+	// Removing the limit redistributes without it.
+	if err := c.SetMaxLoad("node0.olric", 0); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if c.MaxLoad("node0.olric") != 0 {
+		t.Fatalf("Expected no max load")
+	}
+	c.SetMaxLoad("node4.olric", 0)
+	if !c.Equal(New(c.GetMembers(), cfg)) {
+		t.Fatalf("Layout without limits is different from a fresh ring")
+	}
+}
+
+func TestSetMaxLoadNotEnoughRoom(t *testing.T) {
+	members := []Member{testMember("node0.olric"), testMember("node1.olric"), testMember("node2.olric")}
+	c := New(members, newConfig())
+	// Every member may own at most 10 partitions with Load 1.25.
+	for _, name := range []string{"node0.olric", "node1.olric"} {
+		if err := c.SetMaxLoad(name, 8); err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+	}
+	version := c.Version()
+
+	// 8+8+5 partitions is not enough and no Load value can change that.
+	err := c.SetMaxLoad("node2.olric", 5)
+	var distErr *DistributionError
+	if !errors.As(err, &distErr) || !errors.Is(err, ErrNotEnoughRoom) {
+		t.Fatalf("Expected a DistributionError, Got: %v", err)
+	}
+	if !math.IsInf(distErr.MinimumLoad, 1) || distErr.Capped != 3 {
+		t.Fatalf("Expected an infinite minimum load with 3 capped members, Got: %+v", distErr)
+	}
+	if c.MaxLoad("node2.olric") != 0 || c.Version() != version {
+		t.Fatalf("Expected the previous limit and partition table")
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	// 8+2+10 partitions is not enough, but a larger Load helps node2.olric.
+	err = c.SetMaxLoad("node1.olric", 2)
+	if !errors.As(err, &distErr) || math.IsInf(distErr.MinimumLoad, 0) || distErr.MinimumLoad <= 1.25 {
+		t.Fatalf("Expected a finite minimum load above 1.25, Got: %v", err)
+	}
+	if c.MaxLoad("node1.olric") != 8 {
+		t.Fatalf("Expected the previous limit, Got: %d", c.MaxLoad("node1.olric"))
+	}
+	cfg := newConfig()
+	cfg.Load = distErr.MinimumLoad
+	other := New(members, cfg)
+	if err := other.SetMaxLoad("node0.olric", 8); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if err := other.SetMaxLoad("node1.olric", 2); err != nil {
+		t.Fatalf("Expected nil with the minimum load, Got: %v", err)
+	}
+}
+
+func TestSetMaxLoadTinyCluster(t *testing.T) {
+	cfg := newConfig()
+	cfg.Load = 2
+	cfg.TinyClusterFallback = true
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, cfg)
+	if err := c.SetMaxLoad("node0.olric", 2); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if load := c.LoadDistribution()["node0.olric"]; load > 2 {
+		t.Fatalf("node0.olric owns %g partitions, more than its max load", load)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+}
+
+func TestSetMaxLoadSnapshot(t *testing.T) {
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric"), testMember("node2.olric")}, newConfig())
+	if err := c.SetMaxLoad("node0.olric", 4); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	restored, err := FromSnapshot(c.Snapshot())
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if restored.MaxLoad("node0.olric") != 4 {
+		t.Fatalf("Expected the limit to be restored")
+	}
+	restored.Add(testMember("node3.olric"))
+	if load := restored.LoadDistribution()["node0.olric"]; load > 4 {
+		t.Fatalf("node0.olric owns %g partitions, more than its max load", load)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+)
+
+// StringerMember returns s as a Member. Member has the same method set as fmt.Stringer, so any fmt.Stringer
+// can be added to the consistent hash ring as is. This function only documents that at the call site.
+func StringerMember(s fmt.Stringer) Member {
+	return s
+}
+
+// Namer is implemented by types which are identified by a Name method rather than String.
+type Namer interface {
+	Name() string
+}
+
+// NamedMember adapts a Namer to the Member interface. The original value is available via the Namer field,
+// so members returned by LocateKey and friends can be converted back:
+//
+//	c.Add(consistent.NamedMember{Namer: node})
+//	node := c.LocateKey(key).(consistent.NamedMember).Namer.(*Node)
+type NamedMember struct {
+	Namer
+}
+
+// String returns the name of the underlying Namer.
+func (m NamedMember) String() string {
+	return m.Name()
+}
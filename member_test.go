@@ -0,0 +1,65 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+type testNamer struct {
+	name string
+}
+
+func (tn *testNamer) Name() string {
+	return tn.name
+}
+
+func TestStringerMember(t *testing.T) {
+	c := New(nil, newConfig())
+	var s fmt.Stringer = testMember("node1.olric")
+	c.Add(StringerMember(s))
+	owner := c.LocateKey([]byte("Olric"))
+	if owner != s {
+		t.Fatalf("Expected %v, Got: %v", s, owner)
+	}
+}
+
+func TestNamedMember(t *testing.T) {
+	c := New(nil, newConfig())
+	node := &testNamer{name: "node1.olric"}
+	c.Add(NamedMember{Namer: node})
+
+	owner := c.LocateKey([]byte("Olric"))
+	if owner.String() != node.name {
+		t.Fatalf("Expected %s, Got: %s", node.name, owner.String())
+	}
+	if owner.(NamedMember).Namer.(*testNamer) != node {
+		t.Fatalf("Expected the original value")
+	}
+	c.Remove(node.name)
+	if len(c.GetMembers()) != 0 {
+		t.Fatalf("member count should be zero")
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"time"
+)
+
+// MeterProvider receives the measurements of a consistent hash ring, see Config.MeterProvider. The otelconsistent
+// module implements it with OpenTelemetry metrics and spans. Implementations must be safe for concurrent use and
+// fast, they are called on the lookup path.
+type MeterProvider interface {
+	// RecordDistribution is called after every distribution of the partitions, including failed ones.
+	RecordDistribution(m DistributionMetrics)
+
+	// RecordLookup is called after every LocateKey with its latency.
+	RecordLookup(d time.Duration)
+}
+
+// DistributionMetrics describes a distribution of the partitions.
+type DistributionMetrics struct {
+	// Start is the time the distribution started.
+	Start time.Time
+
+	// Duration is the time it took to distribute the partitions.
+	Duration time.Duration
+
+	// Relocated is the number of partitions whose owner changed.
+	Relocated int
+
+	// Members is the number of members.
+	Members int
+
+	// Version is the version of the partition table after the distribution.
+	Version uint64
+
+	// Err is the error of a failed distribution. The partition table is unchanged in that case.
+	Err error
+}
+
+// recordDistribution reports a distribution which started at start and replaced previous, if it succeeded.
+// It's not thread-safe.
+func (c *Consistent) recordDistribution(start time.Time, previous map[int]*Member, err error) {
+	m := DistributionMetrics{
+		Start:    start,
+		Duration: time.Since(start),
+		Members:  len(c.members),
+		Version:  c.version,
+		Err:      err,
+	}
+	if err == nil {
+		m.Relocated = relocated(previous, c.partitions)
+	}
+	if c.config.MeterProvider != nil {
+		c.config.MeterProvider.RecordDistribution(m)
+	}
+	if c.config.Logger != nil {
+		c.logDistribution(m)
+	}
+}
+
+// observed reports whether distributions are reported to Config.MeterProvider or Config.Logger.
+func (c *Consistent) observed() bool {
+	return c.config.MeterProvider != nil || c.config.Logger != nil
+}
+
+// relocated returns the number of partitions whose owner differs between the partition tables.
+func relocated(previous, current map[int]*Member) int {
+	var count int
+	for partID, member := range current {
+		old, ok := previous[partID]
+		if !ok || (*old).String() != (*member).String() {
+			count++
+		}
+	}
+	return count
+}
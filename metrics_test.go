@@ -0,0 +1,121 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testMeter struct {
+	mu            sync.Mutex
+	distributions []DistributionMetrics
+	lookups       int
+}
+
+func (m *testMeter) RecordDistribution(d DistributionMetrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.distributions = append(m.distributions, d)
+}
+
+func (m *testMeter) RecordLookup(time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lookups++
+}
+
+func TestMeterProvider(t *testing.T) {
+	meter := &testMeter{}
+	cfg := newConfig()
+	cfg.MeterProvider = meter
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, cfg)
+	c.Add(testMember("node2.olric"))
+	c.LocateKey([]byte("Olric"))
+	c.LocateKey([]byte("Olric"))
+
+	if len(meter.distributions) != 2 {
+		t.Fatalf("Expected 2 distributions, Got: %d", len(meter.distributions))
+	}
+	first, second := meter.distributions[0], meter.distributions[1]
+	if first.Relocated != cfg.PartitionCount || first.Members != 2 || first.Version != 1 || first.Err != nil {
+		t.Fatalf("Unexpected first distribution: %+v", first)
+	}
+	expected := len(New([]Member{testMember("node0.olric"), testMember("node1.olric")}, newConfig()).Diff(c))
+	if second.Relocated != expected || second.Members != 3 || second.Version != 2 {
+		t.Fatalf("Unexpected second distribution, expected %d relocations: %+v", expected, second)
+	}
+	if second.Start.IsZero() || second.Duration < 0 {
+		t.Fatalf("Unexpected timing: %+v", second)
+	}
+	if meter.lookups != 2 {
+		t.Fatalf("Expected 2 lookups, Got: %d", meter.lookups)
+	}
+}
+
+func TestMeterProviderFailedDistribution(t *testing.T) {
+	meter := &testMeter{}
+	cfg := newConfig()
+	cfg.Load = 0.5
+	cfg.MeterProvider = meter
+	c := New(nil, cfg)
+	func() {
+		defer func() {
+			_ = recover()
+		}()
+		c.Add(testMember("node0.olric"))
+	}()
+	if len(meter.distributions) != 1 || meter.distributions[0].Err == nil {
+		t.Fatalf("Expected a failed distribution, Got: %+v", meter.distributions)
+	}
+}
+
+func TestMeterProviderAsync(t *testing.T) {
+	meter := &testMeter{}
+	cfg := newConfig()
+	cfg.MeterProvider = meter
+	cfg.AsyncDistribution = true
+	c := New(nil, cfg)
+	for i := 0; i < 4; i++ {
+		c.Add(testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	meter.mu.Lock()
+	defer meter.mu.Unlock()
+	var relocated int
+	for _, d := range meter.distributions {
+		if d.Err != nil {
+			t.Fatalf("Unexpected error: %v", d.Err)
+		}
+		relocated += d.Relocated
+	}
+	if len(meter.distributions) == 0 || relocated < cfg.PartitionCount {
+		t.Fatalf("Unexpected distributions: %+v", meter.distributions)
+	}
+}
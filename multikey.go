@@ -0,0 +1,62 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// SameOwner reports whether all keys are owned by the same member and returns it, e.g. to take the fast path of
+// a multi-key transaction. The keys are located against a single version of the partition table. It returns
+// false if there are no keys or the ring is empty.
+func (c *Consistent) SameOwner(keys ...[]byte) (Member, bool) {
+	if len(keys) == 0 {
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	owner := c.getPartitionOwner(c.FindPartitionID(keys[0]))
+	if owner == nil {
+		return nil, false
+	}
+	for _, key := range keys[1:] {
+		if other := c.getPartitionOwner(c.FindPartitionID(key)); !sameMember(owner, other) {
+			return nil, false
+		}
+	}
+	return owner, true
+}
+
+// OwnersOf groups the keys by their owners: it maps member names to the indexes of their keys in ascending order.
+// A multi-key operation whose result has more than one entry needs coordination across members. The keys are
+// located against a single version of the partition table. The result is empty if the ring is empty.
+func (c *Consistent) OwnersOf(keys ...[]byte) map[string][]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	res := make(map[string][]int)
+	for i, key := range keys {
+		if owner := c.getPartitionOwner(c.FindPartitionID(key)); owner != nil {
+			res[owner.String()] = append(res[owner.String()], i)
+		}
+	}
+	return res
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSameOwner(t *testing.T) {
+	cfg := newConfigWith(271)
+	cfg.KeyExtractor = PrefixExtractor('/', 1)
+	c := New(testMembers(6), cfg)
+
+	owner, ok := c.SameOwner([]byte("orders/1"), []byte("orders/2"), []byte("orders/3"))
+	if !ok || owner.String() != c.LocateKey([]byte("orders")).String() {
+		t.Fatalf("Expected the owner of orders, Got: %v, %v", owner, ok)
+	}
+	var keys [][]byte
+	for i := 0; i < 50; i++ {
+		keys = append(keys, []byte(fmt.Sprintf("table%d/row", i)))
+	}
+	if owner, ok := c.SameOwner(keys...); ok || owner != nil {
+		t.Fatalf("Expected keys of different owners, Got: %v", owner)
+	}
+	if _, ok := c.SameOwner(); ok {
+		t.Fatalf("Expected false without keys")
+	}
+	if _, ok := New(nil, cfg).SameOwner([]byte("orders/1")); ok {
+		t.Fatalf("Expected false on an empty ring")
+	}
+}
+
+func TestOwnersOf(t *testing.T) {
+	c := New(testMembers(6), newConfigWith(271))
+	var keys [][]byte
+	for i := 0; i < 50; i++ {
+		keys = append(keys, []byte(fmt.Sprintf("key%d", i)))
+	}
+	owners := c.OwnersOf(keys...)
+	if len(owners) < 2 {
+		t.Fatalf("Expected several owners, Got: %v", owners)
+	}
+	var total int
+	for name, indexes := range owners {
+		for _, i := range indexes {
+			if c.LocateKey(keys[i]).String() != name {
+				t.Fatalf("Expected %s to own %s", name, keys[i])
+			}
+		}
+		total += len(indexes)
+	}
+	if total != len(keys) {
+		t.Fatalf("Expected %d keys, Got: %d", len(keys), total)
+	}
+	owner := c.LocateKey(keys[0]).String()
+	if single := c.OwnersOf(keys[0], keys[0]); !reflect.DeepEqual(single, map[string][]int{owner: {0, 1}}) {
+		t.Fatalf("Unexpected owners: %v", single)
+	}
+	if empty := New(nil, newConfig()).OwnersOf(keys...); len(empty) != 0 {
+		t.Fatalf("Expected no owners, Got: %v", empty)
+	}
+}
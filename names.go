@@ -0,0 +1,71 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// normalize returns the normalized form of a member name, see Config.NormalizeName.
+func (c *Consistent) normalize(name string) string {
+	if c.config.NormalizeName != nil {
+		return c.config.NormalizeName(name)
+	}
+	return name
+}
+
+// validName reports whether the member name is acceptable: it must not be empty after normalization.
+func (c *Consistent) validName(name string) bool {
+	return c.normalize(name) != ""
+}
+
+// memberName returns the name of the active member whose normalized name equals the normalized form of the given
+// name, or the given name if there is none. It's not thread-safe.
+func (c *Consistent) memberName(name string) string {
+	if c.config.NormalizeName == nil {
+		return name
+	}
+	if _, ok := c.members[name]; ok {
+		return name
+	}
+	if member, ok := c.normalizedNames[c.normalize(name)]; ok {
+		return member
+	}
+	return name
+}
+
+// indexName maps the normalized name of a new member to its name for memberName. It's not thread-safe.
+func (c *Consistent) indexName(name string) {
+	if c.config.NormalizeName == nil {
+		return
+	}
+	if c.normalizedNames == nil {
+		c.normalizedNames = make(map[string]string)
+	}
+	c.normalizedNames[c.normalize(name)] = name
+}
+
+// unindexName drops the normalized name of a removed member. It's not thread-safe.
+func (c *Consistent) unindexName(name string) {
+	normalized := c.normalize(name)
+	if c.normalizedNames[normalized] == name {
+		delete(c.normalizedNames, normalized)
+	}
+}
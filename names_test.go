@@ -0,0 +1,121 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"strings"
+	"testing"
+)
+
+func lowerHost(name string) string {
+	if i := strings.LastIndexByte(name, ':'); i >= 0 {
+		name = name[:i]
+	}
+	return strings.ToLower(name)
+}
+
+func TestConsistentNormalizeName(t *testing.T) {
+	cfg := newConfig()
+	cfg.NormalizeName = lowerHost
+	cfg.OnDuplicateAdd = RejectDuplicate
+	c := New([]Member{testMember("Node1:3320"), testMember("node1"), testMember("node2")}, cfg)
+	if len(c.GetMembers()) != 2 {
+		t.Fatalf("Expected 2 members, got: %d", len(c.GetMembers()))
+	}
+	if err := c.TryAdd(testMember("NODE2:3320")); err != ErrMemberAlreadyExists {
+		t.Fatalf("Expected ErrMemberAlreadyExists, got: %v", err)
+	}
+	if err := c.SetMaxLoad("NODE1", 15); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if max := c.MaxLoad("Node1:3320"); max != 15 {
+		t.Fatalf("Expected 15, got: %d", max)
+	}
+
+	c.Remove("node1:80")
+	members := c.GetMembers()
+	if len(members) != 1 || members[0].String() != "node2" {
+		t.Fatalf("Expected only node2, got: %v", members)
+	}
+	if len(c.normalizedNames) != 1 {
+		t.Fatalf("Expected 1 normalized name, got: %v", c.normalizedNames)
+	}
+
+	c.Add(testMember("NODE1:80"))
+	if got := c.memberName("node1"); got != "NODE1:80" {
+		t.Fatalf("Expected NODE1:80, got: %s", got)
+	}
+}
+
+func TestConsistentNormalizeNameSetMembers(t *testing.T) {
+	cfg := newConfig()
+	cfg.NormalizeName = lowerHost
+	c := New([]Member{testMember("node1"), testMember("node2")}, cfg)
+	version := c.Version()
+
+	c.SetMembers([]Member{testMember("node1"), testMember("node2"), testMember("Node2")})
+	if c.Version() != version {
+		t.Fatalf("Expected no distribution")
+	}
+
+	c.SetMembers([]Member{testMember("node1"), testMember("Node2:3320")})
+	members := c.GetMembers()
+	if len(members) != 2 {
+		t.Fatalf("Expected 2 members, got: %v", members)
+	}
+	if members[1].String() != "Node2:3320" {
+		t.Fatalf("Expected node2 to be replaced, got: %v", members)
+	}
+}
+
+func TestConsistentNormalizeNameStandby(t *testing.T) {
+	cfg := newConfig()
+	cfg.NormalizeName = lowerHost
+	c := New([]Member{testMember("node1")}, cfg)
+	c.AddStandby(testMember("NODE1"))
+	c.AddStandby(testMember("node2"))
+	if len(c.Standbys()) != 1 {
+		t.Fatalf("Expected 1 standby, got: %v", c.Standbys())
+	}
+	if err := c.PromoteStandby("Node2:3320"); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if len(c.GetMembers()) != 2 {
+		t.Fatalf("Expected 2 members, got: %d", len(c.GetMembers()))
+	}
+}
+
+func TestConsistentEmptyName(t *testing.T) {
+	cfg := newConfig()
+	cfg.NormalizeName = strings.TrimSpace
+	c := New([]Member{testMember(" "), testMember("node1")}, cfg)
+	if err := c.TryAdd(testMember("")); err != ErrInvalidMemberName {
+		t.Fatalf("Expected ErrInvalidMemberName, got: %v", err)
+	}
+	c.Add(testMember("  "))
+	c.SetMembers([]Member{testMember("node1"), testMember("\t")})
+	if len(c.GetMembers()) != 1 {
+		t.Fatalf("Expected 1 member, got: %v", c.GetMembers())
+	}
+}
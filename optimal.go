@@ -0,0 +1,295 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"math"
+	"time"
+)
+
+// OptimalDistributor computes a perfectly balanced partition table which costs the least to reach from the current
+// one, by default the one which moves the fewest partitions. Every member owns its share of the partitions split
+// by weight, rounded up or down, within the limits of SetMaxLoad; draining members own nothing. It solves the
+// min-cost assignment of partitions to members exactly, which takes O(P²·M) time for P partitions and M members,
+// so it's meant for offline planning rather than for every membership change.
+type OptimalDistributor struct {
+	// Cost returns the cost of assigning the partition to the member. current is the current owner of the
+	// partition, nil if it has none. If Cost is nil, keeping a partition with its current owner costs zero and
+	// moving it costs one.
+	Cost func(partID int, current, member Member) float64
+}
+
+// Assignment is a partition table computed by OptimalDistributor. ApplyAssignment installs it.
+type Assignment struct {
+	// Version is the version of the partition table the assignment was computed from.
+	Version uint64
+
+	// Owners contains the owner of every partition, indexed by partition ID.
+	Owners []Member
+
+	// Moves are the partitions whose owner differs from the current table, ordered by partition ID.
+	Moves []PartitionMove
+
+	// Cost is the total cost of the assignment.
+	Cost float64
+}
+
+// Plan computes the assignment for the current members of c. It returns the *DistributionError if the limits set
+// by SetMaxLoad leave not enough room for all partitions, and ErrInsufficientMemberCount if c has no members. It
+// isn't a Distributor, ApplyAssignment installs the result.
+func (d OptimalDistributor) Plan(c *Consistent) (*Assignment, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var members []Member
+	for _, key := range c.memberHashes {
+		if member := *c.hashedMembers[key]; !c.drained(member) {
+			members = append(members, member)
+		}
+	}
+	if len(members) == 0 {
+		return nil, ErrInsufficientMemberCount
+	}
+
+	// Every member owns between lower and upper partitions.
+	partitionCount := int(c.partitionCount)
+	total := c.totalWeight()
+	lower := make([]int, len(members))
+	upper := make([]int, len(members))
+	var room int
+	for i, member := range members {
+		share := float64(partitionCount) * c.weight(member) / total
+		lower[i], upper[i] = int(math.Floor(share+1e-9)), int(math.Ceil(share-1e-9))
+		if max, ok := c.maxLoad(member); ok {
+			lower[i], upper[i] = int(math.Min(float64(lower[i]), max)), int(math.Min(float64(upper[i]), max))
+		}
+		room += upper[i]
+	}
+	if room < partitionCount {
+		return nil, c.distributionError(0)
+	}
+
+	cost := d.Cost
+	if cost == nil {
+		cost = moveCost
+	}
+	costs := make([][]float64, partitionCount)
+	minCost, maxCost := math.Inf(1), math.Inf(-1)
+	for partID := range costs {
+		current := c.getPartitionOwner(partID)
+		costs[partID] = make([]float64, len(members))
+		for i, member := range members {
+			costs[partID][i] = cost(partID, current, member)
+			minCost, maxCost = math.Min(minCost, costs[partID][i]), math.Max(maxCost, costs[partID][i])
+		}
+	}
+	// Filling a member up to its lower bound is worth more than any difference in cost, so all of them are filled.
+	bonus := (maxCost-minCost+1)*float64(partitionCount)*2 + 1
+
+	assigned := newAssignmentState(len(members), partitionCount)
+	for partID := 0; partID < partitionCount; partID++ {
+		assigned.add(partID, costs, lower, upper, bonus)
+	}
+
+	a := &Assignment{Version: c.version, Owners: make([]Member, partitionCount)}
+	for partID, i := range assigned.owner {
+		a.Owners[partID] = members[i]
+		a.Cost += costs[partID][i]
+		if current := c.getPartitionOwner(partID); !sameMember(current, members[i]) {
+			a.Moves = append(a.Moves, PartitionMove{PartitionID: partID, From: current, To: members[i]})
+		}
+	}
+	return a, nil
+}
+
+// moveCost is the default cost of OptimalDistributor.
+func moveCost(_ int, current, member Member) float64 {
+	if sameMember(current, member) {
+		return 0
+	}
+	return 1
+}
+
+// assignmentState is the partial assignment built by OptimalDistributor, one partition at a time.
+type assignmentState struct {
+	owner []int
+	owned [][]int
+	dist  []float64
+	pred  []int
+	via   []int
+	// move holds the cheapest partition to move from member a to member b, and gain its cost.
+	move [][]int
+	gain [][]float64
+}
+
+func newAssignmentState(memberCount, partitionCount int) *assignmentState {
+	s := &assignmentState{
+		owner: make([]int, 0, partitionCount),
+		owned: make([][]int, memberCount),
+		dist:  make([]float64, memberCount),
+		pred:  make([]int, memberCount),
+		via:   make([]int, memberCount),
+		move:  make([][]int, memberCount),
+		gain:  make([][]float64, memberCount),
+	}
+	for i := range s.move {
+		s.move[i] = make([]int, memberCount)
+		s.gain[i] = make([]float64, memberCount)
+	}
+	return s
+}
+
+// add assigns the partition along the shortest augmenting path of the residual network, like the Hungarian
+// algorithm: the partition goes to a member, which may pass one of its partitions on to another member, and so
+// on, until a member with room takes the extra partition. Adding the partitions one by one this way keeps the
+// assignment optimal for the partitions added so far.
+func (s *assignmentState) add(partID int, costs [][]float64, lower, upper []int, bonus float64) {
+	members := len(s.owned)
+	for a := 0; a < members; a++ {
+		for b := 0; b < members; b++ {
+			s.move[a][b], s.gain[a][b] = -1, math.Inf(1)
+		}
+		for _, q := range s.owned[a] {
+			for b := 0; b < members; b++ {
+				if b == a {
+					continue
+				}
+				if g := costs[q][b] - costs[q][a]; g < s.gain[a][b] {
+					s.move[a][b], s.gain[a][b] = q, g
+				}
+			}
+		}
+	}
+
+	// Bellman-Ford, the residual network has no negative cycles.
+	for b := 0; b < members; b++ {
+		s.dist[b], s.pred[b], s.via[b] = costs[partID][b], -1, -1
+	}
+	for round := 0; round < members; round++ {
+		changed := false
+		for a := 0; a < members; a++ {
+			for b := 0; b < members; b++ {
+				if s.move[a][b] < 0 {
+					continue
+				}
+				if d := s.dist[a] + s.gain[a][b]; d < s.dist[b]-1e-9 {
+					s.dist[b], s.pred[b], s.via[b] = d, a, s.move[a][b]
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	target, best := -1, math.Inf(1)
+	for t := 0; t < members; t++ {
+		load := len(s.owned[t])
+		if load >= upper[t] {
+			continue
+		}
+		d := s.dist[t]
+		if load < lower[t] {
+			d -= bonus
+		}
+		if d < best-1e-9 {
+			target, best = t, d
+		}
+	}
+
+	// Walk the path back from the member which takes the extra partition.
+	s.owner = append(s.owner, -1)
+	for t := target; ; {
+		a, q := s.pred[t], s.via[t]
+		if a < 0 {
+			s.assign(partID, t)
+			return
+		}
+		s.release(q, a)
+		s.assign(q, t)
+		t = a
+	}
+}
+
+func (s *assignmentState) assign(partID, member int) {
+	s.owner[partID] = member
+	s.owned[member] = append(s.owned[member], partID)
+}
+
+func (s *assignmentState) release(partID, member int) {
+	owned := s.owned[member]
+	for i, q := range owned {
+		if q == partID {
+			owned[i] = owned[len(owned)-1]
+			s.owned[member] = owned[:len(owned)-1]
+			return
+		}
+	}
+}
+
+// ApplyAssignment installs the partition table computed by OptimalDistributor. The backups are computed again and
+// the version is bumped. The table stays until the next distribution, e.g. after a membership change; set
+// Config.Stickiness to keep most of it then. It returns ErrPlanOutdated if the partition table or the membership
+// changed since the assignment was computed, or if a distribution is pending; call Flush and compute it again in
+// that case. If Config.ReplicaStrategy fails, it returns the error and the partition table stays as it was.
+func (c *Consistent) ApplyAssignment(a *Assignment) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if a == nil || c.pending || c.version != a.Version || len(a.Owners) != int(c.partitionCount) {
+		return ErrPlanOutdated
+	}
+	partitions := make(map[int]*Member, len(a.Owners))
+	loads := make(map[string]float64)
+	for partID, owner := range a.Owners {
+		if owner == nil {
+			return ErrPlanOutdated
+		}
+		m, ok := c.members[owner.String()]
+		if !ok {
+			return ErrPlanOutdated
+		}
+		partitions[partID] = m
+		loads[owner.String()]++
+	}
+
+	previous, previousLoads := c.partitions, c.loads
+	c.partitions = partitions
+	c.loads = loads
+	// The partitions are already in place, a timeout would only lose the backups.
+	replicas, err := c.replicaTable(time.Time{})
+	if err != nil {
+		c.partitions, c.loads = previous, previousLoads
+		return err
+	}
+	// Like a distribution, the new table supersedes the failover preferences recorded for the previous one.
+	c.failover = nil
+	c.replicas = replicas
+	c.updateResidency(previous, partitions, c.clock().Now())
+	c.trackHandoffs(previous)
+	c.recordLayout(previous)
+	c.bumpVersion()
+	return nil
+}
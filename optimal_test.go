@@ -0,0 +1,194 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestOptimalDistributor(t *testing.T) {
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c := New(members, cfg)
+	c.Add(testMember("node8.olric"))
+
+	a, err := OptimalDistributor{}.Plan(c)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	loads := make(map[string]int)
+	for _, owner := range a.Owners {
+		loads[owner.String()]++
+	}
+	for _, member := range c.GetMembers() {
+		if load := loads[member.String()]; load < 30 || load > 31 {
+			t.Fatalf("%s owns %d partitions, expected 30 or 31", member, load)
+		}
+	}
+	if len(a.Moves) != int(a.Cost) {
+		t.Fatalf("Expected the cost to be the number of moves, Got: %g and %d", a.Cost, len(a.Moves))
+	}
+
+	if err := c.ApplyAssignment(a); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	for partID, owner := range a.Owners {
+		if c.GetPartitionOwner(partID).String() != owner.String() {
+			t.Fatalf("Partition %d: expected %s, Got: %s", partID, owner, c.GetPartitionOwner(partID))
+		}
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if err := c.ApplyAssignment(a); err != ErrPlanOutdated {
+		t.Fatalf("Expected ErrPlanOutdated, Got: %v", err)
+	}
+}
+
+func TestApplyAssignmentReplicaStrategy(t *testing.T) {
+	c := New(testMembers(4), newConfigWith(271))
+	a, err := OptimalDistributor{}.Plan(c)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	owners := c.owners()
+	version := c.Version()
+	c.config.ReplicaStrategy = ownerStrategy{}
+	if err := c.ApplyAssignment(a); err != ErrInvalidDistribution {
+		t.Fatalf("Expected ErrInvalidDistribution, Got: %v", err)
+	}
+	if c.Version() != version {
+		t.Fatalf("Expected the version to stay %d, Got: %d", version, c.Version())
+	}
+	for partID, owner := range c.owners() {
+		if owner != owners[partID] {
+			t.Fatalf("Expected the partition table to stay as it was")
+		}
+	}
+}
+
+func TestApplyAssignmentFailover(t *testing.T) {
+	cfg := newConfigWith(271)
+	cfg.BackupCount = 1
+	cfg.FailoverToBackup = true
+	c := New(testMembers(4), cfg)
+	a, err := OptimalDistributor{}.Plan(c)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	c.prepareFailover("node0.olric")
+	if len(c.failover) == 0 {
+		t.Fatalf("Expected failover preferences")
+	}
+	if err := c.ApplyAssignment(a); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if len(c.failover) != 0 {
+		t.Fatalf("Expected no failover preferences, Got: %v", c.failover)
+	}
+}
+
+func TestOptimalDistributorMinimalMoves(t *testing.T) {
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c := New(members, cfg)
+	c.Add(testMember("node8.olric"))
+	current := c.LoadDistribution()
+
+	// 271 partitions on 9 members: one member owns 31, the others 30. Every member keeps as many of its
+	// partitions as it may own, and one of those with more than 30 keeps 31.
+	a, err := OptimalDistributor{}.Plan(c)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	var kept int
+	var over bool
+	for _, member := range c.GetMembers() {
+		kept += int(math.Min(current[member.String()], 30))
+		over = over || current[member.String()] > 30
+	}
+	if over {
+		kept++
+	}
+	if moves := len(a.Moves); moves != 271-kept {
+		t.Fatalf("Expected %d moves, Got: %d", 271-kept, moves)
+	}
+}
+
+func TestOptimalDistributorBruteForce(t *testing.T) {
+	cfg := newConfig()
+	cfg.PartitionCount = 7
+	cfg.Load = 3
+	members := []Member{testMember("node0.olric"), testMember("node1.olric"), testMember("node2.olric")}
+	c := New(members, cfg)
+	// A cost which prefers some members for some partitions, to exercise the augmenting paths.
+	cost := func(partID int, current, member Member) float64 {
+		h := c.hasher.Sum64([]byte(fmt.Sprintf("%d-%s", partID, member)))
+		return float64(h % 10)
+	}
+	a, err := OptimalDistributor{Cost: cost}.Plan(c)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	// Balanced: two members own two partitions, one owns three.
+	best := math.Inf(1)
+	owners := make([]int, 7)
+	var search func(partID int)
+	search = func(partID int) {
+		if partID == 7 {
+			loads := make([]int, 3)
+			var total float64
+			for p, i := range owners {
+				loads[i]++
+				total += cost(p, nil, members[i])
+			}
+			for _, load := range loads {
+				if load < 2 || load > 3 {
+					return
+				}
+			}
+			best = math.Min(best, total)
+			return
+		}
+		for i := range members {
+			owners[partID] = i
+			search(partID + 1)
+		}
+	}
+	search(0)
+	if math.Abs(a.Cost-best) > 1e-9 {
+		t.Fatalf("Expected the optimal cost %g, Got: %g", best, a.Cost)
+	}
+}
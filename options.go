@@ -0,0 +1,338 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// Option configures a Consistent instance created by NewRing.
+type Option func(*ringOptions)
+
+type ringOptions struct {
+	config  Config
+	members []Member
+}
+
+// defaultHasher implements Hasher with 64-bit FNV-1a.
+type defaultHasher struct{}
+
+func (defaultHasher) Sum64(data []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(data)
+	return h.Sum64()
+}
+
+// WithMembers adds the given members to the consistent hash ring before the initial distribution.
+func WithMembers(members ...Member) Option {
+	return func(o *ringOptions) {
+		o.members = append(o.members, members...)
+	}
+}
+
+// WithPartitionCount sets Config.PartitionCount.
+func WithPartitionCount(count int) Option {
+	return func(o *ringOptions) {
+		o.config.PartitionCount = count
+	}
+}
+
+// WithReplicationFactor sets Config.ReplicationFactor.
+func WithReplicationFactor(factor int) Option {
+	return func(o *ringOptions) {
+		o.config.ReplicationFactor = factor
+	}
+}
+
+// WithAutoReplicationFactor sets Config.AutoReplicationFactor.
+func WithAutoReplicationFactor(total, min, max int) Option {
+	return func(o *ringOptions) {
+		o.config.AutoReplicationFactor = AutoReplicationFactor{TotalVirtualNodes: total, Min: min, Max: max}
+	}
+}
+
+// WithLoadFactor sets Config.Load.
+func WithLoadFactor(load float64) Option {
+	return func(o *ringOptions) {
+		o.config.Load = load
+	}
+}
+
+// WithBackupCount sets Config.BackupCount.
+func WithBackupCount(count int) Option {
+	return func(o *ringOptions) {
+		o.config.BackupCount = count
+	}
+}
+
+// WithBackupDirection sets Config.BackupDirection.
+func WithBackupDirection(direction BackupDirection) Option {
+	return func(o *ringOptions) {
+		o.config.BackupDirection = direction
+	}
+}
+
+// WithSkipBackup sets Config.SkipBackup.
+func WithSkipBackup(skip func(member Member) bool) Option {
+	return func(o *ringOptions) {
+		o.config.SkipBackup = skip
+	}
+}
+
+// WithMinBackupZones sets Config.MinBackupZones.
+func WithMinBackupZones(zones int) Option {
+	return func(o *ringOptions) {
+		o.config.MinBackupZones = zones
+	}
+}
+
+// WithReplicaStrategy sets Config.ReplicaStrategy.
+func WithReplicaStrategy(strategy ReplicaStrategy) Option {
+	return func(o *ringOptions) {
+		o.config.ReplicaStrategy = strategy
+	}
+}
+
+// WithAutoSave sets Config.AutoSavePath.
+func WithAutoSave(path string) Option {
+	return func(o *ringOptions) {
+		o.config.AutoSavePath = path
+	}
+}
+
+// WithExpectedMembers sets Config.ExpectedMembers.
+func WithExpectedMembers(count int) Option {
+	return func(o *ringOptions) {
+		o.config.ExpectedMembers = count
+	}
+}
+
+// WithKeyCacheSize sets Config.KeyCacheSize.
+func WithKeyCacheSize(size int) Option {
+	return func(o *ringOptions) {
+		o.config.KeyCacheSize = size
+	}
+}
+
+// WithDistributionDebounce sets Config.DistributionDebounce.
+func WithDistributionDebounce(d time.Duration) Option {
+	return func(o *ringOptions) {
+		o.config.DistributionDebounce = d
+	}
+}
+
+// WithAsyncDistribution sets Config.AsyncDistribution.
+func WithAsyncDistribution() Option {
+	return func(o *ringOptions) {
+		o.config.AsyncDistribution = true
+	}
+}
+
+// WithPartitionKeyEncoding sets Config.PartitionKeyEncoding.
+func WithPartitionKeyEncoding(encoding PartitionKeyEncoding) Option {
+	return func(o *ringOptions) {
+		o.config.PartitionKeyEncoding = encoding
+	}
+}
+
+// WithKeyExtractor sets Config.KeyExtractor.
+func WithKeyExtractor(extractor KeyExtractor) Option {
+	return func(o *ringOptions) {
+		o.config.KeyExtractor = extractor
+	}
+}
+
+// WithNormalizeName sets Config.NormalizeName.
+func WithNormalizeName(normalize func(name string) string) Option {
+	return func(o *ringOptions) {
+		o.config.NormalizeName = normalize
+	}
+}
+
+// WithOnDuplicateAdd sets Config.OnDuplicateAdd.
+func WithOnDuplicateAdd(policy DuplicatePolicy) Option {
+	return func(o *ringOptions) {
+		o.config.OnDuplicateAdd = policy
+	}
+}
+
+// WithClassLoads sets Config.ClassLoads and Config.ClassLabel.
+func WithClassLoads(label string, loads map[string]float64) Option {
+	return func(o *ringOptions) {
+		o.config.ClassLabel = label
+		o.config.ClassLoads = loads
+	}
+}
+
+// WithRelaxedLoadLimit sets Config.RelaxedLoadLimit.
+func WithRelaxedLoadLimit(limit float64) Option {
+	return func(o *ringOptions) {
+		o.config.RelaxedLoadLimit = limit
+	}
+}
+
+// WithDistributor sets Config.Distributor.
+func WithDistributor(d Distributor) Option {
+	return func(o *ringOptions) {
+		o.config.Distributor = d
+	}
+}
+
+// WithStrategy sets Config.Strategy.
+func WithStrategy(strategy AssignmentStrategy) Option {
+	return func(o *ringOptions) {
+		o.config.Strategy = strategy
+	}
+}
+
+// WithStickiness sets Config.Stickiness.
+func WithStickiness(stickiness float64) Option {
+	return func(o *ringOptions) {
+		o.config.Stickiness = stickiness
+	}
+}
+
+// WithMinResidency sets Config.MinResidency.
+func WithMinResidency(d time.Duration) Option {
+	return func(o *ringOptions) {
+		o.config.MinResidency = d
+	}
+}
+
+// WithWarmupDuration sets Config.WarmupDuration.
+func WithWarmupDuration(d time.Duration) Option {
+	return func(o *ringOptions) {
+		o.config.WarmupDuration = d
+	}
+}
+
+// WithReadWriteSplit sets Config.ReadWriteSplit.
+func WithReadWriteSplit() Option {
+	return func(o *ringOptions) {
+		o.config.ReadWriteSplit = true
+	}
+}
+
+// WithDeltaHistory sets Config.DeltaHistory.
+func WithDeltaHistory(size int) Option {
+	return func(o *ringOptions) {
+		o.config.DeltaHistory = size
+	}
+}
+
+// WithHistorySize sets Config.HistorySize.
+func WithHistorySize(size int) Option {
+	return func(o *ringOptions) {
+		o.config.HistorySize = size
+	}
+}
+
+// WithRandomCandidates sets Config.RandomCandidates and Config.RandomSeed.
+func WithRandomCandidates(count int, seed uint64) Option {
+	return func(o *ringOptions) {
+		o.config.RandomCandidates = count
+		o.config.RandomSeed = seed
+	}
+}
+
+// WithFailoverToBackup sets Config.FailoverToBackup.
+func WithFailoverToBackup() Option {
+	return func(o *ringOptions) {
+		o.config.FailoverToBackup = true
+	}
+}
+
+// WithTinyClusterFallback sets Config.TinyClusterFallback.
+func WithTinyClusterFallback() Option {
+	return func(o *ringOptions) {
+		o.config.TinyClusterFallback = true
+	}
+}
+
+// WithPanicFree sets Config.PanicFree.
+func WithPanicFree() Option {
+	return func(o *ringOptions) {
+		o.config.PanicFree = true
+	}
+}
+
+// WithKeySampler sets Config.KeySampler and Config.SampleEvery.
+func WithKeySampler(sampler KeySampler, every int) Option {
+	return func(o *ringOptions) {
+		o.config.KeySampler = sampler
+		o.config.SampleEvery = every
+	}
+}
+
+// WithAssignmentSink sets Config.AssignmentSink.
+func WithAssignmentSink(sink AssignmentSink) Option {
+	return func(o *ringOptions) {
+		o.config.AssignmentSink = sink
+	}
+}
+
+// WithClock sets Config.Clock.
+func WithClock(clock Clock) Option {
+	return func(o *ringOptions) {
+		o.config.Clock = clock
+	}
+}
+
+// WithLogger sets Config.Logger.
+func WithLogger(logger Logger) Option {
+	return func(o *ringOptions) {
+		o.config.Logger = logger
+	}
+}
+
+// WithTestingKnobs sets Config.TestingKnobs.
+func WithTestingKnobs(knobs *TestingKnobs) Option {
+	return func(o *ringOptions) {
+		o.config.TestingKnobs = knobs
+	}
+}
+
+// WithHasher sets Config.Hasher.
+func WithHasher(hasher Hasher) Option {
+	return func(o *ringOptions) {
+		o.config.Hasher = hasher
+	}
+}
+
+// WithDefaultHasher sets Config.Hasher to a 64-bit FNV-1a implementation from the standard library.
+func WithDefaultHasher() Option {
+	return WithHasher(defaultHasher{})
+}
+
+// NewRing creates and returns a new Consistent object configured by the given options. Configuration values
+// which are not set by an option take the same defaults as New. A Hasher must be set by WithHasher or
+// WithDefaultHasher.
+func NewRing(opts ...Option) *Consistent {
+	var o ringOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return New(o.members, o.config)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNewRing(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c := NewRing(
+		WithMembers(members...),
+		WithPartitionCount(23),
+		WithReplicationFactor(10),
+		WithLoadFactor(1.5),
+		WithHasher(hasher{}),
+	)
+	if len(c.GetMembers()) != len(members) {
+		t.Fatalf("inserted member count is different")
+	}
+	if c.config.PartitionCount != 23 || c.config.ReplicationFactor != 10 || c.config.Load != 1.5 {
+		t.Fatalf("Unexpected config: %+v", c.config)
+	}
+	if len(c.sortedSet) != 10*len(members) {
+		t.Fatalf("Expected %d virtual nodes, Got: %d", 10*len(members), len(c.sortedSet))
+	}
+}
+
+func TestNewRingDefaults(t *testing.T) {
+	c := NewRing(WithDefaultHasher())
+	if c.config.PartitionCount != DefaultPartitionCount ||
+		c.config.ReplicationFactor != DefaultReplicationFactor ||
+		c.config.Load != DefaultLoad {
+		t.Fatalf("Unexpected config: %+v", c.config)
+	}
+	c.Add(testMember("node1.olric"))
+	if c.LocateKey([]byte("Olric")) == nil {
+		t.Fatalf("This shouldn't be nil")
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package otelconsistent reports the measurements of a consistent hash ring to OpenTelemetry.
+//
+//	mp, err := otelconsistent.New(otel.GetMeterProvider(), otel.GetTracerProvider())
+//	if err != nil {
+//		...
+//	}
+//	cfg.MeterProvider = mp
+//
+// It records the following instruments, attributed with the version of the partition table:
+//
+//	consistent.distributions          counter of distribution runs, with an error attribute for failed ones
+//	consistent.relocations            counter of partitions whose owner changed
+//	consistent.distribution.duration  histogram of distribution durations in seconds
+//	consistent.lookup.duration        histogram of LocateKey latencies in seconds
+//
+// Every distribution is also recorded as a "consistent.distribute" span if a TracerProvider is given.
+package otelconsistent
+
+import (
+	"context"
+	"time"
+
+	"github.com/buraksezer/consistent"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the meter and the tracer.
+const ScopeName = "github.com/buraksezer/consistent"
+
+// Attribute keys.
+const (
+	VersionKey = attribute.Key("consistent.version")
+	MembersKey = attribute.Key("consistent.members")
+	ErrorKey   = attribute.Key("error")
+)
+
+// MeterProvider implements consistent.MeterProvider with OpenTelemetry.
+type MeterProvider struct {
+	tracer trace.Tracer
+
+	distributions        metric.Int64Counter
+	relocations          metric.Int64Counter
+	distributionDuration metric.Float64Histogram
+	lookupDuration       metric.Float64Histogram
+}
+
+var _ consistent.MeterProvider = (*MeterProvider)(nil)
+
+// New creates the instruments with mp. tp may be nil, no spans are recorded in that case.
+func New(mp metric.MeterProvider, tp trace.TracerProvider) (*MeterProvider, error) {
+	meter := mp.Meter(ScopeName)
+	m := &MeterProvider{}
+	if tp != nil {
+		m.tracer = tp.Tracer(ScopeName)
+	}
+
+	var err error
+	m.distributions, err = meter.Int64Counter("consistent.distributions",
+		metric.WithDescription("Number of partition distributions."))
+	if err != nil {
+		return nil, err
+	}
+	m.relocations, err = meter.Int64Counter("consistent.relocations",
+		metric.WithDescription("Number of partitions whose owner changed."))
+	if err != nil {
+		return nil, err
+	}
+	m.distributionDuration, err = meter.Float64Histogram("consistent.distribution.duration",
+		metric.WithDescription("Duration of partition distributions."), metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	m.lookupDuration, err = meter.Float64Histogram("consistent.lookup.duration",
+		metric.WithDescription("Latency of key lookups."), metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RecordDistribution implements consistent.MeterProvider.
+func (m *MeterProvider) RecordDistribution(d consistent.DistributionMetrics) {
+	ctx := context.Background()
+	attrs := []attribute.KeyValue{VersionKey.Int64(int64(d.Version))}
+	failed := d.Err != nil
+	m.distributions.Add(ctx, 1, metric.WithAttributes(append(attrs, ErrorKey.Bool(failed))...))
+	m.relocations.Add(ctx, int64(d.Relocated), metric.WithAttributes(attrs...))
+	m.distributionDuration.Record(ctx, d.Duration.Seconds(), metric.WithAttributes(attrs...))
+
+	if m.tracer == nil {
+		return
+	}
+	_, span := m.tracer.Start(ctx, "consistent.distribute", trace.WithTimestamp(d.Start),
+		trace.WithAttributes(append(attrs, MembersKey.Int(d.Members), attribute.Int("consistent.relocated", d.Relocated))...))
+	if failed {
+		span.RecordError(d.Err)
+		span.SetStatus(codes.Error, d.Err.Error())
+	}
+	span.End(trace.WithTimestamp(d.Start.Add(d.Duration)))
+}
+
+// RecordLookup implements consistent.MeterProvider.
+func (m *MeterProvider) RecordLookup(d time.Duration) {
+	m.lookupDuration.Record(context.Background(), d.Seconds())
+}
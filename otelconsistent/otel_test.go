@@ -0,0 +1,116 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package otelconsistent
+
+import (
+	"context"
+	"hash/fnv"
+	"testing"
+
+	"github.com/buraksezer/consistent"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type hasher struct{}
+
+func (hs hasher) Sum64(data []byte) uint64 {
+	h := fnv.New64()
+	_, _ = h.Write(data)
+	return h.Sum64()
+}
+
+type member string
+
+func (m member) String() string {
+	return string(m)
+}
+
+func TestMeterProvider(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	spans := tracetest.NewSpanRecorder()
+	mp, err := New(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+		sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	c := consistent.New([]consistent.Member{member("node0"), member("node1")}, consistent.Config{
+		PartitionCount:    23,
+		ReplicationFactor: 20,
+		Load:              1.25,
+		Hasher:            hasher{},
+		MeterProvider:     mp,
+	})
+	c.Add(member("node2"))
+	c.LocateKey([]byte("Olric"))
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	got := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			got[m.Name] = m.Data
+		}
+	}
+
+	var distributions int64
+	for _, dp := range got["consistent.distributions"].(metricdata.Sum[int64]).DataPoints {
+		distributions += dp.Value
+	}
+	if distributions != 2 {
+		t.Fatalf("Expected 2 distributions, Got: %d", distributions)
+	}
+	var relocations int64
+	for _, dp := range got["consistent.relocations"].(metricdata.Sum[int64]).DataPoints {
+		relocations += dp.Value
+	}
+	if relocations < 23 {
+		t.Fatalf("Expected at least 23 relocations, Got: %d", relocations)
+	}
+	if len(got["consistent.distribution.duration"].(metricdata.Histogram[float64]).DataPoints) != 2 {
+		t.Fatalf("Expected a duration per version")
+	}
+	if got["consistent.lookup.duration"].(metricdata.Histogram[float64]).DataPoints[0].Count != 1 {
+		t.Fatalf("Expected a single lookup")
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 2 || ended[0].Name() != "consistent.distribute" {
+		t.Fatalf("Expected 2 distribution spans, Got: %d", len(ended))
+	}
+}
+
+func TestMeterProviderWithoutTracer(t *testing.T) {
+	mp, err := New(sdkmetric.NewMeterProvider(), nil)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	mp.RecordDistribution(consistent.DistributionMetrics{Version: 1})
+	mp.RecordLookup(0)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"sort"
+)
+
+// SetOverflowPriority sets the overflow priority of the member, see Config.RelaxedLoadLimit. When there is not
+// enough room under the load bound, the relaxed load factors apply to the members with the highest priority
+// first, then to those with the next lower priority as well, and so on. Only if that's not enough either, they
+// apply to all members, as without priorities. Members with a priority of zero, the default, are never
+// overloaded before the others. Like SetMaxLoad, the priority may be set before the member joins and stays in place
+// until it's reset. It takes effect at the next distribution and isn't part of snapshots.
+func (c *Consistent) SetOverflowPriority(name string, priority int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name = c.memberName(name)
+	if priority <= 0 {
+		delete(c.overflow, name)
+		return
+	}
+	c.overflow[name] = priority
+}
+
+// OverflowPriority returns the priority set by SetOverflowPriority for the member, or zero if there is none.
+func (c *Consistent) OverflowPriority(name string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.overflow[c.memberName(name)]
+}
+
+// overflowTiers returns the distinct overflow priorities of the members on the ring in descending order. It's not
+// thread-safe.
+func (c *Consistent) overflowTiers() []int {
+	seen := make(map[int]struct{})
+	var tiers []int
+	for name, priority := range c.overflow {
+		if _, ok := c.members[name]; !ok {
+			continue
+		}
+		if _, ok := seen[priority]; !ok {
+			seen[priority] = struct{}{}
+			tiers = append(tiers, priority)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(tiers)))
+	return tiers
+}
+
+// overflowing reports whether the relaxed load factors apply to the member in the current distribution. It's not
+// thread-safe.
+func (c *Consistent) overflowing(member Member) bool {
+	return c.overflowTier == 0 || c.overflow[member.String()] >= c.overflowTier
+}
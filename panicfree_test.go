@@ -0,0 +1,154 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+// TestPanicFree calls the public API with edge case arguments and configurations and fails if anything panics.
+func TestPanicFree(t *testing.T) {
+	try := func(name string, f func()) {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Errorf("%s panicked: %v", name, r)
+			}
+		}()
+		f()
+	}
+
+	for _, n := range []int{0, 1, 3} {
+		cfg := newConfig()
+		cfg.PanicFree = true
+		cfg.BackupCount = 2
+		c := New(nil, cfg)
+		for i := 0; i < n; i++ {
+			c.Add(testMember(fmt.Sprintf("node%d.olric", i)))
+		}
+		prefix := fmt.Sprintf("%d members: ", n)
+		calls := map[string]func(){
+			"AverageLoad":             func() { c.AverageLoad() },
+			"BackupLoadDistribution":  func() { c.BackupLoadDistribution() },
+			"GetClosestN":             func() { _, _ = c.GetClosestN([]byte("Olric"), -1) },
+			"GetClosestNForPartition": func() { _, _ = c.GetClosestNForPartition(-1, 1) },
+			"GetNForKey":              func() { _, _ = c.GetNForKey([]byte("Olric"), -1) },
+			"GetPartitionOwner":       func() { c.GetPartitionOwner(-1) },
+			"GetPartitionOwnerAndBackups": func() {
+				c.GetPartitionOwnerAndBackups(-1)
+				c.GetPartitionOwnerAndBackups(cfg.PartitionCount)
+			},
+			"ReadOwners":        func() { c.ReadOwners(-1) },
+			"EstimateKeyCounts": func() { c.EstimateKeyCounts(-1) },
+			"PartitionGroups": func() {
+				_, _ = c.PartitionGroups(-1)
+				_, _ = c.PartitionGroups(cfg.PartitionCount + 1)
+			},
+			"Diff":            func() { c.Diff(nil) },
+			"Equal":           func() { c.Equal(nil) },
+			"ApplyAssignment": func() { _ = c.ApplyAssignment(nil) },
+			"ApplyAssignmentNil": func() {
+				_ = c.ApplyAssignment(&Assignment{Version: c.Version(), Owners: make([]Member, cfg.PartitionCount)})
+			},
+			"Subset":             func() { _, _ = c.Subset(nil) },
+			"Stats":              func() { c.Stats() },
+			"String":             func() { _ = c.String() + c.GoString() },
+			"Validate":           func() { _ = c.Validate() },
+			"Fingerprint":        func() { c.Fingerprint() },
+			"RoutingTable":       func() { c.RoutingTable() },
+			"DeltaSince":         func() { _, _ = c.DeltaSince(math.MaxUint64) },
+			"OwnersInRange":      func() { c.OwnersInRange(math.MaxUint64, 0) },
+			"OptimalDistributor": func() { _, _ = OptimalDistributor{}.Plan(c) },
+			"Decommission":       func() { _, _ = c.Decommission("node0.olric") },
+			"FromSnapshot":       func() { _, _ = FromSnapshot(Snapshot{}) },
+			"WaitForVersion": func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				_ = c.WaitForVersion(ctx, math.MaxUint64)
+			},
+			"SetPartitionAffinity": func() { _ = c.SetPartitionAffinity(-1, nil) },
+			"CompleteHandoff":      func() { _ = c.CompleteHandoff(-1) },
+			"LocateKeyAvoiding":    func() { c.LocateKeyAvoiding([]byte("Olric"), func(Member) bool { return true }) },
+		}
+		for name, f := range calls {
+			try(prefix+name, f)
+		}
+		try(prefix+"membership", func() {
+			_ = c.SetMaxLoad("node0.olric", 1)
+			_ = c.Drain("node1.olric")
+			c.AddWithWarmup(testMember("node9.olric"), -1)
+			c.AddStandby(testMember("standby.olric"))
+			_ = c.PromoteStandby("none")
+			_ = c.CompleteDecommission("node0.olric")
+			c.SetMembers(nil)
+		})
+	}
+
+	configs := []Config{
+		{},
+		{PartitionCount: -1, ReplicationFactor: -1, Load: -1, BackupCount: -1, KeyCacheSize: -1},
+		{PartitionCount: 5, Load: 0.1},
+		{ExpectedMembers: -1, DeltaHistory: -1, RandomCandidates: -1, WarmupSteps: -1},
+	}
+	for i, cfg := range configs {
+		cfg.PanicFree = true
+		try(fmt.Sprintf("config %d", i), func() {
+			c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, cfg)
+			c.LocateKey([]byte("Olric"))
+			c.Add(testMember("node2.olric"))
+			c.Remove("node0.olric")
+			_ = c.Flush()
+		})
+	}
+}
+
+func TestPanicFreeNotEnoughRoom(t *testing.T) {
+	cfg := newConfig()
+	cfg.PanicFree = true
+	cfg.Load = 0.5
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, cfg)
+	if !c.Pending() {
+		t.Fatalf("Expected a pending distribution")
+	}
+	if owner := c.LocateKey([]byte("Olric")); owner != nil {
+		t.Fatalf("Expected nil, Got: %v", owner)
+	}
+	c.Add(testMember("node2.olric"))
+	var distErr *DistributionError
+	if err := c.Flush(); !errors.As(err, &distErr) {
+		t.Fatalf("Expected a *DistributionError, Got: %v", err)
+	}
+
+	// A nil hasher takes the default one.
+	cfg = newConfig()
+	cfg.PanicFree = true
+	cfg.Hasher = nil
+	c = New([]Member{testMember("node0.olric")}, cfg)
+	if c.LocateKey([]byte("Olric")) == nil {
+		t.Fatalf("Expected an owner")
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"encoding/binary"
+	"strconv"
+)
+
+// PartitionKeyEncoding selects the bytes which are hashed to put a partition on the ring, see
+// Config.PartitionKeyEncoding. Ports of the algorithm to other languages must use the same encoding to
+// reproduce the placement exactly.
+type PartitionKeyEncoding int
+
+const (
+	// LittleEndianKey hashes the partition ID as an 8-byte little-endian unsigned integer. It's the default.
+	LittleEndianKey PartitionKeyEncoding = iota
+
+	// BigEndianKey hashes the partition ID as an 8-byte big-endian unsigned integer.
+	BigEndianKey
+
+	// DecimalKey hashes the decimal representation of the partition ID, e.g. "42".
+	DecimalKey
+)
+
+// partitionKey returns the bytes which are hashed for the point of the partition. bs is a scratch buffer of 8
+// bytes, the result may share it.
+func (c *Consistent) partitionKey(partID int, bs []byte) []byte {
+	switch c.config.PartitionKeyEncoding {
+	case BigEndianKey:
+		binary.BigEndian.PutUint64(bs, uint64(partID))
+		return bs
+	case DecimalKey:
+		return strconv.AppendInt(bs[:0], int64(partID), 10)
+	default:
+		binary.LittleEndian.PutUint64(bs, uint64(partID))
+		return bs
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+func TestPartitionKeyEncoding(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	layouts := make(map[PartitionKeyEncoding]*Consistent)
+	for _, encoding := range []PartitionKeyEncoding{LittleEndianKey, BigEndianKey, DecimalKey} {
+		cfg := newConfig()
+		cfg.PartitionKeyEncoding = encoding
+		c := New(members, cfg)
+		layouts[encoding] = c
+
+		bs := make([]byte, 8)
+		for partID := 0; partID < cfg.PartitionCount; partID++ {
+			var key []byte
+			switch encoding {
+			case LittleEndianKey:
+				key = make([]byte, 8)
+				binary.LittleEndian.PutUint64(key, uint64(partID))
+			case BigEndianKey:
+				key = make([]byte, 8)
+				binary.BigEndian.PutUint64(key, uint64(partID))
+			case DecimalKey:
+				key = []byte(fmt.Sprintf("%d", partID))
+			}
+			if point := c.partitionPoint(partID, bs); point != (hasher{}).Sum64(key) {
+				t.Fatalf("Encoding %d, partition %d: expected the hash of %v", encoding, partID, key)
+			}
+		}
+		if err := c.Validate(); err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+	}
+	if !layouts[LittleEndianKey].Equal(New(members, newConfig())) {
+		t.Fatalf("LittleEndianKey must be the default")
+	}
+	if layouts[LittleEndianKey].Equal(layouts[BigEndianKey]) || layouts[LittleEndianKey].Equal(layouts[DecimalKey]) {
+		t.Fatalf("Expected different layouts")
+	}
+
+	// Snapshots keep the encoding.
+	restored, err := FromSnapshot(layouts[DecimalKey].Snapshot())
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if !restored.Equal(layouts[DecimalKey]) || restored.config.PartitionKeyEncoding != DecimalKey {
+		t.Fatalf("Expected the layout of DecimalKey")
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// SyncState is what a peer reports about its copy of the layout: the version of its partition table and its
+// Fingerprint. Peers which only keep a RoutingTable can't compute the fingerprint and leave it zero.
+type SyncState struct {
+	Version     uint64
+	Fingerprint uint64
+}
+
+// SyncAction tells what a peer needs to catch up with the ring, see Sync.
+type SyncAction int
+
+const (
+	// InSync means the peer has the current layout.
+	InSync SyncAction = iota
+
+	// SendDelta means the peer can apply SyncResponse.Delta to its copy.
+	SendDelta
+
+	// SendSnapshot means the peer must replace its copy with SyncResponse.Snapshot.
+	SendSnapshot
+
+	// RetryLater means the ring has a pending distribution which failed, SyncResponse.Err tells why. The peer
+	// keeps its copy and reports its state again later.
+	RetryLater
+)
+
+// String returns the name of the action.
+func (a SyncAction) String() string {
+	switch a {
+	case SendDelta:
+		return "delta"
+	case SendSnapshot:
+		return "snapshot"
+	case RetryLater:
+		return "retry later"
+	default:
+		return "in sync"
+	}
+}
+
+// SyncResponse is the answer to a SyncState, to send back over the transport of the application.
+type SyncResponse struct {
+	// Action tells the peer what to do.
+	Action SyncAction
+
+	// State is the state the peer has after following the action.
+	State SyncState
+
+	// Delta contains the moves since the version of the peer if Action is SendDelta.
+	Delta LayoutDelta
+
+	// Snapshot is the state of the ring if Action is SendSnapshot.
+	Snapshot *Snapshot
+
+	// Err is the error of the pending distribution if Action is RetryLater.
+	Err error
+}
+
+// SyncState returns the state of the ring for peers to compare with theirs.
+func (c *Consistent) SyncState() SyncState {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return SyncState{Version: c.version, Fingerprint: c.fingerprint()}
+}
+
+// Sync tells what the peer with the given state needs to catch up with the ring, and carries it: nothing if the
+// versions and fingerprints are equal, the delta since the version of the peer if it's older and still in the
+// history of Config.DeltaHistory, otherwise a snapshot. Versions are only comparable between the ring and peers
+// whose copies came from it. A peer whose copy diverged at its version, e.g. after a restart, has a different
+// fingerprint and gets a snapshot; a zero fingerprint skips that check. After applying a delta, a peer which
+// computes fingerprints can compare its own to State.Fingerprint, and report its state again if they differ.
+// A pending distribution is run first; if it fails, the answer is RetryLater rather than a stale table.
+func (c *Consistent) Sync(peer SyncState) SyncResponse {
+	// Peers must not catch up with a stale partition table, run a pending distribution first.
+	err := c.Flush()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	res := SyncResponse{State: SyncState{Version: c.version, Fingerprint: c.fingerprint()}}
+	if err != nil {
+		res.Action = RetryLater
+		res.State = peer
+		res.Err = err
+		return res
+	}
+	if peer.Version == c.version && (peer.Fingerprint == 0 || peer.Fingerprint == res.State.Fingerprint) {
+		res.Action = InSync
+		return res
+	}
+	if peer.Version < c.version {
+		if d, err := c.deltaSince(peer.Version); err == nil {
+			res.Action = SendDelta
+			res.Delta = d
+			return res
+		}
+	}
+	s := c.snapshot()
+	res.Action = SendSnapshot
+	res.Snapshot = &s
+	return res
+}
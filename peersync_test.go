@@ -0,0 +1,88 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConsistentSync(t *testing.T) {
+	cfg := newConfig()
+	cfg.DeltaHistory = 4
+	c := New(testMembers(4), cfg)
+
+	peer, err := FromSnapshot(c.Snapshot())
+	if err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if res := c.Sync(peer.SyncState()); res.Action != InSync {
+		t.Fatalf("Expected in sync, got: %s", res.Action)
+	}
+
+	table := c.RoutingTable()
+	c.Add(testMember("node4.olric"))
+	res := c.Sync(SyncState{Version: table.Version})
+	if res.Action != SendDelta {
+		t.Fatalf("Expected delta, got: %s", res.Action)
+	}
+	table, err = table.Apply(res.Delta.RoutingDelta())
+	if err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if !reflect.DeepEqual(table, c.RoutingTable()) {
+		t.Fatalf("Expected the current routing table")
+	}
+	if res.State != c.SyncState() {
+		t.Fatalf("Expected the current state, got: %+v", res.State)
+	}
+
+	// A peer which claims the current version with a different layout gets a snapshot.
+	res = c.Sync(SyncState{Version: c.Version(), Fingerprint: peer.Fingerprint()})
+	if res.Action != SendSnapshot || res.Snapshot == nil {
+		t.Fatalf("Expected snapshot, got: %s", res.Action)
+	}
+	peer, err = FromSnapshot(*res.Snapshot)
+	if err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if res := c.Sync(peer.SyncState()); res.Action != InSync {
+		t.Fatalf("Expected in sync, got: %s", res.Action)
+	}
+}
+
+func TestConsistentSyncWithoutHistory(t *testing.T) {
+	c := New(testMembers(4), newConfig())
+	version := c.Version()
+	c.Add(testMember("node4.olric"))
+	if res := c.Sync(SyncState{Version: version}); res.Action != SendSnapshot {
+		t.Fatalf("Expected snapshot, got: %s", res.Action)
+	}
+	if res := c.Sync(SyncState{Version: c.Version() + 1}); res.Action != SendSnapshot {
+		t.Fatalf("Expected snapshot, got: %s", res.Action)
+	}
+	if res := c.Sync(SyncState{Version: c.Version()}); res.Action != InSync {
+		t.Fatalf("Expected in sync, got: %s", res.Action)
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// snapshotMagic starts every file written by SaveToFile, followed by the version of the format.
+const snapshotMagic = "CHRS\x01"
+
+// nameMember is the member LoadFromFile, AddFromReader and AddFromJSON create if there is no decode function.
+type nameMember string
+
+func (m nameMember) String() string {
+	return string(m)
+}
+
+// SaveToFile writes a snapshot of the ring to the file in a compact binary format with a checksum, e.g. for a
+// single-node coordinator which restores its layout with LoadFromFile after a restart. The file is replaced
+// atomically: the snapshot is written to a temporary file in the same directory, synced and renamed. Members are
+// stored by name, and only the parts of the configuration which shape the layout are stored: PartitionCount,
+// ReplicationFactor, Load and BackupCount. A pending distribution is run first; if it fails, nothing is written
+// and the error is returned.
+func (c *Consistent) SaveToFile(path string) error {
+	s, err := c.flushedSnapshot()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, encodeSnapshot(s))
+}
+
+// LoadFromFile restores a ring from a file written by SaveToFile, like FromSnapshot. The configuration comes from
+// cfg, which must set the Hasher, except for the stored parts. decode converts the stored member names to
+// members, e.g. looking them up in the service discovery; if it's nil, the members are plain names. The error
+// wraps ErrInvalidSnapshot if the file is corrupt or doesn't match the hasher.
+func LoadFromFile(path string, cfg Config, decode func(name string) Member) (*Consistent, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := decodeSnapshot(data, cfg, decode)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	return FromSnapshot(s)
+}
+
+// autoSave writes the snapshot to Config.AutoSavePath after a version bump. Errors are logged at warn level. It's
+// not thread-safe.
+func (c *Consistent) autoSave() {
+	if c.config.AutoSavePath == "" || c.detached {
+		return
+	}
+	err := writeFileAtomic(c.config.AutoSavePath, encodeSnapshot(c.snapshot()))
+	if err != nil && c.config.Logger != nil {
+		c.config.Logger.Warn("saving the ring failed", "path", c.config.AutoSavePath, "error", err)
+	}
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
+
+// encodeSnapshot encodes the snapshot for SaveToFile. Members are referenced by their index in Members, zero
+// means no member.
+func encodeSnapshot(s Snapshot) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(snapshotMagic)
+	scratch := make([]byte, binary.MaxVarintLen64)
+	putUint := func(v uint64) {
+		buf.Write(scratch[:binary.PutUvarint(scratch, v)])
+	}
+	putString := func(v string) {
+		putUint(uint64(len(v)))
+		buf.WriteString(v)
+	}
+	index := make(map[string]uint64, len(s.Members))
+	for i, member := range s.Members {
+		index[member.String()] = uint64(i + 1)
+	}
+
+	putUint(uint64(s.Config.PartitionCount))
+	putUint(uint64(s.Config.ReplicationFactor))
+	putUint(math.Float64bits(s.Config.Load))
+	putUint(uint64(s.Config.BackupCount))
+	putUint(s.Version)
+	putUint(s.Collisions)
+	putUint(uint64(len(s.Members)))
+	for _, member := range s.Members {
+		putString(member.String())
+		hashes := s.VirtualNodes[member.String()]
+		putUint(uint64(len(hashes)))
+		for _, h := range hashes {
+			binary.BigEndian.PutUint64(scratch, h)
+			buf.Write(scratch[:8])
+		}
+	}
+	putUint(uint64(len(s.Salts)))
+	for h, salt := range s.Salts {
+		binary.BigEndian.PutUint64(scratch, h)
+		buf.Write(scratch[:8])
+		putUint(uint64(salt))
+	}
+	putUint(uint64(len(s.Partitions)))
+	for _, name := range s.Partitions {
+		putUint(index[name])
+	}
+	putUint(uint64(len(s.Backups)))
+	for _, replicas := range s.Backups {
+		putUint(uint64(len(replicas)))
+		for _, name := range replicas {
+			putUint(index[name])
+		}
+	}
+	putUint(uint64(len(s.MaxLoads)))
+	for name, n := range s.MaxLoads {
+		putString(name)
+		putUint(uint64(n))
+	}
+	putUint(uint64(len(s.Standbys)))
+	for _, member := range s.Standbys {
+		putString(member.String())
+	}
+	putUint(uint64(len(s.Draining)))
+	for _, name := range s.Draining {
+		putString(name)
+	}
+	binary.BigEndian.PutUint32(scratch, crc32.ChecksumIEEE(buf.Bytes()))
+	buf.Write(scratch[:4])
+	return buf.Bytes()
+}
+
+var errCorrupt = errors.New("corrupt snapshot file")
+
+// decodeSnapshot decodes a snapshot encoded by encodeSnapshot on top of cfg.
+func decodeSnapshot(data []byte, cfg Config, decode func(name string) Member) (Snapshot, error) {
+	if len(data) < len(snapshotMagic)+4 || string(data[:len(snapshotMagic)]) != snapshotMagic {
+		return Snapshot{}, errCorrupt
+	}
+	body := data[:len(data)-4]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(data)-4:]) {
+		return Snapshot{}, errors.New("snapshot file checksum mismatch")
+	}
+	r := bytes.NewReader(body[len(snapshotMagic):])
+	var err error
+	getUint := func() uint64 {
+		if err != nil {
+			return 0
+		}
+		var v uint64
+		v, err = binary.ReadUvarint(r)
+		return v
+	}
+	// getCount reads a length, which cannot exceed the remaining bytes.
+	getCount := func() int {
+		n := getUint()
+		if err == nil && n > uint64(r.Len()) {
+			err = errCorrupt
+			return 0
+		}
+		return int(n)
+	}
+	getHash := func() uint64 {
+		if err != nil {
+			return 0
+		}
+		var v uint64
+		err = binary.Read(r, binary.BigEndian, &v)
+		return v
+	}
+	getString := func() string {
+		b := make([]byte, getCount())
+		if err == nil {
+			_, err = r.Read(b)
+		}
+		return string(b)
+	}
+	if decode == nil {
+		decode = func(name string) Member {
+			return nameMember(name)
+		}
+	}
+
+	s := Snapshot{Config: cfg}
+	s.Config.PartitionCount = int(getUint())
+	s.Config.ReplicationFactor = int(getUint())
+	s.Config.Load = math.Float64frombits(getUint())
+	s.Config.BackupCount = int(getUint())
+	s.Version = getUint()
+	s.Collisions = getUint()
+	var names []string
+	s.VirtualNodes = make(map[string][]uint64)
+	for i, n := 0, getCount(); i < n && err == nil; i++ {
+		name := getString()
+		hashes := make([]uint64, getCount())
+		for j := range hashes {
+			hashes[j] = getHash()
+		}
+		names = append(names, name)
+		s.VirtualNodes[name] = hashes
+	}
+	member := func(idx uint64) string {
+		if idx > uint64(len(names)) {
+			err = errCorrupt
+			return ""
+		}
+		if idx == 0 {
+			return ""
+		}
+		return names[idx-1]
+	}
+	s.Salts = make(map[uint64]int)
+	for i, n := 0, getCount(); i < n && err == nil; i++ {
+		h := getHash()
+		s.Salts[h] = int(getUint())
+	}
+	if n := getCount(); n != 0 {
+		s.Partitions = make([]string, n)
+		for i := range s.Partitions {
+			s.Partitions[i] = member(getUint())
+		}
+	}
+	for i, n := 0, getCount(); i < n && err == nil; i++ {
+		replicas := make([]string, getCount())
+		for j := range replicas {
+			replicas[j] = member(getUint())
+		}
+		s.Backups = append(s.Backups, replicas)
+	}
+	s.MaxLoads = make(map[string]int)
+	for i, n := 0, getCount(); i < n && err == nil; i++ {
+		name := getString()
+		s.MaxLoads[name] = int(getUint())
+	}
+	for i, n := 0, getCount(); i < n && err == nil; i++ {
+		s.Standbys = append(s.Standbys, decode(getString()))
+	}
+	for i, n := 0, getCount(); i < n && err == nil; i++ {
+		s.Draining = append(s.Draining, getString())
+	}
+	if err == nil && r.Len() != 0 {
+		err = errCorrupt
+	}
+	if err != nil {
+		return Snapshot{}, err
+	}
+	for _, name := range names {
+		s.Members = append(s.Members, decode(name))
+	}
+	return s, nil
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSaveToFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "consistent")
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ring")
+
+	cfg := newConfigWith(271)
+	cfg.BackupCount = 1
+	c := New(testMembers(6), cfg)
+	if err := c.SetMaxLoad("node1.olric", 50); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if err := c.Drain("node2.olric"); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	c.AddStandby(testMember("node9.olric"))
+	if err := c.SaveToFile(path); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	restored, err := LoadFromFile(path, Config{Hasher: hasher{}}, func(name string) Member {
+		return testMember(name)
+	})
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if !reflect.DeepEqual(restored.Snapshot(), c.Snapshot()) {
+		t.Fatalf("Expected the restored ring to equal the saved one")
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatalf("Expected no temporary files, Got: %d files", len(files))
+	}
+
+	// Members are plain names without a decode function.
+	restored, err = LoadFromFile(path, Config{Hasher: hasher{}}, nil)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if restored.LocateKey([]byte("my-key")).String() != c.LocateKey([]byte("my-key")).String() {
+		t.Fatalf("Expected the same owner")
+	}
+}
+
+func TestLoadFromFileCorrupt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "consistent")
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ring")
+
+	c := New(testMembers(3), newConfig())
+	if err := c.SaveToFile(path); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	data, _ := ioutil.ReadFile(path)
+	for _, corrupt := range [][]byte{data[:len(data)-1], append([]byte("X"), data[1:]...), nil} {
+		if err := ioutil.WriteFile(path, corrupt, 0600); err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+		if _, err := LoadFromFile(path, Config{Hasher: hasher{}}, nil); !errors.Is(err, ErrInvalidSnapshot) {
+			t.Fatalf("Expected ErrInvalidSnapshot, Got: %v", err)
+		}
+	}
+	flipped := append([]byte(nil), data...)
+	flipped[len(snapshotMagic)+2] ^= 0xff
+	if err := ioutil.WriteFile(path, flipped, 0600); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if _, err := LoadFromFile(path, Config{Hasher: hasher{}}, nil); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Fatalf("Expected ErrInvalidSnapshot, Got: %v", err)
+	}
+	if _, err := LoadFromFile(filepath.Join(dir, "missing"), Config{Hasher: hasher{}}, nil); !os.IsNotExist(err) {
+		t.Fatalf("Expected a missing file, Got: %v", err)
+	}
+}
+
+func TestAutoSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "consistent")
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ring")
+
+	cfg := newConfig()
+	cfg.AutoSavePath = path
+	c := New(testMembers(3), cfg)
+	c.Add(testMember("node3.olric"))
+	restored, err := LoadFromFile(path, Config{Hasher: hasher{}}, nil)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if restored.Version() != c.Version() || len(restored.GetMembers()) != 4 {
+		t.Fatalf("Expected the saved ring at version %d, Got: %d", c.Version(), restored.Version())
+	}
+}
+
+func TestSaveToFilePendingFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "consistent")
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ring")
+
+	errInjected := errors.New("injected failure")
+	fail := false
+	cfg := newConfigWith(271)
+	cfg.PanicFree = true
+	cfg.TestingKnobs = &TestingKnobs{FailDistribution: func(uint64) error {
+		if fail {
+			return errInjected
+		}
+		return nil
+	}}
+	c := New(testMembers(4), cfg)
+	fail = true
+	c.Remove("node0.olric")
+	if err := c.SaveToFile(path); err != errInjected {
+		t.Fatalf("Expected the injected failure, Got: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Expected no file, Got: %v", err)
+	}
+	if res := c.Sync(SyncState{}); res.Action != RetryLater || res.Err != errInjected || res.Snapshot != nil {
+		t.Fatalf("Expected retry later, Got: %s, %v", res.Action, res.Err)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMemberForPoint(t *testing.T) {
+	c := New(nil, newConfig())
+	if member, point := c.MemberForPoint(42); member != nil || point != 0 {
+		t.Fatalf("Expected nil on an empty ring, got: %v, %d", member, point)
+	}
+
+	c = New(testMembers(4), newConfig())
+	for i, point := range c.sortedSet {
+		member, got := c.MemberForPoint(point)
+		if got != point || member.String() != (*c.ring[point]).String() {
+			t.Fatalf("Expected %s at point %d, got: %v at %d", *c.ring[point], point, member, got)
+		}
+		if point == 0 {
+			continue
+		}
+		// Points between two virtual nodes belong to the next one.
+		var previous uint64
+		if i > 0 {
+			previous = c.sortedSet[i-1]
+		}
+		if point-previous > 1 {
+			if _, got := c.MemberForPoint(point - 1); got != point {
+				t.Fatalf("Expected point %d for %d, got: %d", point, point-1, got)
+			}
+		}
+	}
+
+	// Hashes after the last point wrap around to the first one.
+	last := c.sortedSet[len(c.sortedSet)-1]
+	if last < math.MaxUint64 {
+		member, got := c.MemberForPoint(last + 1)
+		if got != c.sortedSet[0] || member.String() != (*c.ring[c.sortedSet[0]]).String() {
+			t.Fatalf("Expected the first point %d, got: %d", c.sortedSet[0], got)
+		}
+	}
+}
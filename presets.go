@@ -0,0 +1,57 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// ConfigSmallCluster returns a configuration for clusters of up to about 10 members. Many virtual nodes per member
+// smooth the distribution on a small ring, and the memory cost is negligible at this size. 71 partitions keep
+// the distribution fast while leaving several partitions per member. Set Hasher before using it.
+func ConfigSmallCluster() Config {
+	return Config{
+		PartitionCount:    71,
+		ReplicationFactor: 40,
+		Load:              1.25,
+	}
+}
+
+// ConfigLargeCluster returns a configuration for clusters of hundreds to a few thousand members. The partition
+// count must stay well above the member count to give every member a share, and fewer virtual nodes per member
+// keep the ring small; the number of members already smooths the distribution. Set Hasher before using it.
+func ConfigLargeCluster() Config {
+	return Config{
+		PartitionCount:    8191,
+		ReplicationFactor: 10,
+		Load:              1.2,
+	}
+}
+
+// ConfigCacheRouting returns a configuration for routing requests to cache nodes, where every relocated
+// partition turns into cache misses. A higher Load lets members keep their partitions when the membership
+// changes, at the cost of a less even distribution. Set Hasher before using it.
+func ConfigCacheRouting() Config {
+	return Config{
+		PartitionCount:    271,
+		ReplicationFactor: 20,
+		Load:              1.5,
+	}
+}
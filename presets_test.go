@@ -0,0 +1,57 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestConfigPresets(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		members []int
+	}{
+		{name: "small cluster", config: ConfigSmallCluster(), members: []int{1, 2, 3, 5, 10}},
+		{name: "large cluster", config: ConfigLargeCluster(), members: []int{100, 1000, 3000}},
+		{name: "cache routing", config: ConfigCacheRouting(), members: []int{1, 8, 32, 100}},
+	}
+	for _, tc := range tests {
+		for _, memberCount := range tc.members {
+			t.Run(fmt.Sprintf("%s with %d members", tc.name, memberCount), func(t *testing.T) {
+				cfg := tc.config
+				cfg.Hasher = hasher{}
+				var members []Member
+				for i := 0; i < memberCount; i++ {
+					members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+				}
+				c := New(members, cfg)
+				if err := c.Validate(); err != nil {
+					t.Fatalf("Expected nil, Got: %v", err)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// splitmix64 is the finalizer of the SplitMix64 generator. It maps consecutive inputs to well mixed outputs.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// pickCandidate chooses the owner of the partition among the first Config.RandomCandidates distinct members under
+// the load bound, found by walking the ring from idx, the position of first. The choice is weighted by the member
+// weights and depends only on Config.RandomSeed and the partition, so the distribution stays deterministic.
+// It's not thread-safe.
+func (c *Consistent) pickCandidate(partID, idx int, first Member, loads map[string]float64) Member {
+	candidates := []Member{first}
+	total := c.weight(first)
+	for i := 1; i < len(c.sortedSet) && len(candidates) < c.config.RandomCandidates; i++ {
+		member := *c.ring[c.sortedSet[(idx+i)%len(c.sortedSet)]]
+		if containsMember(candidates, member) {
+			continue
+		}
+		if loads[member.String()]+1 > c.loadBound(member) {
+			continue
+		}
+		candidates = append(candidates, member)
+		total += c.weight(member)
+	}
+	if len(candidates) == 1 {
+		return first
+	}
+
+	// Take the top 53 bits for a uniform float in [0, 1).
+	r := float64(splitmix64(c.config.RandomSeed^splitmix64(uint64(partID)))>>11) / (1 << 53) * total
+	for _, member := range candidates {
+		r -= c.weight(member)
+		if r < 0 {
+			return member
+		}
+	}
+	return candidates[len(candidates)-1]
+}
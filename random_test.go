@@ -0,0 +1,81 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func randomRing(seed uint64) *Consistent {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.RandomCandidates = 3
+	cfg.RandomSeed = seed
+	return New(members, cfg)
+}
+
+func TestRandomCandidates(t *testing.T) {
+	c := randomRing(42)
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if !c.Equal(randomRing(42)) {
+		t.Fatalf("Expected the same layout for the same seed")
+	}
+
+	other := randomRing(43)
+	var same int
+	for partID := 0; partID < 271; partID++ {
+		if c.GetPartitionOwner(partID).String() == other.GetPartitionOwner(partID).String() {
+			same++
+		}
+	}
+	if same > 271/2 {
+		t.Fatalf("Expected different seeds to spread the partitions, %d of 271 have the same owner", same)
+	}
+
+	bound := c.AverageLoad()
+	for member, load := range c.LoadDistribution() {
+		if load > bound {
+			t.Fatalf("%s owns %g partitions, bound is %g", member, load, bound)
+		}
+	}
+}
+
+func TestRandomCandidatesOrderIndependent(t *testing.T) {
+	c := randomRing(7)
+	cfg := c.config
+	d := New(nil, cfg)
+	for i := 7; i >= 0; i-- {
+		d.Add(testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	if !c.Equal(d) {
+		t.Fatalf("Expected the same layout regardless of the insertion order")
+	}
+}
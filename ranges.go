@@ -0,0 +1,67 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// OwnersInRange returns the distinct owners of the partitions which hold keys whose KeyHash falls into [lo, hi],
+// e.g. for repair or scan jobs that work on hash ranges. The hash of a key picks its partition modulo the
+// partition count, so an interval of at least Config.PartitionCount hashes touches every partition. If lo is
+// greater than hi, the interval wraps around. The owners come in the order of their first partition. It returns
+// nil if the ring is empty.
+func (c *Consistent) OwnersInRange(lo, hi uint64) []Member {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if len(c.partitions) == 0 {
+		return nil
+	}
+	touched := make([]bool, c.partitionCount)
+	// The number of hashes in the interval minus one, so the whole hash space doesn't overflow.
+	if span := hi - lo; span >= c.partitionCount-1 {
+		for partID := range touched {
+			touched[partID] = true
+		}
+	} else {
+		for h, i := lo, uint64(0); i <= span; h, i = h+1, i+1 {
+			touched[h%c.partitionCount] = true
+		}
+	}
+
+	var owners []Member
+	seen := make(map[string]struct{})
+	for partID, ok := range touched {
+		if !ok {
+			continue
+		}
+		owner := c.getPartitionOwner(partID)
+		if owner == nil {
+			continue
+		}
+		if _, ok := seen[owner.String()]; ok {
+			continue
+		}
+		seen[owner.String()] = struct{}{}
+		owners = append(owners, owner)
+	}
+	return owners
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestOwnersInRange(t *testing.T) {
+	cfg := newConfig()
+	c := New(nil, cfg)
+	if owners := c.OwnersInRange(0, math.MaxUint64); owners != nil {
+		t.Fatalf("Expected nil, Got: %v", owners)
+	}
+	for i := 0; i < 8; i++ {
+		c.Add(testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+
+	// A single hash belongs to a single partition.
+	if owners := c.OwnersInRange(100, 100); len(owners) != 1 || owners[0].String() != c.OwnerForHash(100).String() {
+		t.Fatalf("Expected the owner of the hash, Got: %v", owners)
+	}
+
+	check := func(lo, hi uint64, hashes []uint64) {
+		expected := make(map[string]struct{})
+		for _, h := range hashes {
+			expected[c.OwnerForHash(h).String()] = struct{}{}
+		}
+		owners := c.OwnersInRange(lo, hi)
+		if len(owners) != len(expected) {
+			t.Fatalf("[%d, %d]: expected %d owners, Got: %v", lo, hi, len(expected), owners)
+		}
+		for _, owner := range owners {
+			if _, ok := expected[owner.String()]; !ok {
+				t.Fatalf("[%d, %d]: unexpected owner %s", lo, hi, owner)
+			}
+		}
+	}
+	check(1000, 1005, []uint64{1000, 1001, 1002, 1003, 1004, 1005})
+	// Wraps around.
+	check(math.MaxUint64-1, 2, []uint64{math.MaxUint64 - 1, math.MaxUint64, 0, 1, 2})
+
+	// Every partition.
+	all := c.OwnersInRange(0, math.MaxUint64)
+	if len(all) != len(c.GetMembers()) {
+		t.Fatalf("Expected every member, Got: %v", all)
+	}
+	if got := c.OwnersInRange(7, 7+uint64(cfg.PartitionCount)-1); len(got) != len(all) {
+		t.Fatalf("Expected every member, Got: %v", got)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import "time"
+
+// relaxSteps are the multiples of Config.Load a failed distribution retries with, see Config.RelaxedLoadLimit.
+var relaxSteps = []float64{1.1, 1.25, 1.5, 2, 3}
+
+// distribute distributes the partitions. If there is not enough room under the load bound, it retries with the
+// relaxed load factors of Config.RelaxedLoadLimit, for the members with overflow priorities first. A relaxed
+// factor which succeeded stays applied to the load bounds until the next distribution, so Validate and
+// AverageLoad agree with the table. It's not thread-safe.
+func (c *Consistent) distribute() error {
+	c.adaptReplicationFactor()
+	deadline := c.distributionDeadline()
+	// The relaxation applied to the current table, restored if every attempt fails.
+	relaxedLoad, loadScale, overflowTier := c.relaxedLoad, c.loadScale, c.overflowTier
+	c.relaxedLoad, c.loadScale, c.overflowTier = 0, 0, 0
+	err := c.distributeOnce(deadline)
+	if _, ok := err.(*DistributionError); !ok || c.config.RelaxedLoadLimit <= c.config.Load {
+		if err != nil {
+			c.relaxedLoad, c.loadScale, c.overflowTier = relaxedLoad, loadScale, overflowTier
+		}
+		return err
+	}
+	for _, tier := range c.overflowTiers() {
+		c.overflowTier = tier
+		err = c.relax(deadline)
+		if _, ok := err.(*DistributionError); !ok {
+			break
+		}
+	}
+	if _, ok := err.(*DistributionError); ok {
+		c.overflowTier = 0
+		err = c.relax(deadline)
+	}
+	if err != nil {
+		c.relaxedLoad, c.loadScale, c.overflowTier = relaxedLoad, loadScale, overflowTier
+		return err
+	}
+	c.logRelaxation()
+	return nil
+}
+
+// relax retries the distribution with the relaxed multiples of Config.Load up to Config.RelaxedLoadLimit. Only
+// the members selected by overflowTier are relaxed if it's set. It's not thread-safe.
+func (c *Consistent) relax(deadline time.Time) error {
+	load := c.config.Load
+	var err error
+	for _, step := range relaxSteps {
+		relaxed := load * step
+		if relaxed > c.config.RelaxedLoadLimit {
+			relaxed = c.config.RelaxedLoadLimit
+		}
+		c.relaxedLoad = 0
+		if c.overflowTier == 0 {
+			c.relaxedLoad = relaxed
+		}
+		// The load factors of Config.ClassLoads relax in proportion.
+		c.loadScale = relaxed / load
+		err = c.distributeOnce(deadline)
+		if _, ok := err.(*DistributionError); !ok || relaxed == c.config.RelaxedLoadLimit {
+			return err
+		}
+	}
+	return err
+}
+
+// relaxedFor reports whether the bound of the member is relaxed in the current distribution: every member's if
+// Config.Load was relaxed, only those of the overflow tier if SetOverflowPriority selected one. It's not
+// thread-safe.
+func (c *Consistent) relaxedFor(member Member) bool {
+	if c.loadScale <= 1 {
+		return false
+	}
+	return c.overflowTier == 0 || c.overflowing(member)
+}
+
+// loadFactor returns the load factor of the current distribution: the relaxed one if Config.RelaxedLoadLimit
+// applied, Config.Load otherwise. It's not thread-safe.
+func (c *Consistent) loadFactor() float64 {
+	if c.relaxedLoad > 0 {
+		return c.relaxedLoad
+	}
+	return c.config.Load
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"testing"
+)
+
+func TestRelaxedLoad(t *testing.T) {
+	cfg := newConfig()
+	cfg.Load = 0.5
+	cfg.RelaxedLoadLimit = 2
+	c := New(testMembers(4), cfg)
+
+	r := c.LastDistribution()
+	if r.Err != nil {
+		t.Fatalf("Expected nil, got: %v", r.Err)
+	}
+	// Four members need a bound of six partitions, which takes a load factor of at least 23/24.
+	if r.Load != 1 {
+		t.Fatalf("Expected the load factor 1, got: %v", r.Load)
+	}
+	if c.config.Load != 0.5 {
+		t.Fatalf("Expected Config.Load to stay, got: %v", c.config.Load)
+	}
+	for name, load := range c.LoadDistribution() {
+		if load > 6 {
+			t.Fatalf("%s owns %v partitions", name, load)
+		}
+	}
+	// The applied factor stays in place, so the checks of the ring agree with the table.
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if got := c.AverageLoad(); got != 6 {
+		t.Fatalf("Expected the average load 6, got: %v", got)
+	}
+
+	// A strict distribution clears it.
+	c.config.RelaxedLoadLimit = 0
+	c.config.Load = 1.25
+	c.Add(testMember("node4.olric"))
+	if c.relaxedLoad != 0 || c.loadScale != 0 {
+		t.Fatalf("Expected the relaxation to be cleared, got: %v, %v", c.relaxedLoad, c.loadScale)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+}
+
+func TestRelaxedLoadLimit(t *testing.T) {
+	cfg := newConfig()
+	cfg.Load = 0.5
+	cfg.RelaxedLoadLimit = 0.7
+	cfg.PanicFree = true
+	c := New(testMembers(4), cfg)
+
+	r := c.LastDistribution()
+	if _, ok := r.Err.(*DistributionError); !ok {
+		t.Fatalf("Expected a *DistributionError, got: %v", r.Err)
+	}
+	if r.Load != 0.7 {
+		t.Fatalf("Expected the last attempt at the limit, got: %v", r.Load)
+	}
+	if !c.Pending() {
+		t.Fatalf("Expected a pending distribution")
+	}
+}
+
+func TestRelaxedLoadDisabled(t *testing.T) {
+	cfg := newConfig()
+	cfg.Load = 0.5
+	cfg.PanicFree = true
+	c := New(testMembers(4), cfg)
+	if r := c.LastDistribution(); r.Err == nil || r.Load != 0.5 {
+		t.Fatalf("Expected a failure at 0.5, got: %+v", r)
+	}
+}
+
+func TestOverflowPriority(t *testing.T) {
+	cfg := newConfig()
+	cfg.Load = 0.8
+	cfg.RelaxedLoadLimit = 2
+	c := New(nil, cfg)
+	c.SetOverflowPriority("node0.olric", 1)
+	c.SetMembers(testMembers(4))
+
+	// The other members own up to five partitions under the load factor, node0.olric takes the rest.
+	loads := c.LoadDistribution()
+	for name, load := range loads {
+		if name != "node0.olric" && load > 5 {
+			t.Fatalf("%s owns %v partitions", name, load)
+		}
+	}
+	if loads["node0.olric"] <= 5 {
+		t.Fatalf("Expected node0.olric to overflow, got: %v", loads["node0.olric"])
+	}
+	if c.config.Load != 0.8 || c.relaxedLoad != 0 || c.overflowTier != 1 {
+		t.Fatalf("Expected only the overflow tier to be relaxed, got: %v, %v", c.loadFactor(), c.overflowTier)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+
+	// A single overflow member with a limit cannot take the rest, so all members are relaxed.
+	if err := c.SetMaxLoad("node0.olric", 6); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if r := c.LastDistribution(); r.Err != nil || r.Load <= 0.8 {
+		t.Fatalf("Expected all members to be relaxed, got: %+v", r)
+	}
+	if c.OverflowPriority("node0.olric") != 1 {
+		t.Fatalf("Expected the priority 1, got: %d", c.OverflowPriority("node0.olric"))
+	}
+	c.SetOverflowPriority("node0.olric", 0)
+	if c.OverflowPriority("node0.olric") != 0 {
+		t.Fatalf("Expected no priority, got: %d", c.OverflowPriority("node0.olric"))
+	}
+}
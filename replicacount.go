@@ -0,0 +1,61 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// AddWithReplicas adds a new member like TryAdd, but with the given number of virtual nodes instead of
+// Config.ReplicationFactor, e.g. more for a member which should own a larger share of the ring without
+// implementing WeightedMember. A non-positive count takes Config.ReplicationFactor. It returns the errors of
+// TryAdd; a member replaced by Config.OnDuplicateAdd or a newer generation takes the new count. The count is kept
+// when the member is replaced with a new value of the same name and forgotten when it's removed. It doesn't
+// follow Config.AutoReplicationFactor.
+func (c *Consistent) AddWithReplicas(member Member, replicas int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.tryAdd(member, replicas)
+}
+
+// Replicas returns the number of virtual nodes of the member, zero if there is no such member.
+func (c *Consistent) Replicas(name string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.vnodes[c.memberName(name)])
+}
+
+// replicaCount returns the number of virtual nodes placed for the member. It's not thread-safe.
+func (c *Consistent) replicaCount(name string) int {
+	if n, ok := c.replicaCounts[name]; ok {
+		return n
+	}
+	return c.config.ReplicationFactor
+}
+
+// setReplicaCount sets the virtual node count of the member for AddWithReplicas. It's not thread-safe.
+func (c *Consistent) setReplicaCount(name string, n int) {
+	if c.replicaCounts == nil {
+		c.replicaCounts = make(map[string]int)
+	}
+	c.replicaCounts[name] = n
+}
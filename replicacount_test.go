@@ -0,0 +1,133 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"testing"
+)
+
+func TestAddWithReplicas(t *testing.T) {
+	cfg := newConfigWith(271)
+	c := New(testMembers(3), cfg)
+	c.AddWithReplicas(testMember("node3.olric"), 60)
+	if got := c.Replicas("node3.olric"); got != 60 {
+		t.Fatalf("Expected 60 virtual nodes, got: %d", got)
+	}
+	if got := c.Replicas("node0.olric"); got != cfg.ReplicationFactor {
+		t.Fatalf("Expected %d virtual nodes, got: %d", cfg.ReplicationFactor, got)
+	}
+	if got := len(c.sortedSet); got != 3*cfg.ReplicationFactor+60 {
+		t.Fatalf("Expected %d points on the ring, got: %d", 3*cfg.ReplicationFactor+60, got)
+	}
+	loads := c.LoadDistribution()
+	if loads["node3.olric"] <= loads["node0.olric"] {
+		t.Fatalf("Expected the member with more virtual nodes to own more partitions: %v", loads)
+	}
+
+	snapshot := c.Snapshot()
+	restored, err := FromSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if !restored.Equal(c) || restored.Replicas("node3.olric") != 60 {
+		t.Fatalf("Expected the restored ring to keep the virtual node count")
+	}
+
+	c.Remove("node3.olric")
+	if got := len(c.sortedSet); got != 3*cfg.ReplicationFactor {
+		t.Fatalf("Expected %d points on the ring, got: %d", 3*cfg.ReplicationFactor, got)
+	}
+	if got := len(c.ring); got != 3*cfg.ReplicationFactor {
+		t.Fatalf("Expected %d virtual nodes, got: %d", 3*cfg.ReplicationFactor, got)
+	}
+	c.Add(testMember("node3.olric"))
+	if got := c.Replicas("node3.olric"); got != cfg.ReplicationFactor {
+		t.Fatalf("Expected the default after re-adding, got: %d", got)
+	}
+	if c.Replicas("unknown") != 0 {
+		t.Fatalf("Expected no virtual nodes for an unknown member")
+	}
+}
+
+func TestAddWithReplicasReplace(t *testing.T) {
+	cfg := newConfig()
+	cfg.OnDuplicateAdd = ReplaceDuplicate
+	c := New(testMembers(3), cfg)
+	c.AddWithReplicas(weightedMember{name: "node3.olric", weight: 1}, 5)
+	c.Add(weightedMember{name: "node3.olric", weight: 2})
+	if got := c.Replicas("node3.olric"); got != 5 {
+		t.Fatalf("Expected 5 virtual nodes after Add, got: %d", got)
+	}
+	c.SetMembers(append(testMembers(3), weightedMember{name: "node3.olric", weight: 3}))
+	if got := c.Replicas("node3.olric"); got != 5 {
+		t.Fatalf("Expected 5 virtual nodes after SetMembers, got: %d", got)
+	}
+	c.SetMembers(testMembers(3))
+	c.Add(testMember("node3.olric"))
+	if got := c.Replicas("node3.olric"); got != cfg.ReplicationFactor {
+		t.Fatalf("Expected the default after SetMembers removed the member, got: %d", got)
+	}
+}
+
+func TestAddWithReplicasErrors(t *testing.T) {
+	cfg := newConfig()
+	cfg.OnDuplicateAdd = RejectDuplicate
+	c := New(testMembers(3), cfg)
+	if err := c.AddWithReplicas(testMember(""), 5); err != ErrInvalidMemberName {
+		t.Fatalf("Expected ErrInvalidMemberName, got: %v", err)
+	}
+	if err := c.AddWithReplicas(testMember("node0.olric"), 5); err != ErrMemberAlreadyExists {
+		t.Fatalf("Expected ErrMemberAlreadyExists, got: %v", err)
+	}
+	if got := c.Replicas("node0.olric"); got != cfg.ReplicationFactor {
+		t.Fatalf("Expected the rejected count to be ignored, got: %d", got)
+	}
+
+	cfg = newConfig()
+	cfg.OnDuplicateAdd = ReplaceDuplicate
+	c = New(testMembers(3), cfg)
+	if err := c.AddWithReplicas(testMember("node0.olric"), 5); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if got := c.Replicas("node0.olric"); got != 5 {
+		t.Fatalf("Expected the replaced member to take 5 virtual nodes, got: %d", got)
+	}
+	if got := len(c.sortedSet); got != 2*cfg.ReplicationFactor+5 {
+		t.Fatalf("Expected %d points on the ring, got: %d", 2*cfg.ReplicationFactor+5, got)
+	}
+}
+
+func TestAddWithReplicasGeneration(t *testing.T) {
+	c := New(nil, newConfig())
+	c.Add(generationMember{name: "node1", generation: 2})
+	if err := c.AddWithReplicas(generationMember{name: "node1", generation: 1}, 5); err != ErrStaleGeneration {
+		t.Fatalf("Expected ErrStaleGeneration, got: %v", err)
+	}
+	if err := c.AddWithReplicas(generationMember{name: "node1", generation: 3}, 5); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if got := c.Replicas("node1"); got != 5 {
+		t.Fatalf("Expected the newer generation to take 5 virtual nodes, got: %d", got)
+	}
+}
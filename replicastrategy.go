@@ -0,0 +1,160 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"time"
+)
+
+// ReplicaStrategy picks the backups of the partitions, see Config.ReplicaStrategy. Backups returns the backups of
+// the partition in order, without its owner. It's called with the ring locked, so it must not call the methods
+// of the ring; the ReplicaContext tells it everything it needs. A partition gets no backups if the ring has no
+// eligible members.
+type ReplicaStrategy interface {
+	Backups(ctx ReplicaContext, partID int, owner Member) []Member
+}
+
+// ReplicaContext is the state of the ring a ReplicaStrategy works on. It's only valid during Backups.
+type ReplicaContext struct {
+	c *Consistent
+}
+
+// BackupCount returns Config.BackupCount.
+func (r ReplicaContext) BackupCount() int {
+	return r.c.config.BackupCount
+}
+
+// Eligible reports whether the member may be a backup: it's neither draining nor leaving, and Config.SkipBackup
+// doesn't reject it.
+func (r ReplicaContext) Eligible(member Member) bool {
+	c := r.c
+	if c.drained(member) {
+		return false
+	}
+	if _, ok := c.leavingBound(member); ok {
+		return false
+	}
+	return c.config.SkipBackup == nil || !c.config.SkipBackup(member)
+}
+
+// Walk calls f with the members of the virtual nodes from the point of the partition in Config.BackupDirection.
+// A member appears once for each of its virtual nodes. Walk stops when f returns false or after a full circle.
+func (r ReplicaContext) Walk(partID int, f func(member Member) bool) {
+	c := r.c
+	n := len(c.sortedSet)
+	if n == 0 {
+		return
+	}
+	idx := c.partitionIndex(partID, make([]byte, 8))
+	for i := 0; i < n; i++ {
+		pos := (idx + i) % n
+		if c.config.BackupDirection == CounterClockwise {
+			pos = ((idx-1-i)%n + n) % n
+		}
+		if !f(*c.ring[c.sortedSet[pos]]) {
+			return
+		}
+	}
+}
+
+// SimpleStrategy picks the first Config.BackupCount eligible members which follow the owner on the ring, like
+// SimpleStrategy of Cassandra. Unlike the default, it doesn't balance the backups over the members.
+type SimpleStrategy struct{}
+
+// Backups returns the successors of the partition.
+func (SimpleStrategy) Backups(ctx ReplicaContext, partID int, owner Member) []Member {
+	count := ctx.BackupCount()
+	var res []Member
+	if count <= 0 {
+		return res
+	}
+	ctx.Walk(partID, func(member Member) bool {
+		if member.String() != owner.String() && !containsMember(res, member) && ctx.Eligible(member) {
+			res = append(res, member)
+		}
+		return len(res) < count
+	})
+	return res
+}
+
+// NetworkTopologyStrategy spreads the replicas of every partition over zones, see ZonedMember, like
+// NetworkTopologyStrategy of Cassandra. Replicas maps zones to the number of replicas, the owner included, in
+// them; Config.BackupCount is ignored. The members of every zone are picked in the order of the ring walk from the
+// partition. A zone gets fewer replicas if it doesn't have enough eligible members.
+type NetworkTopologyStrategy struct {
+	Replicas map[string]int
+}
+
+// Backups returns the replicas of the partition in the zones.
+func (s NetworkTopologyStrategy) Backups(ctx ReplicaContext, partID int, owner Member) []Member {
+	need := make(map[string]int, len(s.Replicas))
+	var remaining int
+	for zone, count := range s.Replicas {
+		if zone == memberZone(owner) {
+			count--
+		}
+		if count > 0 {
+			need[zone] = count
+			remaining += count
+		}
+	}
+	var res []Member
+	if remaining == 0 {
+		return res
+	}
+	ctx.Walk(partID, func(member Member) bool {
+		zone := memberZone(member)
+		if need[zone] == 0 || member.String() == owner.String() || containsMember(res, member) || !ctx.Eligible(member) {
+			return true
+		}
+		res = append(res, member)
+		need[zone]--
+		remaining--
+		return remaining > 0
+	})
+	return res
+}
+
+// replicaTableWith computes the backups of every partition with the strategy. It returns ErrInvalidDistribution
+// if the strategy picks the owner, the same member twice or a member which isn't on the ring, and
+// ErrDistributionTimeout if the deadline passes. It's not thread-safe.
+func (c *Consistent) replicaTableWith(s ReplicaStrategy, deadline time.Time) ([][]Member, error) {
+	ctx := ReplicaContext{c: c}
+	replicas := make([][]Member, c.partitionCount)
+	for partID := range replicas {
+		if c.expired(deadline, partID) {
+			return nil, ErrDistributionTimeout
+		}
+		owner := c.getPartitionOwner(partID)
+		res := []Member{owner}
+		for _, backup := range s.Backups(ctx, partID, owner) {
+			if _, ok := c.members[backup.String()]; !ok || containsMember(res, backup) {
+				return nil, ErrInvalidDistribution
+			}
+			res = append(res, backup)
+		}
+		replicas[partID] = res
+	}
+	return replicas, nil
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSimpleStrategy(t *testing.T) {
+	cfg := newConfigWith(271)
+	cfg.BackupCount = 2
+	cfg.ReplicaStrategy = SimpleStrategy{}
+	c := New(testMembers(6), cfg)
+
+	bs := make([]byte, 8)
+	for partID := 0; partID < 271; partID++ {
+		replicas := c.GetPartitionOwnerAndBackups(partID)
+		if len(replicas) != 3 {
+			t.Fatalf("Expected 3 replicas of partition %d, Got: %v", partID, replicas)
+		}
+		// The backups are the first members after the owner on the ring.
+		var expected []Member
+		idx := c.partitionIndex(partID, bs)
+		for i := 0; len(expected) < 2; i++ {
+			member := *c.ring[c.sortedSet[(idx+i)%len(c.sortedSet)]]
+			if !containsMember(replicas[:1], member) && !containsMember(expected, member) {
+				expected = append(expected, member)
+			}
+		}
+		if replicas[1].String() != expected[0].String() || replicas[2].String() != expected[1].String() {
+			t.Fatalf("Expected the backups %v of partition %d, Got: %v", expected, partID, replicas[1:])
+		}
+	}
+}
+
+func TestNetworkTopologyStrategy(t *testing.T) {
+	var members []Member
+	for i := 0; i < 9; i++ {
+		members = append(members, zonedMember{name: fmt.Sprintf("node%d.olric", i), zone: fmt.Sprintf("zone%d", i%3)})
+	}
+	cfg := newConfigWith(271)
+	cfg.ReplicaStrategy = NetworkTopologyStrategy{Replicas: map[string]int{"zone0": 2, "zone1": 1, "zone2": 0}}
+	c := New(members, cfg)
+
+	for partID := 0; partID < 271; partID++ {
+		replicas := c.GetPartitionOwnerAndBackups(partID)
+		zones := make(map[string]int)
+		for _, member := range replicas {
+			zones[memberZone(member)]++
+		}
+		owner := memberZone(replicas[0])
+		// The owner counts for its zone, even if the zone isn't supposed to get replicas.
+		expected := map[string]int{"zone0": 2, "zone1": 1}
+		if owner == "zone2" {
+			expected["zone2"] = 1
+		}
+		if fmt.Sprint(zones) != fmt.Sprint(expected) {
+			t.Fatalf("Expected %v replicas of partition %d, Got: %v", expected, partID, zones)
+		}
+	}
+
+	// Draining members aren't picked, zone1 runs out of members.
+	for _, name := range []string{"node1.olric", "node4.olric", "node7.olric"} {
+		if err := c.Drain(name); err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+	}
+	for partID := 0; partID < 271; partID++ {
+		for _, member := range c.GetPartitionOwnerAndBackups(partID) {
+			if memberZone(member) == "zone1" {
+				t.Fatalf("Expected no replicas in zone1, Got: %s", member)
+			}
+		}
+	}
+}
+
+type ownerStrategy struct{}
+
+func (ownerStrategy) Backups(ctx ReplicaContext, partID int, owner Member) []Member {
+	return []Member{owner}
+}
+
+func TestReplicaStrategyInvalid(t *testing.T) {
+	cfg := newConfigWith(271)
+	cfg.PanicFree = true
+	cfg.ReplicaStrategy = ownerStrategy{}
+	c := New(testMembers(3), cfg)
+	if err := c.Flush(); err != ErrInvalidDistribution {
+		t.Fatalf("Expected ErrInvalidDistribution, Got: %v", err)
+	}
+}
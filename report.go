@@ -0,0 +1,153 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// DistributionReport describes how the last distribution of the partitions went, see LastDistribution. The walk
+// counts show how close the configuration is to infeasibility: the fuller the members, the more virtual nodes a
+// partition passes before it finds a member with room under the load bound.
+type DistributionReport struct {
+	// Version is the version of the partition table after the distribution. A failed distribution doesn't change
+	// it.
+	Version uint64
+
+	// Start is the time the distribution started.
+	Start time.Time
+
+	// Duration is the time it took to distribute the partitions.
+	Duration time.Duration
+
+	// Iterations is the number of virtual nodes visited by the walks of the ring.
+	Iterations int
+
+	// Preassigned is the number of partitions which kept or got an owner before the walks, e.g. by
+	// Config.Stickiness, Config.MinResidency, Config.FailoverToBackup or partition affinities.
+	Preassigned int
+
+	// Skipped is the number of partitions whose walk passed members at their load bound.
+	Skipped int
+
+	// LongestWalk is the largest number of virtual nodes visited by a single walk.
+	LongestWalk int
+
+	// AtBound contains the names of the members which can't take another partition under the load bound, in
+	// ascending order. It's empty for a failed distribution.
+	AtBound []string
+
+	// Spare is the number of partitions the members could take on top of their loads under the load bound.
+	Spare int
+
+	// Load is the load factor the partitions were distributed with. It's greater than Config.Load if
+	// Config.RelaxedLoadLimit relaxed it.
+	Load float64
+
+	// RoundRobin is set if the partitions were distributed round-robin by Config.TinyClusterFallback instead of
+	// walking the ring.
+	RoundRobin bool
+
+	// Err is the error of a failed distribution.
+	Err error
+}
+
+// LastDistribution returns the report of the last distribution of the partitions, or nil if there was none.
+func (c *Consistent) LastDistribution() *DistributionReport {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.lastDistribution == nil {
+		return nil
+	}
+	r := *c.lastDistribution
+	r.AtBound = append([]string(nil), r.AtBound...)
+	return &r
+}
+
+// walkStats counts the work of a distribution for its report.
+type walkStats struct {
+	iterations  int
+	preassigned int
+	skipped     int
+	longest     int
+	roundRobin  bool
+}
+
+// record counts a walk which visited count virtual nodes.
+func (w *walkStats) record(count int) {
+	w.iterations += count
+	if count > 1 {
+		w.skipped++
+	}
+	if count > w.longest {
+		w.longest = count
+	}
+}
+
+// adoptReport takes the report of a distribution which ran on the shadow, see distributeAsync. It's not
+// thread-safe.
+func (c *Consistent) adoptReport(shadow *Consistent) {
+	if shadow.lastDistribution == nil {
+		return
+	}
+	r := *shadow.lastDistribution
+	r.Version = c.version
+	c.lastDistribution = &r
+}
+
+// reportDistribution records the report of the distribution which started at start. It's not thread-safe.
+func (c *Consistent) reportDistribution(start time.Time, err error) {
+	r := &DistributionReport{
+		Version:     c.version,
+		Start:       start,
+		Duration:    time.Since(start),
+		Iterations:  c.walk.iterations,
+		Preassigned: c.walk.preassigned,
+		Skipped:     c.walk.skipped,
+		LongestWalk: c.walk.longest,
+		Load:        c.loadFactor(),
+		RoundRobin:  c.walk.roundRobin,
+		Err:         err,
+	}
+	if err == nil {
+		for name, member := range c.members {
+			bound := math.Floor(c.loadBound(*member))
+			if c.walk.roundRobin {
+				// The fallback ignores the load bound.
+				continue
+			}
+			load := c.loads[name]
+			if load+1 > bound {
+				r.AtBound = append(r.AtBound, name)
+				continue
+			}
+			r.Spare += int(bound - load)
+		}
+		sort.Strings(r.AtBound)
+	}
+	c.lastDistribution = r
+}
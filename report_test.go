@@ -0,0 +1,105 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestLastDistribution(t *testing.T) {
+	c := New(nil, newConfig())
+	if c.LastDistribution() != nil {
+		t.Fatalf("Expected nil before the first distribution")
+	}
+	for i := 0; i < 4; i++ {
+		c.Add(testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	r := c.LastDistribution()
+	if r == nil || r.Err != nil || r.Version != c.Version() {
+		t.Fatalf("Expected the report of version %d, Got: %+v", c.Version(), r)
+	}
+	if r.Preassigned != 0 || r.RoundRobin {
+		t.Fatalf("Expected a plain walk, Got: %+v", r)
+	}
+	// Every partition walks at least one virtual node.
+	if r.Iterations < 23 || r.LongestWalk < 1 || r.Skipped > 23 {
+		t.Fatalf("Unexpected walk counts: %+v", r)
+	}
+	if r.Skipped != 0 && r.LongestWalk < 2 {
+		t.Fatalf("A skip means a walk passed a full member: %+v", r)
+	}
+
+	// Every member is at its bound or has room; both add up to the capacity of the ring.
+	var capacity int
+	c.mu.RLock()
+	for _, member := range c.members {
+		capacity += int(c.loadBound(*member))
+	}
+	c.mu.RUnlock()
+	if r.Spare+23 != capacity {
+		t.Fatalf("Expected %d spare partitions, Got: %d", capacity-23, r.Spare)
+	}
+	for _, name := range r.AtBound {
+		if c.LoadDistribution()[name]+1 <= float64(int(c.loadBound(*c.members[name]))) {
+			t.Fatalf("%s isn't at its bound", name)
+		}
+	}
+}
+
+func TestLastDistributionFailed(t *testing.T) {
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, newConfig())
+	version := c.Version()
+	var distErr *DistributionError
+	if err := c.SetMaxLoad("node0.olric", 1); !errors.As(err, &distErr) {
+		t.Fatalf("Expected a *DistributionError, Got: %v", err)
+	}
+	r := c.LastDistribution()
+	if r.Err == nil || r.Version != version || len(r.AtBound) != 0 {
+		t.Fatalf("Expected the report of a failed distribution, Got: %+v", r)
+	}
+}
+
+func TestLastDistributionRoundRobin(t *testing.T) {
+	cfg := newConfig()
+	cfg.TinyClusterFallback = true
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, cfg)
+	if r := c.LastDistribution(); !r.RoundRobin || r.Iterations != 0 {
+		t.Fatalf("Expected a round-robin distribution, Got: %+v", r)
+	}
+}
+
+func TestLastDistributionAsync(t *testing.T) {
+	cfg := newConfig()
+	cfg.AsyncDistribution = true
+	c := New([]Member{testMember("node0.olric")}, cfg)
+	c.Add(testMember("node1.olric"))
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if r := c.LastDistribution(); r.Version != c.Version() || r.Err != nil {
+		t.Fatalf("Expected the report of version %d, Got: %+v", c.Version(), r)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"time"
+)
+
+// distributeResident keeps the partitions which moved less than Config.MinResidency ago on their current owners,
+// as long as the owners are on the ring and have room under the load bound. It's not thread-safe.
+func (c *Consistent) distributeResident(partitions map[int]*Member, loads map[string]float64, now time.Time) {
+	if c.config.MinResidency <= 0 || len(c.movedAt) == 0 {
+		return
+	}
+	// Follow the partition order, so the same partitions stay if an owner runs out of room.
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		movedAt, ok := c.movedAt[partID]
+		if !ok || now.Sub(movedAt) >= c.config.MinResidency {
+			continue
+		}
+		if _, ok := partitions[partID]; ok {
+			continue
+		}
+		owner, ok := c.partitions[partID]
+		if !ok {
+			continue
+		}
+		m, ok := c.members[(*owner).String()]
+		if !ok {
+			// The owner left, the partition moves.
+			continue
+		}
+		if loads[(*m).String()]+1 <= c.loadBound(*m) {
+			partitions[partID] = m
+			loads[(*m).String()]++
+		}
+	}
+}
+
+// updateResidency records the time of the move of every partition whose owner changed, and forgets the moves
+// older than Config.MinResidency. The first owner of a partition isn't a move. It's not thread-safe.
+func (c *Consistent) updateResidency(previous, partitions map[int]*Member, now time.Time) {
+	if c.config.MinResidency <= 0 {
+		return
+	}
+	movedAt := make(map[int]time.Time)
+	for partID, t := range c.movedAt {
+		if now.Sub(t) < c.config.MinResidency {
+			movedAt[partID] = t
+		}
+	}
+	for partID, owner := range partitions {
+		if old, ok := previous[partID]; ok && (*old).String() != (*owner).String() {
+			movedAt[partID] = now
+		}
+	}
+	c.movedAt = movedAt
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMinResidency(t *testing.T) {
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.MinResidency = time.Hour
+	var members []Member
+	for i := 0; i < 6; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c := New(members, cfg)
+	if len(c.movedAt) != 0 {
+		t.Fatalf("The initial distribution isn't a move")
+	}
+	before := c.owners()
+
+	// node5.olric flaps: its partitions move away and must not move again when it's back.
+	c.Remove("node5.olric")
+	moved := make(map[int]bool)
+	for partID, owner := range c.owners() {
+		if owner.String() != before[partID].String() {
+			moved[partID] = true
+		}
+	}
+	if len(moved) == 0 {
+		t.Fatalf("Expected moved partitions")
+	}
+	afterRemove := c.owners()
+	c.Add(testMember("node5.olric"))
+	for partID, owner := range c.owners() {
+		if moved[partID] && owner.String() != afterRemove[partID].String() {
+			t.Fatalf("Partition %d moved again from %s to %s", partID, afterRemove[partID], owner)
+		}
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	// Once the residency is over, the partitions go back.
+	for partID := range c.movedAt {
+		c.movedAt[partID] = time.Now().Add(-2 * time.Hour)
+	}
+	if err := c.distributePartitions(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	cfg.MinResidency = 0
+	if !c.Equal(New(members, cfg)) {
+		t.Fatalf("Expected the layout without residency")
+	}
+}
+
+func TestMinResidencyOwnerLeft(t *testing.T) {
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.MinResidency = time.Hour
+	var members []Member
+	for i := 0; i < 4; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c := New(members, cfg)
+	c.Add(testMember("node4.olric"))
+	c.Remove("node4.olric")
+	if _, ok := c.LoadDistribution()["node4.olric"]; ok {
+		t.Fatalf("Partitions must leave a removed owner")
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+}
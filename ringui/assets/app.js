@@ -0,0 +1,110 @@
+// Draws the ring served at api/ring and looks up keys at api/lookup.
+(function () {
+  "use strict";
+
+  var svg = document.getElementById("ring");
+  var ns = "http://www.w3.org/2000/svg";
+  var colors = {};
+  var selected = -1;
+
+  function color(name) {
+    return colors[name] || "#999";
+  }
+
+  // point returns the coordinates of a position on the circle, zero is at the top and positions grow clockwise.
+  function point(position, radius) {
+    var angle = position * 2 * Math.PI - Math.PI / 2;
+    return [radius * Math.cos(angle), radius * Math.sin(angle)];
+  }
+
+  function element(name, attrs) {
+    var el = document.createElementNS(ns, name);
+    Object.keys(attrs).forEach(function (k) {
+      el.setAttribute(k, attrs[k]);
+    });
+    return el;
+  }
+
+  function draw(ring) {
+    colors = {};
+    ring.members.forEach(function (m, i) {
+      colors[m.name] = "hsl(" + Math.round(i * 360 / ring.members.length) + ", 65%, 50%)";
+    });
+
+    while (svg.firstChild) {
+      svg.removeChild(svg.firstChild);
+    }
+    svg.appendChild(element("circle", {r: 200, fill: "none", stroke: "#ccc"}));
+    svg.appendChild(element("circle", {r: 160, fill: "none", stroke: "#eee"}));
+
+    // Virtual nodes are ticks on the outer circle.
+    ring.members.forEach(function (m) {
+      m.points.forEach(function (p) {
+        var a = point(p, 192), b = point(p, 208);
+        var tick = element("line", {x1: a[0], y1: a[1], x2: b[0], y2: b[1], stroke: color(m.name)});
+        tick.appendChild(element("title", {})).textContent = m.name;
+        svg.appendChild(tick);
+      });
+    });
+
+    // Partitions are dots on the inner circle, colored by owner.
+    ring.partitions.forEach(function (p) {
+      var c = point(p.point, 160);
+      var dot = element("circle", {
+        cx: c[0], cy: c[1], r: Math.max(2, Math.min(6, 600 / ring.partitionCount)),
+        fill: color(p.owner), "class": p.id === selected ? "partition selected" : "partition"
+      });
+      dot.appendChild(element("title", {})).textContent = "partition " + p.id + ": " + p.owner;
+      svg.appendChild(dot);
+    });
+
+    document.getElementById("summary").textContent = ring.members.length + " members, " +
+      ring.partitionCount + " partitions, version " + ring.version;
+
+    var loads = document.getElementById("loads");
+    loads.innerHTML = "";
+    var max = ring.averageLoad;
+    ring.members.forEach(function (m) {
+      if (m.load > max) {
+        max = m.load;
+      }
+    });
+    ring.members.forEach(function (m) {
+      var li = document.createElement("li");
+      li.textContent = m.name + ": " + m.load + " / " + ring.averageLoad;
+      var bar = document.createElement("div");
+      bar.className = "bar";
+      var fill = document.createElement("span");
+      fill.style.width = (max > 0 ? 100 * m.load / max : 0) + "%";
+      fill.style.background = color(m.name);
+      bar.appendChild(fill);
+      li.appendChild(bar);
+      loads.appendChild(li);
+    });
+  }
+
+  function refresh() {
+    fetch("api/ring").then(function (r) {
+      return r.json();
+    }).then(draw);
+  }
+
+  document.getElementById("lookup").addEventListener("submit", function (e) {
+    e.preventDefault();
+    var key = document.getElementById("key").value;
+    if (!key) {
+      return;
+    }
+    fetch("api/lookup?key=" + encodeURIComponent(key)).then(function (r) {
+      return r.json();
+    }).then(function (l) {
+      selected = l.partition;
+      document.getElementById("result").textContent = "hash:      " + l.hash + "\npartition: " + l.partition +
+        "\nowner:     " + l.owner + (l.backups.length ? "\nbackups:   " + l.backups.join(", ") : "");
+      refresh();
+    });
+  });
+
+  refresh();
+  setInterval(refresh, 5000);
+})();
@@ -0,0 +1,7 @@
+module github.com/buraksezer/consistent/ringui
+
+go 1.16
+
+require github.com/buraksezer/consistent v0.0.0
+
+replace github.com/buraksezer/consistent => ../
@@ -0,0 +1,179 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package ringui serves a web page which visualizes a consistent hash ring: the virtual nodes of the members
+// around the circle, the partitions colored by their owners, the loads of the members and a box to look up keys.
+// It's meant for demos, debugging and spot checks by operators. The assets are embedded, the page needs no
+// network access:
+//
+//	http.Handle("/ring/", http.StripPrefix("/ring", ringui.Handler(c)))
+//
+// The page polls the ring every few seconds, so it follows membership changes. It exposes the member names and
+// looks up arbitrary keys, don't serve it to untrusted clients.
+package ringui
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/buraksezer/consistent"
+)
+
+//go:embed assets
+var assets embed.FS
+
+// Ring is the state of the ring served at api/ring.
+type Ring struct {
+	Version        uint64      `json:"version"`
+	PartitionCount int         `json:"partitionCount"`
+	AverageLoad    float64     `json:"averageLoad"`
+	Members        []Member    `json:"members"`
+	Partitions     []Partition `json:"partitions"`
+}
+
+// Member is a member of the ring.
+type Member struct {
+	Name string `json:"name"`
+
+	// Load is the number of partitions the member owns.
+	Load float64 `json:"load"`
+
+	// Points are the positions of the virtual nodes on the circle, from 0 to 1.
+	Points []float64 `json:"points"`
+}
+
+// Partition is a partition of the ring.
+type Partition struct {
+	ID    int    `json:"id"`
+	Owner string `json:"owner"`
+
+	// Point is the position of the partition on the circle, from 0 to 1.
+	Point float64 `json:"point"`
+}
+
+// Lookup is the location of a key served at api/lookup.
+type Lookup struct {
+	Key string `json:"key"`
+
+	// Hash is the decimal KeyHash of the key. It's a string, JavaScript numbers can't hold every uint64.
+	Hash      string   `json:"hash"`
+	Partition int      `json:"partition"`
+	Owner     string   `json:"owner"`
+	Backups   []string `json:"backups"`
+}
+
+// Handler returns the handler of the page. It serves the page at the root of its path, the state of the ring at
+// api/ring and the location of the key in the query parameter key at api/lookup. Mount it on a path which ends
+// with a slash, the page uses relative URLs.
+func Handler(c *consistent.Consistent) http.Handler {
+	static, err := fs.Sub(assets, "assets")
+	if err != nil {
+		panic(err)
+	}
+	files := http.FileServer(http.FS(static))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/ring":
+			writeJSON(w, ringState(c))
+		case "/api/lookup":
+			key := r.URL.Query().Get("key")
+			if key == "" {
+				http.Error(w, "missing key", http.StatusBadRequest)
+				return
+			}
+			writeJSON(w, lookup(c, key))
+		default:
+			files.ServeHTTP(w, r)
+		}
+	})
+}
+
+// ringState returns the state of the ring.
+func ringState(c *consistent.Consistent) Ring {
+	s := c.Snapshot()
+	loads := c.LoadDistribution()
+	ring := Ring{
+		Version:        s.Version,
+		PartitionCount: s.Config.PartitionCount,
+		AverageLoad:    c.AverageLoad(),
+		Members:        make([]Member, 0, len(s.Members)),
+		Partitions:     make([]Partition, 0, len(s.Partitions)),
+	}
+	for _, member := range s.Members {
+		name := member.String()
+		m := Member{Name: name, Load: loads[name]}
+		for _, h := range s.VirtualNodes[name] {
+			m.Points = append(m.Points, position(h))
+		}
+		sort.Float64s(m.Points)
+		ring.Members = append(ring.Members, m)
+	}
+	for partID, owner := range s.Partitions {
+		ring.Partitions = append(ring.Partitions, Partition{
+			ID:    partID,
+			Owner: owner,
+			Point: position(c.PartitionPoint(partID)),
+		})
+	}
+	return ring
+}
+
+// lookup returns the location of the key.
+func lookup(c *consistent.Consistent, key string) Lookup {
+	h := c.KeyHash([]byte(key))
+	l := Lookup{
+		Key:       key,
+		Hash:      strconv.FormatUint(h, 10),
+		Partition: c.PartitionForHash(h),
+		Backups:   []string{},
+	}
+	for i, member := range c.GetPartitionOwnerAndBackups(l.Partition) {
+		if i == 0 {
+			l.Owner = member.String()
+			continue
+		}
+		l.Backups = append(l.Backups, member.String())
+	}
+	return l
+}
+
+// position returns the position of the point on the circle, from 0 to 1.
+func position(h uint64) float64 {
+	return float64(h) / math.Exp2(64)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(v)
+}
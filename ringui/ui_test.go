@@ -0,0 +1,154 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ringui
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/buraksezer/consistent"
+)
+
+type hasher struct{}
+
+func (hasher) Sum64(data []byte) uint64 {
+	h := fnv.New64()
+	_, _ = h.Write(data)
+	return h.Sum64()
+}
+
+type member string
+
+func (m member) String() string {
+	return string(m)
+}
+
+func newRing() *consistent.Consistent {
+	var members []consistent.Member
+	for i := 0; i < 4; i++ {
+		members = append(members, member(fmt.Sprintf("node%d.olric", i)))
+	}
+	return consistent.New(members, consistent.Config{
+		PartitionCount:    23,
+		ReplicationFactor: 20,
+		Load:              1.25,
+		BackupCount:       1,
+		Hasher:            hasher{},
+	})
+}
+
+func get(t *testing.T, h http.Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestHandlerPage(t *testing.T) {
+	h := Handler(newRing())
+	for _, path := range []string{"/", "/app.js", "/style.css"} {
+		rec := get(t, h, path)
+		if rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+			t.Fatalf("%s: expected the asset, Got: %d", path, rec.Code)
+		}
+	}
+	if body := get(t, h, "/").Body.String(); !strings.Contains(body, "<svg") {
+		t.Fatalf("Expected the page")
+	}
+	if rec := get(t, h, "/missing.js"); rec.Code != http.StatusNotFound {
+		t.Fatalf("Expected 404, Got: %d", rec.Code)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/ring", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("Expected 405, Got: %d", rec.Code)
+	}
+}
+
+func TestHandlerRing(t *testing.T) {
+	c := newRing()
+	rec := get(t, Handler(c), "/api/ring")
+	var ring Ring
+	if err := json.NewDecoder(rec.Body).Decode(&ring); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if ring.Version != c.Version() || ring.PartitionCount != 23 || len(ring.Partitions) != 23 {
+		t.Fatalf("Unexpected ring: %+v", ring)
+	}
+	if len(ring.Members) != 4 {
+		t.Fatalf("Expected 4 members, Got: %d", len(ring.Members))
+	}
+	var total float64
+	for _, m := range ring.Members {
+		if len(m.Points) != 20 {
+			t.Fatalf("Expected 20 virtual nodes, Got: %d", len(m.Points))
+		}
+		for _, p := range m.Points {
+			if p < 0 || p >= 1 {
+				t.Fatalf("Expected a position in [0, 1), Got: %g", p)
+			}
+		}
+		total += m.Load
+	}
+	if total != 23 {
+		t.Fatalf("Expected 23 partitions, Got: %g", total)
+	}
+	for _, p := range ring.Partitions {
+		if p.Owner != c.GetPartitionOwner(p.ID).String() {
+			t.Fatalf("Partition %d: expected owner %s, Got: %s", p.ID, c.GetPartitionOwner(p.ID), p.Owner)
+		}
+	}
+}
+
+func TestHandlerLookup(t *testing.T) {
+	c := newRing()
+	h := Handler(c)
+	var l Lookup
+	if err := json.NewDecoder(get(t, h, "/api/lookup?key=Olric").Body).Decode(&l); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if l.Owner != c.LocateKey([]byte("Olric")).String() || l.Partition != c.FindPartitionID([]byte("Olric")) {
+		t.Fatalf("Unexpected lookup: %+v", l)
+	}
+	if len(l.Backups) != 1 || l.Backups[0] == l.Owner {
+		t.Fatalf("Expected a backup, Got: %v", l.Backups)
+	}
+	if rec := get(t, h, "/api/lookup"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400, Got: %d", rec.Code)
+	}
+
+	// An empty ring has no owners.
+	empty := Handler(consistent.New(nil, consistent.Config{Hasher: hasher{}}))
+	l = Lookup{}
+	if err := json.NewDecoder(get(t, empty, "/api/lookup?key=Olric").Body).Decode(&l); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if l.Owner != "" {
+		t.Fatalf("Expected no owner, Got: %s", l.Owner)
+	}
+}
@@ -0,0 +1,7 @@
+module github.com/buraksezer/consistent/router
+
+go 1.18
+
+require github.com/buraksezer/consistent v0.0.0
+
+replace github.com/buraksezer/consistent => ../
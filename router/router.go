@@ -0,0 +1,307 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package router calls the member which owns a key and fails over to the backups of its partition, the
+// pattern most users of a consistent hash ring reimplement:
+//
+//	r := router.New[[]byte](c, router.Config{})
+//	value, err := r.Do(ctx, key, func(ctx context.Context, m consistent.Member) ([]byte, error) {
+//		return fetch(ctx, m, key)
+//	})
+//
+// Every member has a circuit breaker. After Config.FailureThreshold consecutive failures the breaker opens and
+// the member is skipped for Config.Cooldown, then a single call probes it again. It needs Go 1.18 for the
+// type parameter.
+package router
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/buraksezer/consistent"
+)
+
+// ErrNoMember represents an error which means that the ring has no member to call, or the breakers of all the
+// candidates for the key are open.
+var ErrNoMember = errors.New("no available member")
+
+const (
+	// DefaultFailureThreshold is the number of consecutive failures which opens the breaker of a member.
+	DefaultFailureThreshold = 5
+
+	// DefaultCooldown is the time a breaker stays open before the member is probed again.
+	DefaultCooldown = 30 * time.Second
+)
+
+// Config configures a Router. The zero value is usable.
+type Config struct {
+	// Attempts is the maximum number of members a call tries, the owner included. Zero tries the owner and all
+	// of its backups. If the ring has fewer backups than Attempts-1, the rest of the members are tried in the
+	// order of GetClosestNForPartition.
+	Attempts int
+
+	// FailureThreshold is the number of consecutive failures which opens the breaker of a member. Zero means
+	// DefaultFailureThreshold.
+	FailureThreshold int
+
+	// Cooldown is the time a breaker stays open. Zero means DefaultCooldown.
+	Cooldown time.Duration
+
+	// Retryable reports whether an error returned by the call should fail over to the next member. If it's nil,
+	// every error except context.Canceled and context.DeadlineExceeded is retried. Errors which are not
+	// retried are returned as they are and don't count against the breaker.
+	Retryable func(err error) bool
+
+	// Clock tells the time for the breakers. If it's nil, the wall clock is used.
+	Clock consistent.Clock
+}
+
+// BreakerState is the state of the circuit breaker of a member.
+type BreakerState int
+
+const (
+	// Closed means the member is called.
+	Closed BreakerState = iota
+
+	// Open means the member is skipped until the cooldown elapses.
+	Open
+
+	// HalfOpen means the cooldown elapsed and the next call probes the member.
+	HalfOpen
+)
+
+// String returns the name of the state.
+func (s BreakerState) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+type breaker struct {
+	failures int
+	open     bool
+	until    time.Time
+	probing  bool
+}
+
+// Router calls the owners of keys with failover to their backups. It's safe for concurrent use.
+type Router[T any] struct {
+	ring *consistent.Consistent
+	cfg  Config
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+// New creates a router for the given ring.
+func New[T any](c *consistent.Consistent, cfg Config) *Router[T] {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = DefaultFailureThreshold
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = DefaultCooldown
+	}
+	return &Router[T]{
+		ring:     c,
+		cfg:      cfg,
+		breakers: make(map[string]*breaker),
+	}
+}
+
+// Do calls f with the owner of the key. If f returns a retryable error, it calls f with the next backup, until
+// f succeeds, the attempts are used up or the context is done. Members whose breakers are open are skipped.
+// It returns the result of the first successful call, otherwise the last error of f, the error of the
+// context or ErrNoMember if no member could be called.
+func (r *Router[T]) Do(ctx context.Context, key []byte, f func(ctx context.Context, member consistent.Member) (T, error)) (T, error) {
+	var zero T
+	err := ErrNoMember
+	for _, member := range r.candidates(key) {
+		if cerr := ctx.Err(); cerr != nil {
+			return zero, cerr
+		}
+		name := member.String()
+		if !r.allow(name) {
+			continue
+		}
+		res, ferr := r.call(ctx, member, f)
+		if ferr == nil {
+			r.succeed(name)
+			return res, nil
+		}
+		if !r.retryable(ferr) {
+			r.release(name)
+			return zero, ferr
+		}
+		r.fail(name)
+		err = ferr
+	}
+	return zero, err
+}
+
+// call calls f with the member. If f panics, it ends a probe of the member before the panic goes on, so the
+// breaker doesn't stay half-open with a probe which never finishes.
+func (r *Router[T]) call(ctx context.Context, member consistent.Member, f func(ctx context.Context, member consistent.Member) (T, error)) (T, error) {
+	returned := false
+	defer func() {
+		if !returned {
+			r.release(member.String())
+		}
+	}()
+	res, err := f(ctx, member)
+	returned = true
+	return res, err
+}
+
+// State returns the state of the breaker of the member with the given name.
+func (r *Router[T]) State(name string) BreakerState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.breakers[name]
+	if !ok || !b.open {
+		return Closed
+	}
+	if r.now().Before(b.until) {
+		return Open
+	}
+	return HalfOpen
+}
+
+// Reset closes the breaker of the member with the given name, e.g. after it was replaced.
+func (r *Router[T]) Reset(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.breakers, name)
+}
+
+// candidates returns the members to try for the key in order: the owner, the backups, then the closest members.
+func (r *Router[T]) candidates(key []byte) []consistent.Member {
+	partID := r.ring.FindPartitionID(key)
+	res := r.ring.GetPartitionOwnerAndBackups(partID)
+	attempts := r.cfg.Attempts
+	if attempts <= 0 {
+		return res
+	}
+	if len(res) >= attempts {
+		return res[:attempts]
+	}
+	count := attempts
+	if n := len(r.ring.GetMembers()); count > n {
+		count = n
+	}
+	closest, err := r.ring.GetClosestNForPartition(partID, count)
+	if err != nil {
+		return res
+	}
+	for _, member := range closest {
+		if len(res) == attempts {
+			break
+		}
+		if !contains(res, member) {
+			res = append(res, member)
+		}
+	}
+	return res
+}
+
+func contains(members []consistent.Member, member consistent.Member) bool {
+	for _, m := range members {
+		if m.String() == member.String() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router[T]) retryable(err error) bool {
+	if r.cfg.Retryable != nil {
+		return r.cfg.Retryable(err)
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+func (r *Router[T]) now() time.Time {
+	if r.cfg.Clock != nil {
+		return r.cfg.Clock.Now()
+	}
+	return time.Now()
+}
+
+// allow reports whether the member may be called. Once the cooldown of an open breaker elapsed, it lets a
+// single call through to probe the member.
+func (r *Router[T]) allow(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.breakers[name]
+	if !ok || !b.open {
+		return true
+	}
+	if b.probing || r.now().Before(b.until) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (r *Router[T]) succeed(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.breakers, name)
+}
+
+// release ends a probe without a verdict, the breaker stays as it was.
+func (r *Router[T]) release(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if b, ok := r.breakers[name]; ok {
+		b.probing = false
+	}
+}
+
+func (r *Router[T]) fail(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.breakers[name]
+	if !ok {
+		b = &breaker{}
+		r.breakers[name] = b
+	}
+	b.failures++
+	if b.probing || b.failures >= r.cfg.FailureThreshold {
+		b.open = true
+		b.until = r.now().Add(r.cfg.Cooldown)
+	}
+	b.probing = false
+}
@@ -0,0 +1,292 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/buraksezer/consistent"
+)
+
+type hasher struct{}
+
+func (hasher) Sum64(data []byte) uint64 {
+	h := fnv.New64()
+	_, _ = h.Write(data)
+	return h.Sum64()
+}
+
+type member string
+
+func (m member) String() string {
+	return string(m)
+}
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) AfterFunc(time.Duration, func()) consistent.Timer {
+	panic("not used")
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func newRing(backups int) *consistent.Consistent {
+	var members []consistent.Member
+	for i := 0; i < 4; i++ {
+		members = append(members, member(fmt.Sprintf("node%d.olric", i)))
+	}
+	return consistent.New(members, consistent.Config{
+		PartitionCount:    23,
+		ReplicationFactor: 20,
+		Load:              1.25,
+		BackupCount:       backups,
+		Hasher:            hasher{},
+	})
+}
+
+var errDown = errors.New("down")
+
+func TestRouterFailover(t *testing.T) {
+	c := newRing(2)
+	r := New[string](c, Config{})
+	key := []byte("my-key")
+	want := c.GetPartitionOwnerAndBackups(c.FindPartitionID(key))
+
+	var called []string
+	res, err := r.Do(context.Background(), key, func(_ context.Context, m consistent.Member) (string, error) {
+		called = append(called, m.String())
+		if len(called) < 3 {
+			return "", errDown
+		}
+		return "value from " + m.String(), nil
+	})
+	if err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if len(called) != 3 {
+		t.Fatalf("Expected 3 calls, got: %v", called)
+	}
+	for i, name := range called {
+		if name != want[i].String() {
+			t.Fatalf("Expected call %d on %s, got: %s", i, want[i], name)
+		}
+	}
+	if res != "value from "+want[2].String() {
+		t.Fatalf("Unexpected result: %s", res)
+	}
+}
+
+func TestRouterExhausted(t *testing.T) {
+	c := newRing(1)
+	r := New[int](c, Config{Attempts: 3})
+
+	var calls int
+	_, err := r.Do(context.Background(), []byte("my-key"), func(context.Context, consistent.Member) (int, error) {
+		calls++
+		return 0, errDown
+	})
+	if err != errDown {
+		t.Fatalf("Expected errDown, got: %v", err)
+	}
+	// One backup, the third attempt goes to the closest remaining member.
+	if calls != 3 {
+		t.Fatalf("Expected 3 calls, got: %d", calls)
+	}
+}
+
+func TestRouterNotRetryable(t *testing.T) {
+	c := newRing(2)
+	errMissing := errors.New("missing")
+	r := New[int](c, Config{Retryable: func(err error) bool { return err != errMissing }})
+
+	var calls int
+	_, err := r.Do(context.Background(), []byte("my-key"), func(context.Context, consistent.Member) (int, error) {
+		calls++
+		return 0, errMissing
+	})
+	if err != errMissing {
+		t.Fatalf("Expected errMissing, got: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("Expected 1 call, got: %d", calls)
+	}
+}
+
+func TestRouterBreaker(t *testing.T) {
+	c := newRing(2)
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	r := New[int](c, Config{FailureThreshold: 2, Cooldown: time.Minute, Clock: clock})
+	key := []byte("my-key")
+	owner := c.LocateKey(key).String()
+
+	down := true
+	call := func(_ context.Context, m consistent.Member) (int, error) {
+		if m.String() == owner && down {
+			return 0, errDown
+		}
+		return 1, nil
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Do(context.Background(), key, call); err != nil {
+			t.Fatalf("Expected nil, got: %v", err)
+		}
+	}
+	if s := r.State(owner); s != Open {
+		t.Fatalf("Expected open, got: %s", s)
+	}
+
+	var skipped bool
+	_, _ = r.Do(context.Background(), key, func(ctx context.Context, m consistent.Member) (int, error) {
+		skipped = m.String() != owner
+		return call(ctx, m)
+	})
+	if !skipped {
+		t.Fatalf("Expected %s to be skipped", owner)
+	}
+
+	clock.Advance(time.Minute)
+	if s := r.State(owner); s != HalfOpen {
+		t.Fatalf("Expected half-open, got: %s", s)
+	}
+	// A failed probe opens the breaker again.
+	_, _ = r.Do(context.Background(), key, call)
+	if s := r.State(owner); s != Open {
+		t.Fatalf("Expected open, got: %s", s)
+	}
+
+	clock.Advance(time.Minute)
+	down = false
+	var got string
+	_, _ = r.Do(context.Background(), key, func(ctx context.Context, m consistent.Member) (int, error) {
+		got = m.String()
+		return call(ctx, m)
+	})
+	if got != owner {
+		t.Fatalf("Expected the probe on %s, got: %s", owner, got)
+	}
+	if s := r.State(owner); s != Closed {
+		t.Fatalf("Expected closed, got: %s", s)
+	}
+}
+
+func TestRouterProbePanic(t *testing.T) {
+	c := newRing(2)
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	r := New[int](c, Config{FailureThreshold: 1, Cooldown: time.Minute, Clock: clock})
+	key := []byte("my-key")
+	owner := c.LocateKey(key).String()
+
+	_, _ = r.Do(context.Background(), key, func(_ context.Context, m consistent.Member) (int, error) {
+		if m.String() == owner {
+			return 0, errDown
+		}
+		return 1, nil
+	})
+	clock.Advance(time.Minute)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("Expected the panic of f")
+			}
+		}()
+		_, _ = r.Do(context.Background(), key, func(context.Context, consistent.Member) (int, error) {
+			panic("boom")
+		})
+	}()
+	if s := r.State(owner); s != HalfOpen {
+		t.Fatalf("Expected half-open, got: %s", s)
+	}
+
+	var got string
+	_, _ = r.Do(context.Background(), key, func(_ context.Context, m consistent.Member) (int, error) {
+		got = m.String()
+		return 1, nil
+	})
+	if got != owner {
+		t.Fatalf("Expected another probe on %s, got: %s", owner, got)
+	}
+	if s := r.State(owner); s != Closed {
+		t.Fatalf("Expected closed, got: %s", s)
+	}
+}
+
+func TestRouterAllOpen(t *testing.T) {
+	c := newRing(0)
+	r := New[int](c, Config{FailureThreshold: 1})
+	key := []byte("my-key")
+	fail := func(context.Context, consistent.Member) (int, error) {
+		return 0, errDown
+	}
+	if _, err := r.Do(context.Background(), key, fail); err != errDown {
+		t.Fatalf("Expected errDown, got: %v", err)
+	}
+	if _, err := r.Do(context.Background(), key, fail); err != ErrNoMember {
+		t.Fatalf("Expected ErrNoMember, got: %v", err)
+	}
+	r.Reset(c.LocateKey(key).String())
+	if _, err := r.Do(context.Background(), key, fail); err != errDown {
+		t.Fatalf("Expected errDown, got: %v", err)
+	}
+}
+
+func TestRouterContext(t *testing.T) {
+	c := newRing(2)
+	r := New[int](c, Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var calls int
+	_, err := r.Do(ctx, []byte("my-key"), func(ctx context.Context, m consistent.Member) (int, error) {
+		calls++
+		cancel()
+		return 0, ctx.Err()
+	})
+	if err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("Expected 1 call, got: %d", calls)
+	}
+	if s := r.State(c.LocateKey([]byte("my-key")).String()); s != Closed {
+		t.Fatalf("Expected closed, got: %s", s)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// RoutingTable is a copy of the partition table meant for client-side routing: thin clients locate keys with
+// FindPartitionID and look the owner up in the table instead of running a ring. It holds plain names and numbers,
+// so it's cheap to encode with encoding/json or encoding/gob.
+type RoutingTable struct {
+	// Version is the version of the partition table.
+	Version uint64
+
+	// Owners maps partition IDs to the names of their owners. Partitions without an owner are missing.
+	Owners map[int]string
+}
+
+// RoutingDelta contains the entries of a routing table which changed between two versions, see
+// RoutingTable.Delta. Shipping deltas instead of whole tables keeps frequent updates small.
+type RoutingDelta struct {
+	// From is the version the delta applies to.
+	From uint64
+
+	// To is the version of the routing table after applying the delta.
+	To uint64
+
+	// Changed maps partition IDs to the names of their new owners. An empty name means that the partition has
+	// no owner anymore.
+	Changed map[int]string
+}
+
+// RoutingTable returns the current partition table as a RoutingTable.
+func (c *Consistent) RoutingTable() RoutingTable {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	t := RoutingTable{
+		Version: c.version,
+		Owners:  make(map[int]string, len(c.partitions)),
+	}
+	for partID, owner := range c.partitions {
+		t.Owners[partID] = (*owner).String()
+	}
+	return t
+}
+
+// Owner returns the name of the owner of the partition, or an empty string if it has none.
+func (t RoutingTable) Owner(partID int) string {
+	return t.Owners[partID]
+}
+
+// Delta returns the entries of next which differ from t.
+func (t RoutingTable) Delta(next RoutingTable) RoutingDelta {
+	d := RoutingDelta{
+		From:    t.Version,
+		To:      next.Version,
+		Changed: make(map[int]string),
+	}
+	for partID, name := range next.Owners {
+		if t.Owners[partID] != name {
+			d.Changed[partID] = name
+		}
+	}
+	for partID := range t.Owners {
+		if _, ok := next.Owners[partID]; !ok {
+			d.Changed[partID] = ""
+		}
+	}
+	return d
+}
+
+// Apply returns the routing table with the delta applied. t isn't modified. It returns ErrVersionMismatch if
+// the delta wasn't computed from the version of t; fetch the whole table in that case.
+func (t RoutingTable) Apply(d RoutingDelta) (RoutingTable, error) {
+	if d.From != t.Version {
+		return RoutingTable{}, ErrVersionMismatch
+	}
+	res := RoutingTable{
+		Version: d.To,
+		Owners:  make(map[int]string, len(t.Owners)),
+	}
+	for partID, name := range t.Owners {
+		res.Owners[partID] = name
+	}
+	for partID, name := range d.Changed {
+		if name == "" {
+			delete(res.Owners, partID)
+			continue
+		}
+		res.Owners[partID] = name
+	}
+	return res, nil
+}
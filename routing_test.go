@@ -0,0 +1,119 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestRoutingTable(t *testing.T) {
+	var members []Member
+	for i := 0; i < 4; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c := New(members, newConfig())
+	table := c.RoutingTable()
+	if table.Version != c.Version() || len(table.Owners) != 23 {
+		t.Fatalf("Unexpected routing table: %v", table)
+	}
+	key := []byte("Olric")
+	if owner := table.Owner(c.FindPartitionID(key)); owner != c.LocateKey(key).String() {
+		t.Fatalf("Expected %s, Got: %s", c.LocateKey(key), owner)
+	}
+
+	c.Add(testMember("node4.olric"))
+	next := c.RoutingTable()
+	delta := table.Delta(next)
+	if len(delta.Changed) == 0 || len(delta.Changed) != len(table.Owners)-countSame(table, next) {
+		t.Fatalf("Unexpected delta: %v", delta)
+	}
+	applied, err := table.Apply(delta)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if !reflect.DeepEqual(applied, next) {
+		t.Fatalf("Expected the new routing table, Got: %v", applied)
+	}
+	if _, err := next.Apply(delta); err != ErrVersionMismatch {
+		t.Fatalf("Expected ErrVersionMismatch, Got: %v", err)
+	}
+
+	// Partitions lose their owners when the ring becomes empty.
+	for _, member := range c.GetMembers() {
+		c.Remove(member.String())
+	}
+	applied, err = next.Apply(next.Delta(c.RoutingTable()))
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if len(applied.Owners) != 0 {
+		t.Fatalf("Expected an empty routing table, Got: %v", applied)
+	}
+}
+
+func countSame(a, b RoutingTable) int {
+	var same int
+	for partID, name := range a.Owners {
+		if b.Owners[partID] == name {
+			same++
+		}
+	}
+	return same
+}
+
+func TestRoutingTableEncoding(t *testing.T) {
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, newConfig())
+	table := c.RoutingTable()
+
+	data, err := json.Marshal(table)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	var decoded RoutingTable
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, table) {
+		t.Fatalf("Expected %v, Got: %v", table, decoded)
+	}
+
+	var buf bytes.Buffer
+	c.Add(testMember("node2.olric"))
+	delta := table.Delta(c.RoutingTable())
+	if err := gob.NewEncoder(&buf).Encode(delta); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	var decodedDelta RoutingDelta
+	if err := gob.NewDecoder(&buf).Decode(&decodedDelta); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if !reflect.DeepEqual(decodedDelta, delta) {
+		t.Fatalf("Expected %v, Got: %v", delta, decodedDelta)
+	}
+}
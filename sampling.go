@@ -0,0 +1,47 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"sync/atomic"
+)
+
+// KeySampler receives a sample of the keys located by LocateKey, see Config.KeySampler. It's meant for hot key
+// detectors, which may feed their findings back into the ring, e.g. with SetMaxLoad or member weights.
+// Implementations must be safe for concurrent use and fast, they are called on the lookup path, without holding
+// the lock of the ring.
+type KeySampler interface {
+	// SampleKey is called with the KeyHash of a located key, its partition and the member LocateKey returned,
+	// which is nil if the ring is empty.
+	SampleKey(hash uint64, partID int, member Member)
+}
+
+// sample passes every Config.SampleEvery-th lookup to Config.KeySampler.
+func (c *Consistent) sample(key []byte, member Member) {
+	if every := c.config.SampleEvery; every > 1 && atomic.AddUint32(&c.lookups, 1)%uint32(every) != 0 {
+		return
+	}
+	h := c.KeyHash(key)
+	c.config.KeySampler.SampleKey(h, c.PartitionForHash(h), member)
+}
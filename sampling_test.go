@@ -0,0 +1,101 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+type testSampler struct {
+	mu      sync.Mutex
+	samples map[int]int
+	owners  map[int]string
+}
+
+func (s *testSampler) SampleKey(hash uint64, partID int, member Member) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.samples == nil {
+		s.samples = make(map[int]int)
+		s.owners = make(map[int]string)
+	}
+	s.samples[partID]++
+	if member != nil {
+		s.owners[partID] = member.String()
+	}
+}
+
+func (s *testSampler) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int
+	for _, count := range s.samples {
+		n += count
+	}
+	return n
+}
+
+func TestKeySampler(t *testing.T) {
+	sampler := &testSampler{}
+	cfg := newConfig()
+	cfg.KeySampler = sampler
+	cfg.SampleEvery = 10
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, cfg)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 250; j++ {
+				c.LocateKey([]byte(fmt.Sprintf("key-%d-%d", i, j)))
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := sampler.count(); n != 100 {
+		t.Fatalf("Expected 100 samples, Got: %d", n)
+	}
+	for partID, owner := range sampler.owners {
+		if owner != c.GetPartitionOwner(partID).String() {
+			t.Fatalf("Partition %d: expected owner %s, Got: %s", partID, c.GetPartitionOwner(partID), owner)
+		}
+	}
+}
+
+func TestKeySamplerHotKey(t *testing.T) {
+	sampler := &testSampler{}
+	cfg := newConfig()
+	cfg.KeySampler = sampler
+	c := New([]Member{testMember("node0.olric")}, cfg)
+	for i := 0; i < 5; i++ {
+		c.LocateKey([]byte("hot"))
+	}
+	c.LocateKey([]byte("cold"))
+	if sampler.samples[c.FindPartitionID([]byte("hot"))] < 5 || sampler.count() != 6 {
+		t.Fatalf("Expected every lookup to be sampled, Got: %v", sampler.samples)
+	}
+}
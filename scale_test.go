@@ -0,0 +1,107 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newLargeCluster(tb testing.TB, memberCount int) (*Consistent, Config) {
+	cfg := Config{
+		PartitionCount:    271,
+		ReplicationFactor: 10,
+		Load:              1.25,
+		BackupCount:       2,
+		Hasher:            hasher{},
+	}
+	members := make([]Member, 0, memberCount)
+	for i := 0; i < memberCount; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	return New(members, cfg), cfg
+}
+
+func TestConsistentLargeCluster(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large cluster test in short mode")
+	}
+	const memberCount = 100000
+
+	start := time.Now()
+	c, cfg := newLargeCluster(t, memberCount)
+	build := time.Since(start)
+	if c.Stats().VirtualNodes != memberCount*cfg.ReplicationFactor {
+		t.Fatalf("Expected %d virtual nodes, Got: %d", memberCount*cfg.ReplicationFactor, c.Stats().VirtualNodes)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	start = time.Now()
+	const changes = 20
+	for i := 0; i < changes; i++ {
+		member := testMember(fmt.Sprintf("new-node%d.olric", i))
+		c.Add(member)
+		c.Remove(member.String())
+	}
+	mutation := time.Since(start) / (2 * changes)
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	t.Logf("build: %v, mutation: %v", build, mutation)
+
+	// The bounds are deliberately generous, they only catch quadratic regressions.
+	if build > 30*time.Second {
+		t.Fatalf("Building a ring with %d members took %v", memberCount, build)
+	}
+	if mutation > time.Second {
+		t.Fatalf("A membership change on a ring with %d members took %v", memberCount, mutation)
+	}
+}
+
+func BenchmarkNewLargeCluster(b *testing.B) {
+	for _, memberCount := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprintf("%d members", memberCount), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				newLargeCluster(b, memberCount)
+			}
+		})
+	}
+}
+
+func BenchmarkAddRemoveLargeCluster(b *testing.B) {
+	for _, memberCount := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprintf("%d members", memberCount), func(b *testing.B) {
+			c, _ := newLargeCluster(b, memberCount)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				member := testMember("new-node" + fmt.Sprint(i))
+				c.Add(member)
+				c.Remove(member.String())
+			}
+		})
+	}
+}
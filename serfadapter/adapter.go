@@ -0,0 +1,186 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package serfadapter keeps a consistent hash ring in sync with the members of a serf cluster.
+//
+// Feed the serf event channel to Run, or call HandleEvent from an existing event loop:
+//
+//	events := make(chan serf.Event, 64)
+//	conf := serf.DefaultConfig()
+//	conf.EventCh = events
+//	...
+//	a := serfadapter.New(ring, serfadapter.Config{FlapDamping: 10 * time.Second})
+//	go a.Run(ctx, events)
+//
+// Joined members are added right away. Members which leave or fail stay on the ring for Config.FlapDamping, and
+// are kept if they rejoin in the meantime, so a flapping member doesn't move its partitions back and forth.
+package serfadapter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/buraksezer/consistent"
+	"github.com/hashicorp/serf/serf"
+)
+
+// Member is a serf member on the consistent hash ring. It's identified by its serf node name.
+type Member struct {
+	serf.Member
+}
+
+// String returns the serf node name of the member.
+func (m Member) String() string {
+	return m.Name
+}
+
+// Config configures an Adapter.
+type Config struct {
+	// FlapDamping is the time a member stays on the ring after it left or failed. If it rejoins before that,
+	// the ring is not modified. Zero removes members immediately.
+	FlapDamping time.Duration
+
+	// NewMember converts a serf member into a ring member, e.g. to derive a weight from its tags. The default
+	// returns a Member. The result must use the serf node name as its name.
+	NewMember func(serf.Member) consistent.Member
+}
+
+// Adapter applies serf membership events to a consistent hash ring.
+type Adapter struct {
+	mu      sync.Mutex
+	ring    *consistent.Consistent
+	config  Config
+	pending map[string]*time.Timer
+	stopped bool
+}
+
+// New returns an Adapter which keeps ring in sync with serf membership events.
+func New(ring *consistent.Consistent, config Config) *Adapter {
+	if config.NewMember == nil {
+		config.NewMember = func(m serf.Member) consistent.Member {
+			return Member{Member: m}
+		}
+	}
+	return &Adapter{
+		ring:    ring,
+		config:  config,
+		pending: make(map[string]*time.Timer),
+	}
+}
+
+// Run applies the events read from events until ctx is done or events is closed. Pending removals are
+// cancelled when it returns, see Stop.
+func (a *Adapter) Run(ctx context.Context, events <-chan serf.Event) error {
+	defer a.Stop()
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			a.HandleEvent(e)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// HandleEvent applies a serf event to the ring. Events other than member events are ignored.
+func (a *Adapter) HandleEvent(e serf.Event) {
+	me, ok := e.(serf.MemberEvent)
+	if !ok {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.stopped {
+		return
+	}
+	for _, m := range me.Members {
+		switch me.Type {
+		case serf.EventMemberJoin:
+			a.cancel(m.Name)
+			a.ring.Add(a.config.NewMember(m))
+		case serf.EventMemberLeave, serf.EventMemberFailed:
+			a.scheduleRemove(m.Name)
+		case serf.EventMemberReap:
+			// Serf forgot about the member, there is nothing to wait for.
+			a.cancel(m.Name)
+			a.ring.Remove(m.Name)
+		}
+	}
+}
+
+// Stop cancels the pending removals and makes the adapter ignore further events.
+func (a *Adapter) Stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.stopped = true
+	for name := range a.pending {
+		a.cancel(name)
+	}
+}
+
+// scheduleRemove removes the member after Config.FlapDamping. It's not thread-safe.
+func (a *Adapter) scheduleRemove(name string) {
+	if a.config.FlapDamping <= 0 {
+		a.ring.Remove(name)
+		return
+	}
+	if _, ok := a.pending[name]; ok {
+		return
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(a.config.FlapDamping, func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		if a.pending[name] != timer {
+			// Cancelled, or rescheduled after a rejoin.
+			return
+		}
+		delete(a.pending, name)
+		a.ring.Remove(name)
+	})
+	a.pending[name] = timer
+}
+
+// cancel cancels the pending removal of the member, if any. It's not thread-safe.
+func (a *Adapter) cancel(name string) {
+	if timer, ok := a.pending[name]; ok {
+		timer.Stop()
+		delete(a.pending, name)
+	}
+}
+
+// Pending returns the number of members waiting for removal.
+func (a *Adapter) Pending() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return len(a.pending)
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package serfadapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/buraksezer/consistent"
+	"github.com/buraksezer/consistent/hashers"
+	"github.com/hashicorp/serf/serf"
+)
+
+func newRing() *consistent.Consistent {
+	return consistent.New(nil, consistent.Config{
+		PartitionCount:    23,
+		ReplicationFactor: 20,
+		Load:              1.25,
+		Hasher:            hashers.FNV1a{},
+	})
+}
+
+func event(typ serf.EventType, names ...string) serf.MemberEvent {
+	e := serf.MemberEvent{Type: typ}
+	for _, name := range names {
+		e.Members = append(e.Members, serf.Member{Name: name, Status: serf.StatusAlive})
+	}
+	return e
+}
+
+func members(ring *consistent.Consistent) map[string]struct{} {
+	res := make(map[string]struct{})
+	for _, m := range ring.GetMembers() {
+		res[m.String()] = struct{}{}
+	}
+	return res
+}
+
+func TestAdapterJoinLeave(t *testing.T) {
+	ring := newRing()
+	a := New(ring, Config{})
+	a.HandleEvent(event(serf.EventMemberJoin, "node1", "node2", "node3"))
+	if len(members(ring)) != 3 {
+		t.Fatalf("Expected 3 members, Got: %v", members(ring))
+	}
+	a.HandleEvent(event(serf.EventMemberLeave, "node1"))
+	a.HandleEvent(event(serf.EventMemberFailed, "node2"))
+	if m := members(ring); len(m) != 1 {
+		t.Fatalf("Expected only node3, Got: %v", m)
+	}
+	// Non-member events are ignored.
+	a.HandleEvent(serf.UserEvent{Name: "deploy"})
+	if err := ring.Validate(); err != nil {
+		t.Fatalf("Expected no error, Got: %v", err)
+	}
+}
+
+func TestAdapterFlapDamping(t *testing.T) {
+	ring := newRing()
+	a := New(ring, Config{FlapDamping: 50 * time.Millisecond})
+	a.HandleEvent(event(serf.EventMemberJoin, "node1", "node2"))
+	version := ring.Version()
+
+	// A member which rejoins within the damping period stays on the ring.
+	a.HandleEvent(event(serf.EventMemberFailed, "node1"))
+	if a.Pending() != 1 {
+		t.Fatalf("Expected a pending removal")
+	}
+	a.HandleEvent(event(serf.EventMemberJoin, "node1"))
+	if a.Pending() != 0 || ring.Version() != version {
+		t.Fatalf("Expected no change in the ring")
+	}
+
+	a.HandleEvent(event(serf.EventMemberLeave, "node2"))
+	deadline := time.Now().Add(5 * time.Second)
+	for a.Pending() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("node2 is not removed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, ok := members(ring)["node2"]; ok {
+		t.Fatalf("Expected node2 to be removed")
+	}
+
+	// Reaped members are removed immediately.
+	a.HandleEvent(event(serf.EventMemberFailed, "node1"))
+	a.HandleEvent(event(serf.EventMemberReap, "node1"))
+	if a.Pending() != 0 || len(members(ring)) != 0 {
+		t.Fatalf("Expected an empty ring, Got: %v", members(ring))
+	}
+}
+
+type weightedMember struct {
+	name   string
+	weight float64
+}
+
+func (m weightedMember) String() string  { return m.name }
+func (m weightedMember) Weight() float64 { return m.weight }
+
+func TestAdapterNewMember(t *testing.T) {
+	ring := newRing()
+	a := New(ring, Config{NewMember: func(m serf.Member) consistent.Member {
+		weight := 1.0
+		if m.Tags["role"] == "large" {
+			weight = 3
+		}
+		return weightedMember{name: m.Name, weight: weight}
+	}})
+	e := event(serf.EventMemberJoin, "node1", "node2")
+	e.Members[1].Tags = map[string]string{"role": "large"}
+	a.HandleEvent(e)
+	loads := ring.LoadDistribution()
+	if loads["node2"] <= loads["node1"] {
+		t.Fatalf("Expected node2 to own more partitions: %v", loads)
+	}
+}
+
+func TestAdapterRun(t *testing.T) {
+	ring := newRing()
+	a := New(ring, Config{FlapDamping: time.Hour})
+	events := make(chan serf.Event)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- a.Run(ctx, events)
+	}()
+	events <- event(serf.EventMemberJoin, "node1")
+	events <- event(serf.EventMemberFailed, "node1")
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, Got: %v", err)
+	}
+	// Stop cancels the pending removal and further events are ignored.
+	a.HandleEvent(event(serf.EventMemberJoin, "node2"))
+	if m := members(ring); len(m) != 1 || a.Pending() != 0 {
+		t.Fatalf("Expected only node1 without pending removals, Got: %v", m)
+	}
+}
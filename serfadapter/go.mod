@@ -0,0 +1,10 @@
+module github.com/buraksezer/consistent/serfadapter
+
+go 1.12
+
+require (
+	github.com/buraksezer/consistent v0.0.0
+	github.com/hashicorp/serf v0.10.1
+)
+
+replace github.com/buraksezer/consistent => ../
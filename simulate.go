@@ -0,0 +1,91 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"sort"
+)
+
+// ImpactReport describes what happens if some members fail, see SimulateFailure.
+type ImpactReport struct {
+	// Failed are the names of the failed members in ascending order.
+	Failed []string
+
+	// Moves are the partitions whose owner changes, ordered by partition ID. To is nil if no member is left.
+	Moves []PartitionMove
+
+	// Loads maps the names of the remaining members to the number of partitions they would own. It's nil if the
+	// distribution fails.
+	Loads map[string]float64
+
+	// Err is the error the distribution among the remaining members fails with, e.g. the *DistributionError if
+	// they can't own all partitions under the load bound, or ErrInsufficientMemberCount if no member is left.
+	Err error
+}
+
+// SimulateFailure computes how the partitions would be distributed if the given members disappeared, without
+// changing the ring, e.g. for game-day planning. A failed distribution is reported by ImpactReport.Err. It
+// returns ErrMemberNotFound if one of the members isn't on the ring.
+func (c *Consistent) SimulateFailure(names ...string) (ImpactReport, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	failed := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		name = c.memberName(name)
+		if _, ok := c.members[name]; !ok {
+			return ImpactReport{}, ErrMemberNotFound
+		}
+		failed[name] = struct{}{}
+	}
+	var report ImpactReport
+	for name := range failed {
+		report.Failed = append(report.Failed, name)
+	}
+	sort.Strings(report.Failed)
+
+	next := c.clone()
+	for _, name := range report.Failed {
+		next.remove(name)
+	}
+	if len(next.members) == 0 {
+		next.partitions = make(map[int]*Member)
+		report.Err = ErrInsufficientMemberCount
+	} else if err := next.distribute(); err != nil {
+		report.Err = err
+		return report, nil
+	} else {
+		report.Loads = make(map[string]float64, len(next.loads))
+		for name, load := range next.loads {
+			report.Loads[name] = load
+		}
+	}
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		from, to := c.getPartitionOwner(partID), next.getPartitionOwner(partID)
+		if !sameMember(from, to) {
+			report.Moves = append(report.Moves, PartitionMove{PartitionID: partID, From: from, To: to})
+		}
+	}
+	return report, nil
+}
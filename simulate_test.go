@@ -0,0 +1,106 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSimulateFailure(t *testing.T) {
+	members := testMembers(6)
+	cfg := newConfigWith(271)
+	c := New(members, cfg)
+	version := c.Version()
+
+	report, err := c.SimulateFailure("node1.olric", "node0.olric", "node1.olric")
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if !reflect.DeepEqual(report.Failed, []string{"node0.olric", "node1.olric"}) {
+		t.Fatalf("Unexpected failed members: %v", report.Failed)
+	}
+	if report.Err != nil {
+		t.Fatalf("Expected nil, Got: %v", report.Err)
+	}
+	if c.Version() != version || !c.Equal(New(members, cfg)) {
+		t.Fatalf("Expected the ring not to change")
+	}
+	var total float64
+	for name, load := range report.Loads {
+		if name == "node0.olric" || name == "node1.olric" {
+			t.Fatalf("Expected no load on the failed member %s", name)
+		}
+		total += load
+	}
+	if total != 271 {
+		t.Fatalf("Expected 271 partitions, Got: %v", total)
+	}
+
+	before := c.owners()
+	c.Remove("node0.olric")
+	c.Remove("node1.olric")
+	after := c.owners()
+	var moved []int
+	for partID := range before {
+		if before[partID].String() != after[partID].String() {
+			moved = append(moved, partID)
+		}
+	}
+	if len(moved) != len(report.Moves) {
+		t.Fatalf("Expected %d moves, Got: %d", len(moved), len(report.Moves))
+	}
+	for i, move := range report.Moves {
+		if move.PartitionID != moved[i] || move.To.String() != after[moved[i]].String() {
+			t.Fatalf("Unexpected move: %+v", move)
+		}
+	}
+}
+
+func TestSimulateFailureInfeasible(t *testing.T) {
+	c := New(testMembers(6), newConfigWith(271))
+	for _, member := range testMembers(6) {
+		if err := c.SetMaxLoad(member.String(), 50); err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+	}
+	report, err := c.SimulateFailure("node0.olric")
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if report.Err == nil || report.Loads != nil {
+		t.Fatalf("Expected the distribution to fail: %+v", report)
+	}
+
+	report, err = c.SimulateFailure("node0.olric", "node1.olric", "node2.olric", "node3.olric", "node4.olric", "node5.olric")
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if report.Err != ErrInsufficientMemberCount || len(report.Moves) != 271 {
+		t.Fatalf("Expected every partition to lose its owner: %v, %d moves", report.Err, len(report.Moves))
+	}
+	if _, err := c.SimulateFailure("node9.olric"); err != ErrMemberNotFound {
+		t.Fatalf("Expected ErrMemberNotFound, Got: %v", err)
+	}
+}
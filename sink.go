@@ -0,0 +1,102 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AssignmentSink receives the partitions which moved in every distribution, see Config.AssignmentSink. It's
+// meant for keeping the assignment history for offline analysis, e.g. of churn patterns over weeks. CSVSink
+// writes it as CSV. Implementations are called with the lock of the ring held, so they must be fast: buffer and
+// write in the background if the destination is slow.
+type AssignmentSink interface {
+	// RecordAssignments is called at the given time for every new version of the partition table, including
+	// the first one, whose moves have no previous owner.
+	RecordAssignments(at time.Time, d LayoutDelta)
+}
+
+// CSVHeader is the header row written by CSVSink.
+var CSVHeader = []string{"version", "partition", "old_owner", "new_owner", "timestamp"}
+
+// CSVSink is an AssignmentSink which writes a row for every moved partition: the new version of the partition
+// table, the partition ID, the names of the old and the new owner, which are empty if there was none, and the
+// time in RFC 3339 format. Rows are flushed after every distribution.
+type CSVSink struct {
+	mu     sync.Mutex
+	w      *csv.Writer
+	header bool
+	err    error
+}
+
+// NewCSVSink returns a CSVSink writing to w. If header is set, CSVHeader is written before the first row; leave it
+// unset when appending to an existing file.
+func NewCSVSink(w io.Writer, header bool) *CSVSink {
+	return &CSVSink{w: csv.NewWriter(w), header: header}
+}
+
+// RecordAssignments implements AssignmentSink. After a write error it writes nothing anymore, see Err.
+func (s *CSVSink) RecordAssignments(at time.Time, d LayoutDelta) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil || len(d.Moves) == 0 {
+		return
+	}
+	if s.header {
+		s.header = false
+		if s.err = s.w.Write(CSVHeader); s.err != nil {
+			return
+		}
+	}
+	version := strconv.FormatUint(d.To, 10)
+	timestamp := at.UTC().Format(time.RFC3339Nano)
+	for _, move := range d.Moves {
+		var from, to string
+		if move.From != nil {
+			from = move.From.String()
+		}
+		if move.To != nil {
+			to = move.To.String()
+		}
+		row := []string{version, strconv.Itoa(move.PartitionID), from, to, timestamp}
+		if s.err = s.w.Write(row); s.err != nil {
+			return
+		}
+	}
+	s.w.Flush()
+	s.err = s.w.Error()
+}
+
+// Err returns the first error which occurred while writing, if any.
+func (s *CSVSink) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
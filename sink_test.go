@@ -0,0 +1,101 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCSVSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewCSVSink(&buf, true)
+	clock := newFakeClock()
+	cfg := newConfig()
+	cfg.AssignmentSink = sink
+	cfg.Clock = clock
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, cfg)
+	before := c.owners()
+	clock.Advance(time.Minute)
+	c.Add(testMember("node2.olric"))
+	if err := sink.Err(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if len(rows) == 0 || len(rows[0]) != len(CSVHeader) || rows[0][0] != "version" {
+		t.Fatalf("Expected the header, Got: %v", rows)
+	}
+	moves := c.Diff(New([]Member{testMember("node0.olric"), testMember("node1.olric")}, newConfig()))
+	// The first distribution assigns every partition.
+	if len(rows) != 1+23+len(moves) {
+		t.Fatalf("Expected %d rows, Got: %d", 1+23+len(moves), len(rows))
+	}
+	for _, row := range rows[1:24] {
+		if row[0] != "1" || row[2] != "" || row[4] != "2020-01-01T00:00:00Z" {
+			t.Fatalf("Unexpected row of the first distribution: %v", row)
+		}
+	}
+	for _, row := range rows[24:] {
+		partID, err := strconv.Atoi(row[1])
+		if err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+		if row[0] != "2" || row[2] != before[partID].String() || row[3] != c.GetPartitionOwner(partID).String() {
+			t.Fatalf("Unexpected row: %v", row)
+		}
+		if row[4] != "2020-01-01T00:01:00Z" {
+			t.Fatalf("Expected the time of the clock, Got: %s", row[4])
+		}
+	}
+
+	// The sink doesn't enable the history.
+	if _, err := c.DeltaSince(1); err != ErrVersionUnavailable {
+		t.Fatalf("Expected ErrVersionUnavailable, Got: %v", err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestCSVSinkError(t *testing.T) {
+	sink := NewCSVSink(failingWriter{}, false)
+	cfg := newConfig()
+	cfg.AssignmentSink = sink
+	c := New([]Member{testMember("node0.olric")}, cfg)
+	c.Add(testMember("node1.olric"))
+	if err := sink.Err(); err == nil || err.Error() != "disk full" {
+		t.Fatalf("Expected the write error, Got: %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+)
+
+// Snapshot is an exported copy of the complete state of a consistent hash ring. FromSnapshot restores it verbatim,
+// without redistributing the partitions.
+type Snapshot struct {
+	// Config is the configuration of the ring, with the defaults applied. Hasher must be set before restoring
+	// the snapshot, if it was lost while moving the snapshot around.
+	Config Config
+
+	// Version is the version of the partition table.
+	Version uint64
+
+	// Members are the members of the ring in the order of addition.
+	Members []Member
+
+	// VirtualNodes maps member names to the points of their virtual nodes. The index is the replica number.
+	VirtualNodes map[string][]uint64
+
+	// Salts maps the points of re-probed virtual nodes to their salts.
+	Salts map[uint64]int
+
+	// Partitions contains the name of the owner of every partition, indexed by partition ID. It is empty if
+	// the ring has no members.
+	Partitions []string
+
+	// Backups contains the names of the owner and backups of every partition, if Config.BackupCount is set.
+	Backups [][]string
+
+	// Collisions is the number of virtual node collisions since the ring was created.
+	Collisions uint64
+
+	// MaxLoads contains the limits set by SetMaxLoad.
+	MaxLoads map[string]int
+
+	// Standbys are the standby members in the order of registration.
+	Standbys []Member
+
+	// Draining contains the names of the draining members in ascending order.
+	Draining []string
+}
+
+// Snapshot returns a copy of the complete state of the consistent hash ring. A pending distribution is run
+// first, whether it's deferred, running in the background or left pending by a failure. If it fails again, the
+// snapshot carries the previous partition table, which FromSnapshot rejects if members left since.
+func (c *Consistent) Snapshot() Snapshot {
+	s, _ := c.flushedSnapshot()
+	return s
+}
+
+// flushedSnapshot runs a pending distribution and copies the state of the ring. It returns the error of the
+// distribution if it fails, see Flush.
+func (c *Consistent) flushedSnapshot() (Snapshot, error) {
+	// A snapshot of a stale partition table cannot be restored.
+	err := c.Flush()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.snapshot(), err
+}
+
+// snapshot copies the state of the ring. It's not thread-safe.
+func (c *Consistent) snapshot() Snapshot {
+	s := Snapshot{
+		Config:       c.config,
+		Version:      c.version,
+		Members:      append([]Member(nil), c.memberList...),
+		VirtualNodes: make(map[string][]uint64, len(c.vnodes)),
+		Salts:        make(map[uint64]int, len(c.salts)),
+		Collisions:   c.collisions,
+		MaxLoads:     make(map[string]int, len(c.maxLoads)),
+		Standbys:     append([]Member(nil), c.standbys...),
+		Draining:     c.drainingNames(),
+	}
+	for name, hashes := range c.vnodes {
+		s.VirtualNodes[name] = append([]uint64(nil), hashes...)
+	}
+	for h, salt := range c.salts {
+		s.Salts[h] = salt
+	}
+	for name, n := range c.maxLoads {
+		s.MaxLoads[name] = n
+	}
+	if len(c.members) != 0 {
+		s.Partitions = make([]string, c.partitionCount)
+		for partID := range s.Partitions {
+			if owner := c.getPartitionOwner(partID); owner != nil {
+				s.Partitions[partID] = owner.String()
+			}
+		}
+	}
+	for _, replicas := range c.replicas {
+		names := make([]string, 0, len(replicas))
+		for _, member := range replicas {
+			names = append(names, member.String())
+		}
+		s.Backups = append(s.Backups, names)
+	}
+	return s
+}
+
+// FromSnapshot reconstructs a consistent hash ring from the snapshot. It doesn't recompute the distribution,
+// the restored ring has exactly the ownership of the snapshot. The state is validated before it is returned,
+// the error wraps ErrInvalidSnapshot if the snapshot is inconsistent or was taken with a different Hasher.
+func FromSnapshot(s Snapshot) (*Consistent, error) {
+	if s.Config.Hasher == nil {
+		return nil, fmt.Errorf("%w: Hasher cannot be nil", ErrInvalidSnapshot)
+	}
+	c := newConsistent(s.Config, len(s.Members))
+	if err := c.restore(s); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	return c, nil
+}
+
+// restore loads the state of the snapshot into the empty ring. It's not thread-safe.
+func (c *Consistent) restore(s Snapshot) error {
+	for _, member := range s.Members {
+		name := member.String()
+		if _, ok := c.members[name]; ok {
+			return fmt.Errorf("duplicate member %s", name)
+		}
+		hashes := s.VirtualNodes[name]
+		if len(hashes) == 0 {
+			return fmt.Errorf("member %s has no virtual nodes", name)
+		}
+		if len(hashes) != c.config.ReplicationFactor {
+			// The member was added by AddWithReplicas.
+			c.setReplicaCount(name, len(hashes))
+		}
+		m := member
+		for replica, h := range hashes {
+			if c.virtualNodeHash(name, replica, s.Salts[h]) != h {
+				return fmt.Errorf("virtual node %d of %s doesn't match the hasher", replica, name)
+			}
+			if _, ok := c.ring[h]; ok {
+				return fmt.Errorf("virtual node %d of %s collides with another one", replica, name)
+			}
+			c.ring[h] = &m
+			c.sortedSet = append(c.sortedSet, h)
+			if salt := s.Salts[h]; salt != 0 {
+				c.salts[h] = salt
+			}
+		}
+		c.vnodes[name] = append([]uint64(nil), hashes...)
+		c.register(&m)
+		c.memberHashes = append(c.memberHashes, c.memberHash(name))
+	}
+	if len(s.VirtualNodes) != len(c.vnodes) {
+		return fmt.Errorf("snapshot has virtual nodes of unknown members")
+	}
+	if len(s.Salts) != len(c.salts) {
+		return fmt.Errorf("snapshot has salts of unknown virtual nodes")
+	}
+	sortHashes(c.sortedSet)
+	sortHashes(c.memberHashes)
+
+	if len(c.members) != 0 && len(s.Partitions) != int(c.partitionCount) {
+		return fmt.Errorf("partition table has %d partitions, expected %d", len(s.Partitions), c.partitionCount)
+	}
+	c.partitions = make(map[int]*Member, len(s.Partitions))
+	c.loads = make(map[string]float64)
+	for partID, name := range s.Partitions {
+		member, ok := c.members[name]
+		if !ok {
+			return fmt.Errorf("partition %d is owned by unknown member %s", partID, name)
+		}
+		c.partitions[partID] = member
+		c.loads[name]++
+	}
+	if len(s.Backups) != 0 {
+		if len(s.Backups) != int(c.partitionCount) {
+			return fmt.Errorf("backup table has %d partitions, expected %d", len(s.Backups), c.partitionCount)
+		}
+		c.replicas = make([][]Member, len(s.Backups))
+		for partID, names := range s.Backups {
+			if len(names) == 0 || names[0] != s.Partitions[partID] {
+				return fmt.Errorf("backup table of partition %d doesn't start with its owner", partID)
+			}
+			for _, name := range names {
+				member, ok := c.members[name]
+				if !ok {
+					return fmt.Errorf("partition %d is backed up by unknown member %s", partID, name)
+				}
+				c.replicas[partID] = append(c.replicas[partID], *member)
+			}
+		}
+	}
+	for _, name := range s.Draining {
+		if _, ok := c.members[name]; !ok {
+			return fmt.Errorf("draining member %s is not on the ring", name)
+		}
+		c.draining[name] = struct{}{}
+	}
+	if len(c.members) != 0 {
+		c.weightSum = c.totalWeight()
+	}
+	c.version = s.Version
+	c.collisions = s.Collisions
+	for name, n := range s.MaxLoads {
+		c.maxLoads[name] = n
+	}
+	for _, member := range s.Standbys {
+		if _, ok := c.members[member.String()]; ok {
+			return fmt.Errorf("standby member %s is on the ring", member)
+		}
+	}
+	c.standbys = append([]Member(nil), s.Standbys...)
+	return nil
+}
+
+// RestoreAndReconcile restores the snapshot like FromSnapshot and makes the live members the members of the ring
+// like SetMembers, for the common cold start: restore the last known state, then catch up with the membership
+// reported by discovery. The partitions are distributed once, for the whole difference, and not at all if the
+// members didn't change, regardless of Config.DistributionDebounce and Config.AsyncDistribution. Partitions move
+// only as far as a membership change moves them, and less with Config.Stickiness. It returns the error of
+// FromSnapshot or the error of the distribution instead of panicking.
+func RestoreAndReconcile(s Snapshot, live []Member) (*Consistent, error) {
+	c, err := FromSnapshot(s)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.setMembers(live) {
+		return c, nil
+	}
+	if len(c.members) == 0 {
+		c.membershipChanged()
+		return c, nil
+	}
+	if err := c.distributePartitions(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
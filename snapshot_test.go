@@ -0,0 +1,170 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestConsistentSnapshot(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	cfg.BackupCount = 2
+	cfg.Hasher = narrowHasher{}
+
+	t.Run("Restore verbatim", func(t *testing.T) {
+		c := New(members, cfg)
+		c.Remove("node3.olric")
+		s := c.Snapshot()
+		restored, err := FromSnapshot(s)
+		if err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+		if !restored.Equal(c) || restored.Fingerprint() != c.Fingerprint() {
+			t.Fatalf("Restored layout is different")
+		}
+		if restored.Version() != c.Version() || restored.Stats() != c.Stats() {
+			t.Fatalf("Expected stats %+v, Got: %+v", c.Stats(), restored.Stats())
+		}
+
+		// The restored ring behaves like the original one.
+		restored.Add(testMember("node8.olric"))
+		c.Add(testMember("node8.olric"))
+		if !restored.Equal(c) {
+			t.Fatalf("Restored ring diverged after adding a member")
+		}
+	})
+
+	t.Run("Ownership is not recomputed", func(t *testing.T) {
+		c := New(members, cfg)
+		s := c.Snapshot()
+		s.Partitions[0], s.Partitions[1] = s.Partitions[1], s.Partitions[0]
+		s.Backups = nil
+		restored, err := FromSnapshot(s)
+		if err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+		if restored.GetPartitionOwner(0).String() != s.Partitions[0] {
+			t.Fatalf("Expected owner %s, Got: %s", s.Partitions[0], restored.GetPartitionOwner(0))
+		}
+	})
+
+	t.Run("Empty ring", func(t *testing.T) {
+		restored, err := FromSnapshot(New(nil, cfg).Snapshot())
+		if err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+		if len(restored.GetMembers()) != 0 {
+			t.Fatalf("Expected no members")
+		}
+	})
+
+	t.Run("Invalid snapshots", func(t *testing.T) {
+		corrupt := []func(s *Snapshot){
+			func(s *Snapshot) { s.Config.Hasher = nil },
+			func(s *Snapshot) { s.Config.Hasher = hasher{} },
+			func(s *Snapshot) { s.Partitions[0] = "unknown" },
+			func(s *Snapshot) { s.Partitions = s.Partitions[:3] },
+			func(s *Snapshot) { s.VirtualNodes["node0.olric"] = s.VirtualNodes["node0.olric"][1:] },
+			func(s *Snapshot) { s.Backups[0] = s.Backups[1] },
+		}
+		for i, fn := range corrupt {
+			s := New(members, cfg).Snapshot()
+			fn(&s)
+			if _, err := FromSnapshot(s); !errors.Is(err, ErrInvalidSnapshot) {
+				t.Fatalf("Case %d: expected ErrInvalidSnapshot, Got: %v", i, err)
+			}
+		}
+	})
+}
+
+func TestRestoreAndReconcile(t *testing.T) {
+	c := New(testMembers(8), newConfig())
+	s := c.Snapshot()
+
+	live := append(testMembers(7), testMember("node8.olric"))
+	r, err := RestoreAndReconcile(s, live)
+	if err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if r.Version() != s.Version+1 {
+		t.Fatalf("Expected a single distribution, got version %d after %d", r.Version(), s.Version)
+	}
+	if want := New(live, newConfig()); !r.Equal(want) {
+		t.Fatalf("Expected the layout of the live members, diff: %v", r.Diff(want))
+	}
+
+	r, err = RestoreAndReconcile(s, testMembers(8))
+	if err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if r.Version() != s.Version || !r.Equal(c) {
+		t.Fatalf("Expected the restored layout")
+	}
+
+	r, err = RestoreAndReconcile(s, nil)
+	if err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if len(r.GetMembers()) != 0 || r.GetPartitionOwner(0) != nil {
+		t.Fatalf("Expected an empty ring")
+	}
+
+	s.Config.Hasher = nil
+	if _, err := RestoreAndReconcile(s, live); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Fatalf("Expected ErrInvalidSnapshot, got: %v", err)
+	}
+}
+
+func TestSnapshotPendingFailure(t *testing.T) {
+	failed := false
+	cfg := newConfigWith(271)
+	cfg.PanicFree = true
+	cfg.TestingKnobs = &TestingKnobs{FailDistribution: func(uint64) error {
+		if failed {
+			return nil
+		}
+		failed = true
+		return errors.New("injected failure")
+	}}
+	c := New(testMembers(4), cfg)
+	failed = false
+	c.Remove("node0.olric")
+	if !c.Pending() {
+		t.Fatalf("Expected a pending distribution")
+	}
+
+	restored, err := FromSnapshot(c.Snapshot())
+	if err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if c.Pending() || !restored.Equal(c) {
+		t.Fatalf("Expected the snapshot to carry the distribution after the removal")
+	}
+}
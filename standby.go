@@ -0,0 +1,102 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// AddStandby registers a spare member. Standby members are not on the ring and own nothing until they are
+// promoted, either by PromoteStandby or automatically when Remove takes an active member off the ring. Then the
+// first registered standby joins in the same distribution, so the average load doesn't grow during a failure.
+// Standby members are not promoted by SetMembers and CompleteDecommission. It's a no-op if a member with the
+// same name is already active or standby.
+func (c *Consistent) AddStandby(member Member) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := member.String()
+	if !c.validName(name) {
+		return
+	}
+	if _, ok := c.members[c.memberName(name)]; ok || c.standbyIndex(name) >= 0 {
+		return
+	}
+	c.standbys = append(c.standbys, member)
+}
+
+// RemoveStandby unregisters a standby member. It's a no-op if there is no standby member with that name.
+func (c *Consistent) RemoveStandby(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if i := c.standbyIndex(name); i >= 0 {
+		c.standbys = append(c.standbys[:i:i], c.standbys[i+1:]...)
+	}
+}
+
+// Standbys returns a thread-safe copy of the standby members in the order of registration.
+func (c *Consistent) Standbys() []Member {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]Member(nil), c.standbys...)
+}
+
+// PromoteStandby adds the standby member to the ring, like Add. It returns ErrMemberNotFound if there is no
+// standby member with that name.
+func (c *Consistent) PromoteStandby(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := c.standbyIndex(name)
+	if i < 0 {
+		return ErrMemberNotFound
+	}
+	member := c.standbys[i]
+	c.standbys = append(c.standbys[:i:i], c.standbys[i+1:]...)
+	c.add(member)
+	c.startWarmup(member.String())
+	c.redistribute()
+	return nil
+}
+
+// standbyIndex returns the index of the standby member, or -1. It's not thread-safe.
+func (c *Consistent) standbyIndex(name string) int {
+	name = c.normalize(name)
+	for i, member := range c.standbys {
+		if c.normalize(member.String()) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// promoteStandby adds the first standby member to the ring without distributing the partitions. It's not
+// thread-safe.
+func (c *Consistent) promoteStandby() {
+	if len(c.standbys) == 0 {
+		return
+	}
+	member := c.standbys[0]
+	c.standbys = c.standbys[1:]
+	c.add(member)
+	c.startWarmup(member.String())
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStandby(t *testing.T) {
+	var members []Member
+	for i := 0; i < 4; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c := New(members, newConfig())
+	c.AddStandby(testMember("spare0.olric"))
+	c.AddStandby(testMember("spare1.olric"))
+	c.AddStandby(testMember("spare0.olric"))
+	c.AddStandby(testMember("node0.olric"))
+	if standbys := c.Standbys(); len(standbys) != 2 || standbys[0].String() != "spare0.olric" {
+		t.Fatalf("Unexpected standby members: %v", standbys)
+	}
+	if _, ok := c.LoadDistribution()["spare0.olric"]; ok {
+		t.Fatalf("Standby member must not own partitions")
+	}
+
+	// The first standby member takes the place of the removed one in a single distribution.
+	version := c.Version()
+	c.Remove("node1.olric")
+	if c.Version() != version+1 {
+		t.Fatalf("Expected a single distribution, Got: %d", c.Version()-version)
+	}
+	expected := New([]Member{members[0], members[2], members[3], testMember("spare0.olric")}, newConfig())
+	if !c.Equal(expected) {
+		t.Fatalf("Layout is different from a ring with the promoted member")
+	}
+	if standbys := c.Standbys(); len(standbys) != 1 || standbys[0].String() != "spare1.olric" {
+		t.Fatalf("Unexpected standby members: %v", standbys)
+	}
+
+	if err := c.PromoteStandby("spare1.olric"); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if len(c.GetMembers()) != 5 || len(c.Standbys()) != 0 {
+		t.Fatalf("Expected 5 active and no standby members")
+	}
+	if err := c.PromoteStandby("spare1.olric"); err != ErrMemberNotFound {
+		t.Fatalf("Expected ErrMemberNotFound, Got: %v", err)
+	}
+	c.Remove("node2.olric")
+	if len(c.GetMembers()) != 4 {
+		t.Fatalf("Expected 4 members without standby members left")
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+}
+
+func TestStandbyAddRemove(t *testing.T) {
+	c := New([]Member{testMember("node0.olric")}, newConfig())
+	c.AddStandby(testMember("spare0.olric"))
+	c.AddStandby(testMember("spare1.olric"))
+	c.RemoveStandby("spare0.olric")
+	c.RemoveStandby("spare2.olric")
+	if standbys := c.Standbys(); len(standbys) != 1 || standbys[0].String() != "spare1.olric" {
+		t.Fatalf("Unexpected standby members: %v", standbys)
+	}
+
+	// Adding a standby member makes it active.
+	c.Add(testMember("spare1.olric"))
+	if len(c.Standbys()) != 0 || len(c.GetMembers()) != 2 {
+		t.Fatalf("Expected spare1.olric to be active")
+	}
+
+	c.AddStandby(testMember("spare2.olric"))
+	restored, err := FromSnapshot(c.Snapshot())
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if standbys := restored.Standbys(); len(standbys) != 1 || standbys[0].String() != "spare2.olric" {
+		t.Fatalf("Unexpected standby members: %v", standbys)
+	}
+}
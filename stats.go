@@ -0,0 +1,101 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"sort"
+)
+
+// Stats represents statistics about the consistent hash ring.
+type Stats struct {
+	// Members is the number of members on the ring.
+	Members int
+
+	// VirtualNodes is the number of virtual nodes on the ring.
+	VirtualNodes int
+
+	// PartitionCount is the number of partitions.
+	PartitionCount int
+
+	// Version is the version of the partition table.
+	Version uint64
+
+	// Collisions is the number of virtual node hashes which collided with an existing one and
+	// had to be re-probed since the ring was created.
+	Collisions uint64
+}
+
+// Stats returns statistics about the consistent hash ring.
+func (c *Consistent) Stats() Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return Stats{
+		Members:        len(c.members),
+		VirtualNodes:   len(c.sortedSet),
+		PartitionCount: int(c.partitionCount),
+		Version:        c.version,
+		Collisions:     c.collisions,
+	}
+}
+
+// EstimateKeyCounts converts the partition ownership into the expected number of keys per member, assuming that
+// totalKeys keys are spread uniformly over the partitions. The counts sum up to totalKeys. Members which own no
+// partitions are not included.
+func (c *Consistent) EstimateKeyCounts(totalKeys int) map[string]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	res := make(map[string]int, len(c.loads))
+	if c.partitionCount == 0 || len(c.loads) == 0 {
+		return res
+	}
+
+	// Largest remainder method: hand out the rounded down shares first, then the keys left
+	// over to the members with the largest fractional parts.
+	type share struct {
+		name      string
+		remainder float64
+	}
+	shares := make([]share, 0, len(c.loads))
+	assigned := 0
+	for name, load := range c.loads {
+		exact := load * float64(totalKeys) / float64(c.partitionCount)
+		count := int(exact)
+		res[name] = count
+		assigned += count
+		shares = append(shares, share{name: name, remainder: exact - float64(count)})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].remainder != shares[j].remainder {
+			return shares[i].remainder > shares[j].remainder
+		}
+		return shares[i].name < shares[j].name
+	})
+	for i := 0; assigned < totalKeys; i++ {
+		res[shares[i%len(shares)].name]++
+		assigned++
+	}
+	return res
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"hash/fnv"
+	"testing"
+)
+
+// narrowHasher maps everything to a small hash space to force virtual node collisions.
+type narrowHasher struct{}
+
+func (narrowHasher) Sum64(data []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(data)
+	return h.Sum64() % 512
+}
+
+func TestConsistentStats(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	c := New(members, cfg)
+	stats := c.Stats()
+	if stats.Members != 8 || stats.VirtualNodes != 8*cfg.ReplicationFactor || stats.PartitionCount != cfg.PartitionCount {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+	if stats.Version != c.Version() {
+		t.Fatalf("Expected version %d, Got: %d", c.Version(), stats.Version)
+	}
+}
+
+func TestConsistentVirtualNodeCollisions(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	cfg.Hasher = narrowHasher{}
+	c := New(members, cfg)
+
+	stats := c.Stats()
+	if stats.Collisions == 0 {
+		t.Fatalf("Expected collisions in a narrow hash space")
+	}
+	if stats.VirtualNodes != 8*cfg.ReplicationFactor {
+		t.Fatalf("Expected %d virtual nodes, Got: %d", 8*cfg.ReplicationFactor, stats.VirtualNodes)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	// Removing members must remove the re-probed virtual nodes as well.
+	for _, member := range members[:4] {
+		c.Remove(member.String())
+	}
+	if c.Stats().VirtualNodes != 4*cfg.ReplicationFactor {
+		t.Fatalf("Expected %d virtual nodes, Got: %d", 4*cfg.ReplicationFactor, c.Stats().VirtualNodes)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+}
+
+func TestConsistentEstimateKeyCounts(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	c := New(members, cfg)
+
+	const totalKeys = 1000003
+	estimates := c.EstimateKeyCounts(totalKeys)
+	var sum int
+	for member, count := range estimates {
+		load := c.LoadDistribution()[member]
+		expected := load * totalKeys / float64(cfg.PartitionCount)
+		if float64(count) < expected-1 || float64(count) > expected+1 {
+			t.Fatalf("%s: expected about %f keys, Got: %d", member, expected, count)
+		}
+		sum += count
+	}
+	if sum != totalKeys {
+		t.Fatalf("Expected %d keys in total, Got: %d", totalKeys, sum)
+	}
+
+	if len(New(nil, cfg).EstimateKeyCounts(totalKeys)) != 0 {
+		t.Fatalf("Expected no estimates for an empty ring")
+	}
+}
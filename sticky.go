@@ -0,0 +1,54 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"math"
+)
+
+// distributeSticky keeps every partition with its current owner while the owner holds fewer than
+// Config.Stickiness times its load bound, see Config.Stickiness. It's not thread-safe.
+func (c *Consistent) distributeSticky(partitions map[int]*Member, loads map[string]float64) {
+	if c.config.Stickiness <= 0 || len(c.partitions) == 0 {
+		return
+	}
+	stickiness := math.Min(c.config.Stickiness, 1)
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		if _, ok := partitions[partID]; ok {
+			continue
+		}
+		owner, ok := c.partitions[partID]
+		if !ok {
+			continue
+		}
+		m, ok := c.members[(*owner).String()]
+		if !ok {
+			continue
+		}
+		if loads[(*m).String()]+1 <= math.Floor(c.loadBound(*m)*stickiness) {
+			partitions[partID] = m
+			loads[(*m).String()]++
+		}
+	}
+}
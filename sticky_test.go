@@ -0,0 +1,83 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func stickyMoves(t *testing.T, stickiness float64) (moved int, loads map[string]float64) {
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.Stickiness = stickiness
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c := New(members, cfg)
+	for i := 8; i < 10; i++ {
+		before := c.owners()
+		c.Add(testMember(fmt.Sprintf("node%d.olric", i)))
+		for partID, owner := range c.owners() {
+			if owner.String() != before[partID].String() {
+				moved++
+			}
+		}
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	return moved, c.LoadDistribution()
+}
+
+func TestStickiness(t *testing.T) {
+	baseline, _ := stickyMoves(t, 0)
+	full, _ := stickyMoves(t, 1)
+	partial, loads := stickyMoves(t, 0.8)
+	if full >= baseline || partial >= baseline {
+		t.Fatalf("Expected fewer moves with stickiness, baseline: %d, full: %d, partial: %d", baseline, full, partial)
+	}
+	if loads["node8.olric"] == 0 || loads["node9.olric"] == 0 {
+		t.Fatalf("Expected the new members to own partitions: %v", loads)
+	}
+}
+
+func TestStickinessRemove(t *testing.T) {
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.Stickiness = 1
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c := New(members, cfg)
+	before := c.owners()
+	c.Remove("node3.olric")
+	for partID, owner := range c.owners() {
+		if before[partID].String() != "node3.olric" && owner.String() != before[partID].String() {
+			t.Fatalf("Partition %d moved from %s to %s", partID, before[partID], owner)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"math"
+	"sort"
+)
+
+// AssignmentStrategy selects the order in which a distribution assigns the partitions to members, see
+// Config.Strategy.
+type AssignmentStrategy int
+
+const (
+	// PartitionOrder assigns the partitions in the order of their IDs. It's the default.
+	PartitionOrder AssignmentStrategy = iota
+
+	// HashOrder assigns the partitions in the order of their points on the ring, so the members fill up clockwise
+	// instead of in an order unrelated to the ring.
+	HashOrder
+
+	// TwoPass first distributes the partitions with the fair share of every member as the bound, the partition
+	// count split by weight without the slack of Config.Load. The second pass assigns the partitions which found
+	// no room as usual. Members which own large arcs of the ring can't take the slack of the load bound before the
+	// others got their share, so the loads are nearly even, but more partitions move on a membership change.
+	TwoPass
+)
+
+// assignmentOrder returns the partition IDs in the order the distribution assigns them. It's not thread-safe.
+func (c *Consistent) assignmentOrder() []int {
+	order := make([]int, c.partitionCount)
+	for partID := range order {
+		order[partID] = partID
+	}
+	if c.config.Strategy != HashOrder {
+		return order
+	}
+
+	points := make([]uint64, c.partitionCount)
+	bs := make([]byte, 8)
+	for partID := range points {
+		points[partID] = c.partitionPoint(partID, bs)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if points[order[i]] != points[order[j]] {
+			return points[order[i]] < points[order[j]]
+		}
+		return order[i] < order[j]
+	})
+	return order
+}
+
+// distributeFairShare is the first pass of TwoPass. It walks the ring like the usual distribution, but with the
+// fair share of every member as the bound. Partitions which find no room are left to the second pass.
+// It's not thread-safe.
+func (c *Consistent) distributeFairShare(partitions map[int]*Member, loads map[string]float64) {
+	bs := make([]byte, 8)
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		if _, ok := partitions[partID]; ok {
+			continue
+		}
+		idx := c.partitionIndex(partID, bs)
+		for i := 0; i < len(c.sortedSet); i++ {
+			member := c.ring[c.sortedSet[(idx+i)%len(c.sortedSet)]]
+			if loads[(*member).String()]+1 <= c.fairShare(*member) {
+				partitions[partID] = member
+				loads[(*member).String()]++
+				break
+			}
+		}
+	}
+}
+
+// fairShare returns the number of partitions the member owns if they are split by weight without the slack of
+// Config.Load, limited by its load bound. It's not thread-safe.
+func (c *Consistent) fairShare(member Member) float64 {
+	share := c.capacity(member, float64(c.partitionCount), 1, c.weightSum)
+	return math.Min(share, c.loadBound(member))
+}
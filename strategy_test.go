@@ -0,0 +1,75 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+// loadSpread returns the difference between the largest and the smallest load of the members.
+func loadSpread(c *Consistent, members []Member) float64 {
+	loads := c.LoadDistribution()
+	max, min := 0.0, float64(c.partitionCount)
+	for _, member := range members {
+		load := loads[member.String()]
+		if load > max {
+			max = load
+		}
+		if load < min {
+			min = load
+		}
+	}
+	return max - min
+}
+
+func TestAssignmentStrategy(t *testing.T) {
+	for _, count := range []int{3, 7, 10, 25} {
+		var members []Member
+		for i := 0; i < count; i++ {
+			members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+		}
+		spreads := make(map[AssignmentStrategy]float64)
+		for _, strategy := range []AssignmentStrategy{PartitionOrder, HashOrder, TwoPass} {
+			cfg := newConfig()
+			cfg.PartitionCount = 271
+			cfg.Strategy = strategy
+			c := New(members, cfg)
+			if err := c.Validate(); err != nil {
+				t.Fatalf("Strategy %d with %d members: %v", strategy, count, err)
+			}
+			d := New(nil, cfg)
+			for i := len(members) - 1; i >= 0; i-- {
+				d.Add(members[i])
+			}
+			if !c.Equal(d) {
+				t.Fatalf("Strategy %d with %d members depends on the insertion order", strategy, count)
+			}
+			spreads[strategy] = loadSpread(c, members)
+		}
+		if spreads[TwoPass] >= spreads[PartitionOrder] {
+			t.Fatalf("Expected TwoPass to even the loads of %d members, spreads: %v", count, spreads)
+		}
+	}
+}
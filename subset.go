@@ -0,0 +1,126 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// RingView is a read-only consistent hash ring, see Subset.
+type RingView struct {
+	c *Consistent
+}
+
+// Subset returns a read-only ring over the members for which filter returns true, e.g. the members of a single
+// region. The view has the same configuration as c, its layout depends only on the selected members, so every
+// node which computes the same subset gets the same layout. It doesn't follow later changes of c; call Subset
+// again after a membership change. Limits set by SetMaxLoad, virtual node counts set by AddWithReplicas and
+// draining carry over to the view. A nil filter selects every member.
+//
+// It returns ErrInsufficientMemberCount if no member matches, and the *DistributionError if the selected members
+// cannot own all the partitions.
+func (c *Consistent) Subset(filter func(Member) bool) (RingView, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var members []Member
+	for _, member := range c.memberList {
+		if filter == nil || filter(member) {
+			members = append(members, member)
+		}
+	}
+	if len(members) == 0 {
+		return RingView{}, ErrInsufficientMemberCount
+	}
+
+	config := c.config
+	// The view is distributed once, right here.
+	config.DistributionDebounce = 0
+	config.AsyncDistribution = false
+	config.DistributionTimeout = 0
+	config.DeltaHistory = 0
+	config.HistorySize = 0
+	config.AutoSavePath = ""
+	config.AssignmentSink = nil
+	config.MeterProvider = nil
+	config.Logger = nil
+	v := newConsistent(config, len(members))
+	for _, member := range members {
+		if n, ok := c.replicaCounts[member.String()]; ok {
+			v.setReplicaCount(member.String(), n)
+		}
+		v.sortedSet = append(v.sortedSet, v.place(member)...)
+		v.memberHashes = append(v.memberHashes, v.memberHash(member.String()))
+		name := member.String()
+		if n, ok := c.maxLoads[name]; ok {
+			v.maxLoads[name] = n
+		}
+		if _, ok := c.draining[name]; ok {
+			v.draining[name] = struct{}{}
+		}
+	}
+	sortHashes(v.sortedSet)
+	sortHashes(v.memberHashes)
+	if err := v.distribute(); err != nil {
+		return RingView{}, err
+	}
+	return RingView{c: v}, nil
+}
+
+// GetMembers returns the members of the view.
+func (v RingView) GetMembers() []Member {
+	return v.c.GetMembers()
+}
+
+// FindPartitionID returns the partition ID for the given key.
+func (v RingView) FindPartitionID(key []byte) int {
+	return v.c.FindPartitionID(key)
+}
+
+// GetPartitionOwner returns the owner of the given partition in the view.
+func (v RingView) GetPartitionOwner(partID int) Member {
+	return v.c.GetPartitionOwner(partID)
+}
+
+// LocateKey finds the member in the view which owns the key.
+func (v RingView) LocateKey(key []byte) Member {
+	return v.c.LocateKey(key)
+}
+
+// GetClosestN returns the closest N members of the view to the key, see Consistent.GetClosestN.
+func (v RingView) GetClosestN(key []byte, count int) ([]Member, error) {
+	return v.c.GetClosestN(key, count)
+}
+
+// GetPartitionOwnerAndBackups returns the owner of the partition in the view followed by its backups, see
+// Consistent.GetPartitionOwnerAndBackups.
+func (v RingView) GetPartitionOwnerAndBackups(partID int) []Member {
+	return v.c.GetPartitionOwnerAndBackups(partID)
+}
+
+// LoadDistribution exposes the load distribution of the members of the view.
+func (v RingView) LoadDistribution() map[string]float64 {
+	return v.c.LoadDistribution()
+}
+
+// Snapshot returns a copy of the complete state of the view. FromSnapshot turns it into a regular ring.
+func (v RingView) Snapshot() Snapshot {
+	return v.c.Snapshot()
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"testing"
+)
+
+func TestSubset(t *testing.T) {
+	var members []Member
+	for i := 0; i < 6; i++ {
+		region := "eu-west"
+		if i%2 == 1 {
+			region = "us-east"
+		}
+		members = append(members, testMember(fmt.Sprintf("node%d.%s", i, region)))
+	}
+	cfg := newConfig()
+	cfg.BackupCount = 1
+	c := New(members, cfg)
+	euWest := func(m Member) bool {
+		return strings.HasSuffix(m.String(), ".eu-west")
+	}
+	v, err := c.Subset(euWest)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if len(v.GetMembers()) != 3 {
+		t.Fatalf("Expected 3 members, Got: %d", len(v.GetMembers()))
+	}
+	for i := 0; i < 100; i++ {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		if owner := v.LocateKey(key); !euWest(owner) {
+			t.Fatalf("%s is not in the subset", owner)
+		}
+	}
+	for _, member := range v.GetPartitionOwnerAndBackups(0) {
+		if !euWest(member) {
+			t.Fatalf("%s is not in the subset", member)
+		}
+	}
+
+	// The layout is the one of a ring of the selected members only.
+	expected := New([]Member{members[0], members[2], members[4]}, cfg)
+	view, err := FromSnapshot(v.Snapshot())
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if !view.Equal(expected) {
+		t.Fatalf("Expected the layout of the selected members")
+	}
+
+	// The view doesn't follow c.
+	c.Remove("node0.eu-west")
+	if len(v.GetMembers()) != 3 {
+		t.Fatalf("Expected the view to keep its members")
+	}
+
+	if _, err := c.Subset(func(Member) bool { return false }); err != ErrInsufficientMemberCount {
+		t.Fatalf("Expected ErrInsufficientMemberCount, Got: %v", err)
+	}
+}
+
+// mixHasher spreads similar inputs over the ring, fnv alone puts the virtual nodes of a member close together.
+type mixHasher struct{}
+
+func (mixHasher) Sum64(data []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(data)
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+func TestSubsetReplicas(t *testing.T) {
+	cfg := newConfigWith(271)
+	cfg.Hasher = mixHasher{}
+	c := New(testMembers(3), cfg)
+	if err := c.AddWithReplicas(testMember("node3.olric"), 60); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	v, err := c.Subset(func(m Member) bool {
+		return m.String() != "node0.olric"
+	})
+	if err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+
+	direct := New([]Member{testMember("node1.olric"), testMember("node2.olric")}, cfg)
+	if err := direct.AddWithReplicas(testMember("node3.olric"), 60); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	for partID := 0; partID < cfg.PartitionCount; partID++ {
+		if v.GetPartitionOwner(partID).String() != direct.GetPartitionOwner(partID).String() {
+			t.Fatalf("Partition %d: expected %s, got: %s", partID, direct.GetPartitionOwner(partID),
+				v.GetPartitionOwner(partID))
+		}
+	}
+}
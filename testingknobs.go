@@ -0,0 +1,114 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"sort"
+	"time"
+)
+
+// TestingKnobs inject deterministic faults into a ring, see Config.TestingKnobs. They are meant for tests of the
+// code which reacts to changes of the partition table, e.g. migration logic. Don't set them in production.
+type TestingKnobs struct {
+	// FailDistribution is called before every distribution with the version it would publish. A non-nil error
+	// fails the distribution and is returned or handled like any other distribution error: Add and Remove panic
+	// unless Config.PanicFree is set, or the error is ErrDistributionTimeout, and keep the previous table then.
+	FailDistribution func(version uint64) error
+
+	// MovePartition is called for every partition after the assignment, in the order of the partition IDs. If it
+	// returns a member of the ring other than owner, the partition moves to it regardless of the load limits.
+	// Other return values are ignored.
+	MovePartition func(partID int, owner Member) Member
+
+	// PublishDelay delays the publication of every new version of the partition table to WaitForVersion by the
+	// given duration on Config.Clock. Lookups see the new table immediately.
+	PublishDelay time.Duration
+}
+
+// injectFailure returns the error of TestingKnobs.FailDistribution. It's not thread-safe.
+func (c *Consistent) injectFailure() error {
+	if c.config.TestingKnobs == nil || c.config.TestingKnobs.FailDistribution == nil {
+		return nil
+	}
+	return c.config.TestingKnobs.FailDistribution(c.version + 1)
+}
+
+// injectMoves applies TestingKnobs.MovePartition to the assignment. It's not thread-safe.
+func (c *Consistent) injectMoves(partitions map[int]*Member, loads map[string]float64) {
+	if c.config.TestingKnobs == nil || c.config.TestingKnobs.MovePartition == nil {
+		return
+	}
+	partIDs := make([]int, 0, len(partitions))
+	for partID := range partitions {
+		partIDs = append(partIDs, partID)
+	}
+	sort.Ints(partIDs)
+	for _, partID := range partIDs {
+		owner := *partitions[partID]
+		target := c.config.TestingKnobs.MovePartition(partID, owner)
+		if target == nil || target.String() == owner.String() {
+			continue
+		}
+		member, ok := c.members[target.String()]
+		if !ok {
+			continue
+		}
+		loads[owner.String()]--
+		if loads[owner.String()] <= 0 {
+			delete(loads, owner.String())
+		}
+		loads[target.String()]++
+		partitions[partID] = member
+	}
+}
+
+// publishDelay returns TestingKnobs.PublishDelay.
+func (c *Consistent) publishDelay() time.Duration {
+	if c.config.TestingKnobs == nil {
+		return 0
+	}
+	return c.config.TestingKnobs.PublishDelay
+}
+
+// publishedVersion returns the version WaitForVersion sees. It's not thread-safe.
+func (c *Consistent) publishedVersion() uint64 {
+	if c.publishDelay() <= 0 {
+		return c.version
+	}
+	return c.published
+}
+
+// publishLater publishes the version to WaitForVersion after TestingKnobs.PublishDelay. It's not thread-safe.
+func (c *Consistent) publishLater(version uint64) {
+	c.clock().AfterFunc(c.publishDelay(), func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if version > c.published {
+			c.published = version
+		}
+		close(c.versionCh)
+		c.versionCh = make(chan struct{})
+	})
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTestingKnobsFailDistribution(t *testing.T) {
+	errInjected := errors.New("injected")
+	var fail bool
+	cfg := newConfig()
+	cfg.PanicFree = true
+	cfg.TestingKnobs = &TestingKnobs{
+		FailDistribution: func(version uint64) error {
+			if fail {
+				return errInjected
+			}
+			return nil
+		},
+	}
+	c := New(testMembers(4), cfg)
+	version := c.Version()
+	owners := c.owners()
+
+	fail = true
+	c.Add(testMember("node4.olric"))
+	if c.Version() != version {
+		t.Fatalf("Expected version %d, got: %d", version, c.Version())
+	}
+	for partID, owner := range c.owners() {
+		if owner.String() != owners[partID].String() {
+			t.Fatalf("Expected the previous partition table")
+		}
+	}
+	if err := c.Flush(); err != errInjected {
+		t.Fatalf("Expected the injected error, got: %v", err)
+	}
+
+	fail = false
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+	if c.Version() != version+1 {
+		t.Fatalf("Expected version %d, got: %d", version+1, c.Version())
+	}
+}
+
+func TestTestingKnobsMovePartition(t *testing.T) {
+	cfg := newConfig()
+	cfg.TestingKnobs = &TestingKnobs{
+		MovePartition: func(partID int, owner Member) Member {
+			if partID == 3 || partID == 7 {
+				return testMember("node0.olric")
+			}
+			if partID == 5 {
+				return testMember("unknown")
+			}
+			return nil
+		},
+	}
+	c := New(testMembers(4), cfg)
+	owners := c.owners()
+	for _, partID := range []int{3, 7} {
+		if owners[partID].String() != "node0.olric" {
+			t.Fatalf("Expected partition %d on node0.olric, got: %s", partID, owners[partID])
+		}
+	}
+	var total float64
+	for name, load := range c.LoadDistribution() {
+		var want float64
+		for _, owner := range owners {
+			if owner.String() == name {
+				want++
+			}
+		}
+		if load != want {
+			t.Fatalf("Expected load %v for %s, got: %v", want, name, load)
+		}
+		total += load
+	}
+	if total != float64(cfg.PartitionCount) {
+		t.Fatalf("Expected total load %d, got: %v", cfg.PartitionCount, total)
+	}
+}
+
+func TestTestingKnobsPublishDelay(t *testing.T) {
+	clock := newFakeClock()
+	cfg := newConfig()
+	cfg.Clock = clock
+	cfg.TestingKnobs = &TestingKnobs{PublishDelay: time.Second}
+	c := New(testMembers(4), cfg)
+	c.Add(testMember("node4.olric"))
+	version := c.Version()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.WaitForVersion(ctx, version); err != context.DeadlineExceeded {
+		t.Fatalf("Expected context.DeadlineExceeded, got: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.WaitForVersion(context.Background(), version)
+	}()
+	clock.Advance(time.Second)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Expected nil, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected version %d to be published", version)
+	}
+}
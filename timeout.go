@@ -0,0 +1,50 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"time"
+)
+
+// deadlineCheckInterval is the number of partitions placed between two checks of the distribution deadline.
+const deadlineCheckInterval = 64
+
+// distributionDeadline returns the time by which the running distribution must finish, or the zero time if there
+// is no limit. The first distribution has no previous table to fall back to, so it's never limited.
+// It's not thread-safe.
+func (c *Consistent) distributionDeadline() time.Time {
+	if c.config.DistributionTimeout <= 0 || c.partitions == nil {
+		return time.Time{}
+	}
+	return c.clock().Now().Add(c.config.DistributionTimeout)
+}
+
+// expired reports whether the deadline has passed. It reads the clock only every deadlineCheckInterval
+// iterations. It's not thread-safe.
+func (c *Consistent) expired(deadline time.Time, i int) bool {
+	if deadline.IsZero() || i%deadlineCheckInterval != deadlineCheckInterval-1 {
+		return false
+	}
+	return c.clock().Now().After(deadline)
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDistributionTimeout(t *testing.T) {
+	meter := &testMeter{}
+	cfg := newConfig()
+	cfg.PartitionCount = 2711
+	cfg.BackupCount = 1
+	cfg.DistributionTimeout = time.Nanosecond
+	cfg.MeterProvider = meter
+	var members []Member
+	for i := 0; i < 4; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	// New isn't limited.
+	c := New(members, cfg)
+	version := c.Version()
+	owner := c.GetPartitionOwner(0)
+
+	c.Add(testMember("node4.olric"))
+	if !c.Pending() {
+		t.Fatalf("Expected a pending distribution")
+	}
+	if c.Version() != version || c.GetPartitionOwner(0).String() != owner.String() {
+		t.Fatalf("Expected the previous partition table")
+	}
+	if _, ok := c.LoadDistribution()["node4.olric"]; ok {
+		t.Fatalf("Expected node4.olric without partitions")
+	}
+	last := meter.distributions[len(meter.distributions)-1]
+	if last.Err != ErrDistributionTimeout {
+		t.Fatalf("Expected ErrDistributionTimeout, Got: %v", last.Err)
+	}
+	if err := c.Flush(); err != ErrDistributionTimeout {
+		t.Fatalf("Expected ErrDistributionTimeout, Got: %v", err)
+	}
+
+	c.config.DistributionTimeout = time.Minute
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if c.Pending() || c.Version() != version+1 {
+		t.Fatalf("Expected the new partition table")
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+}
+
+func TestDistributionTimeoutPreassigned(t *testing.T) {
+	cfg := newConfig()
+	cfg.PartitionCount = 100
+	c := New(testMembers(4), cfg)
+
+	// The partitions at the check interval are preassigned, e.g. by affinities.
+	partitions := make(map[int]*Member)
+	loads := make(map[string]float64)
+	order := c.assignmentOrder()
+	for _, partID := range order[:deadlineCheckInterval] {
+		partitions[partID] = c.partitions[partID]
+		loads[(*c.partitions[partID]).String()]++
+	}
+	deadline := c.clock().Now().Add(-time.Second)
+	if err := c.assign(partitions, loads, deadline, c.clock().Now()); err != ErrDistributionTimeout {
+		t.Fatalf("Expected ErrDistributionTimeout, Got: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"sort"
+)
+
+// ZoneReport summarizes a zone of the ring, see TopologyReport.
+type ZoneReport struct {
+	// Members is the number of members in the zone.
+	Members int
+
+	// Partitions is the number of partitions owned by the members of the zone.
+	Partitions int
+
+	// Backups is the number of backups, see Config.BackupCount, held by the members of the zone.
+	Backups int
+
+	// SpareOnLoss is the number of partitions the members of the other zones could take on top of all partitions
+	// under the load bound if the zone failed. It's negative if they can't take them all.
+	SpareOnLoss int
+
+	// SurvivesLoss is set if the members of the other zones could own all partitions under the load bound if the
+	// zone failed.
+	SurvivesLoss bool
+}
+
+// MembersByZone returns the members grouped by their zones, see ZonedMember, in ascending order of their names.
+// Members which don't report a zone are listed under the empty zone.
+func (c *Consistent) MembersByZone() map[string][]Member {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	res := make(map[string][]Member)
+	for _, member := range c.members {
+		zone := memberZone(*member)
+		res[zone] = append(res[zone], *member)
+	}
+	for _, members := range res {
+		sort.Slice(members, func(i, j int) bool {
+			return members[i].String() < members[j].String()
+		})
+	}
+	return res
+}
+
+// TopologyReport returns a summary of every zone of the ring, see MembersByZone, e.g. to verify that the loss of
+// any zone leaves enough capacity under the load factor. Draining and leaving members count for the partitions
+// they hold, but not for the capacity left after a loss.
+func (c *Consistent) TopologyReport() map[string]ZoneReport {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	res := make(map[string]ZoneReport)
+	for _, member := range c.members {
+		zone := memberZone(*member)
+		r := res[zone]
+		r.Members++
+		res[zone] = r
+	}
+	for partID, owner := range c.partitions {
+		zone := memberZone(*owner)
+		r := res[zone]
+		r.Partitions++
+		res[zone] = r
+		if partID < len(c.replicas) && len(c.replicas[partID]) > 1 {
+			for _, backup := range c.replicas[partID][1:] {
+				zone := memberZone(backup)
+				r := res[zone]
+				r.Backups++
+				res[zone] = r
+			}
+		}
+	}
+	for zone, r := range res {
+		r.SpareOnLoss = c.capacityWithout(zone) - int(c.partitionCount)
+		r.SurvivesLoss = r.SpareOnLoss >= 0
+		res[zone] = r
+	}
+	return res
+}
+
+// capacityWithout returns the number of partitions the members outside of the zone could own under the load
+// bound if the partitions were distributed among them only. It's not thread-safe.
+func (c *Consistent) capacityWithout(zone string) int {
+	var survivors []Member
+	var total float64
+	for _, key := range c.memberHashes {
+		member := *c.hashedMembers[key]
+		if memberZone(member) == zone || c.drained(member) {
+			continue
+		}
+		if _, ok := c.leaving[member.String()]; ok {
+			continue
+		}
+		survivors = append(survivors, member)
+		total += c.weight(member)
+	}
+	if total == 0 {
+		return 0
+	}
+	var res float64
+	for _, member := range survivors {
+		bound := c.capacity(member, float64(c.partitionCount), c.memberLoad(member), total)
+		if max, ok := c.maxLoad(member); ok && max < bound {
+			bound = max
+		}
+		res += bound
+	}
+	return int(res)
+}
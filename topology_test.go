@@ -0,0 +1,89 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMembersByZone(t *testing.T) {
+	var members []Member
+	for i := 0; i < 6; i++ {
+		members = append(members, zonedMember{name: fmt.Sprintf("node%d.olric", i), zone: fmt.Sprintf("zone%d", i%3)})
+	}
+	members = append(members, testMember("node6.olric"))
+	c := New(members, newConfigWith(271))
+
+	zones := c.MembersByZone()
+	if len(zones) != 4 {
+		t.Fatalf("Expected 4 zones, Got: %v", zones)
+	}
+	if len(zones["zone1"]) != 2 || zones["zone1"][0].String() != "node1.olric" || zones["zone1"][1].String() != "node4.olric" {
+		t.Fatalf("Unexpected members of zone1: %v", zones["zone1"])
+	}
+	if len(zones[""]) != 1 || zones[""][0].String() != "node6.olric" {
+		t.Fatalf("Unexpected members without a zone: %v", zones[""])
+	}
+}
+
+func TestTopologyReport(t *testing.T) {
+	var members []Member
+	for i := 0; i < 6; i++ {
+		members = append(members, zonedMember{name: fmt.Sprintf("node%d.olric", i), zone: fmt.Sprintf("zone%d", i%3)})
+	}
+	cfg := newConfigWith(271)
+	cfg.BackupCount = 1
+	c := New(members, cfg)
+
+	report := c.TopologyReport()
+	var partitions, backups int
+	for zone, r := range report {
+		if r.Members != 2 {
+			t.Fatalf("Expected 2 members in %s, Got: %d", zone, r.Members)
+		}
+		if !r.SurvivesLoss {
+			t.Fatalf("Expected the ring to survive the loss of %s: %+v", zone, r)
+		}
+		partitions += r.Partitions
+		backups += r.Backups
+	}
+	if partitions != 271 || backups != 271 {
+		t.Fatalf("Expected 271 partitions and backups, Got: %d, %d", partitions, backups)
+	}
+
+	// The members of zone1 and zone2 can't own more than 240 partitions.
+	for _, name := range []string{"node1.olric", "node2.olric", "node4.olric", "node5.olric"} {
+		if err := c.SetMaxLoad(name, 60); err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+	}
+	r := c.TopologyReport()["zone0"]
+	if r.SurvivesLoss || r.SpareOnLoss != 240-271 {
+		t.Fatalf("Expected the loss of zone0 to leave too little capacity: %+v", r)
+	}
+	if r := c.TopologyReport()["zone1"]; !r.SurvivesLoss {
+		t.Fatalf("Expected the ring to survive the loss of zone1: %+v", r)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+// PartitionID identifies a partition. Partition IDs range from zero to Config.PartitionCount-1.
+type PartitionID int
+
+// MemberName is the name of a member, as returned by its String method.
+type MemberName string
+
+// NameOf returns the name of the member.
+func NameOf(member Member) MemberName {
+	return MemberName(member.String())
+}
+
+// TypedRing is a view of a consistent hash ring whose methods take PartitionID and MemberName instead of plain
+// ints and strings, so the compiler catches a partition ID swapped with a count or a hash. It is a preview of
+// the signatures of the next major version and shares the state of the underlying ring.
+type TypedRing struct {
+	c *Consistent
+}
+
+// Typed returns a typed view of the consistent hash ring.
+func (c *Consistent) Typed() TypedRing {
+	return TypedRing{c: c}
+}
+
+// Consistent returns the underlying consistent hash ring.
+func (r TypedRing) Consistent() *Consistent {
+	return r.c
+}
+
+// FindPartitionID returns partition id for given key.
+func (r TypedRing) FindPartitionID(key []byte) PartitionID {
+	return PartitionID(r.c.FindPartitionID(key))
+}
+
+// GetPartitionOwner returns the owner of the given partition.
+func (r TypedRing) GetPartitionOwner(partID PartitionID) Member {
+	return r.c.GetPartitionOwner(int(partID))
+}
+
+// GetPartitionOwnerAndBackups returns the owner of the given partition followed by its backups.
+func (r TypedRing) GetPartitionOwnerAndBackups(partID PartitionID) []Member {
+	return r.c.GetPartitionOwnerAndBackups(int(partID))
+}
+
+// GetClosestNForPartition returns the closest N member for given partition.
+func (r TypedRing) GetClosestNForPartition(partID PartitionID, count int) ([]Member, error) {
+	return r.c.GetClosestNForPartition(int(partID), count)
+}
+
+// Add adds a new member to the consistent hash circle.
+func (r TypedRing) Add(member Member) {
+	r.c.Add(member)
+}
+
+// Remove removes a member from the consistent hash circle.
+func (r TypedRing) Remove(name MemberName) {
+	r.c.Remove(string(name))
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTypedRing(t *testing.T) {
+	var members []Member
+	for i := 0; i < 8; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	cfg := newConfig()
+	cfg.BackupCount = 1
+	c := New(members, cfg)
+	r := c.Typed()
+	if r.Consistent() != c {
+		t.Fatalf("Expected the underlying ring")
+	}
+
+	key := []byte("Olric")
+	partID := r.FindPartitionID(key)
+	if int(partID) != c.FindPartitionID(key) {
+		t.Fatalf("Expected partition %d, Got: %d", c.FindPartitionID(key), partID)
+	}
+	owner := r.GetPartitionOwner(partID)
+	if owner.String() != c.LocateKey(key).String() {
+		t.Fatalf("Expected owner %s, Got: %s", c.LocateKey(key), owner)
+	}
+	if replicas := r.GetPartitionOwnerAndBackups(partID); len(replicas) != 2 || replicas[0].String() != owner.String() {
+		t.Fatalf("Unexpected replicas: %v", replicas)
+	}
+	if closest, err := r.GetClosestNForPartition(partID, 2); err != nil || len(closest) != 2 {
+		t.Fatalf("Unexpected closest members: %v, %v", closest, err)
+	}
+
+	r.Remove(NameOf(owner))
+	if len(c.GetMembers()) != 7 {
+		t.Fatalf("Expected 7 members, Got: %d", len(c.GetMembers()))
+	}
+	r.Add(owner)
+	if len(c.GetMembers()) != 8 {
+		t.Fatalf("Expected 8 members, Got: %d", len(c.GetMembers()))
+	}
+}
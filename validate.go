@@ -0,0 +1,127 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+)
+
+// Validate checks the internal invariants of the consistent hash ring and returns an error describing the first
+// violation. It is useful in tests and as a periodic self-check in production.
+func (c *Consistent) Validate() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.validate()
+}
+
+// validate checks the internal invariants. It's not thread-safe.
+func (c *Consistent) validate() error {
+	// The sorted set and the ring must contain the same virtual nodes.
+	if len(c.sortedSet) != len(c.ring) {
+		return fmt.Errorf("sorted set has %d virtual nodes, ring has %d", len(c.sortedSet), len(c.ring))
+	}
+	for i, h := range c.sortedSet {
+		if i > 0 && c.sortedSet[i-1] >= h {
+			return fmt.Errorf("sorted set is not sorted at index %d", i)
+		}
+		member, ok := c.ring[h]
+		if !ok {
+			return fmt.Errorf("virtual node %d is not on the ring", h)
+		}
+		if _, ok := c.members[(*member).String()]; !ok {
+			return fmt.Errorf("virtual node %d belongs to unknown member %s", h, (*member).String())
+		}
+	}
+
+	var vnodes int
+	for name, hashes := range c.vnodes {
+		for _, h := range hashes {
+			member, ok := c.ring[h]
+			if !ok || (*member).String() != name {
+				return fmt.Errorf("virtual node %d of %s is not on the ring", h, name)
+			}
+		}
+		vnodes += len(hashes)
+	}
+	if vnodes != len(c.ring) {
+		return fmt.Errorf("members have %d virtual nodes, ring has %d", vnodes, len(c.ring))
+	}
+	for i := 1; i < len(c.memberHashes); i++ {
+		if c.memberHashes[i-1] >= c.memberHashes[i] {
+			return fmt.Errorf("member hashes are not sorted at index %d", i)
+		}
+	}
+	if len(c.memberHashes) != len(c.hashedMembers) {
+		return fmt.Errorf("%d member hashes, %d hashed members", len(c.memberHashes), len(c.hashedMembers))
+	}
+
+	if c.pending {
+		// The partition table is stale on purpose until the deferred distribution runs.
+		return nil
+	}
+
+	if len(c.members) == 0 {
+		if len(c.partitions) != 0 || len(c.loads) != 0 {
+			return fmt.Errorf("consistent hash ring is empty but has %d partition owners", len(c.partitions))
+		}
+		return nil
+	}
+
+	// Every partition must be owned by a member and the loads must match the partition table.
+	loads := make(map[string]float64)
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		member, ok := c.partitions[partID]
+		if !ok {
+			return fmt.Errorf("partition %d has no owner", partID)
+		}
+		name := (*member).String()
+		if _, ok := c.members[name]; !ok {
+			return fmt.Errorf("partition %d is owned by unknown member %s", partID, name)
+		}
+		loads[name]++
+	}
+	if len(c.partitions) != int(c.partitionCount) {
+		return fmt.Errorf("partition table has %d partitions, expected %d", len(c.partitions), c.partitionCount)
+	}
+
+	var total float64
+	for name, load := range c.loads {
+		if load != loads[name] {
+			return fmt.Errorf("load of %s is %g, but it owns %g partitions", name, load, loads[name])
+		}
+		member, ok := c.members[name]
+		if !ok {
+			return fmt.Errorf("load of unknown member %s is %g", name, load)
+		}
+		if bound := c.loadBound(*member); load > bound {
+			return fmt.Errorf("load of %s is %g, exceeds its load bound %g", name, load, bound)
+		}
+		total += load
+	}
+	if total != float64(c.partitionCount) {
+		return fmt.Errorf("loads sum to %g, expected %d", total, c.partitionCount)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestConsistentValidate(t *testing.T) {
+	t.Run("Random membership changes keep the invariants", func(t *testing.T) {
+		cfg := newConfig()
+		c := New(nil, cfg)
+		if err := c.Validate(); err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+		r := rand.New(rand.NewSource(42))
+		for i := 0; i < 200; i++ {
+			member := testMember(fmt.Sprintf("node%d.olric", r.Intn(12)))
+			if r.Intn(3) == 0 {
+				c.Remove(member.String())
+			} else {
+				c.Add(member)
+			}
+			if err := c.Validate(); err != nil {
+				t.Fatalf("Expected nil after %d changes, Got: %v", i, err)
+			}
+		}
+	})
+
+	t.Run("Corrupted partition table", func(t *testing.T) {
+		var members []Member
+		for i := 0; i < 8; i++ {
+			members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+		}
+		c := New(members, newConfig())
+		delete(c.partitions, 3)
+		if err := c.Validate(); err == nil {
+			t.Fatalf("Expected an error for a partition without owner")
+		}
+	})
+
+	t.Run("Corrupted sorted set", func(t *testing.T) {
+		var members []Member
+		for i := 0; i < 8; i++ {
+			members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+		}
+		c := New(members, newConfig())
+		c.sortedSet[0], c.sortedSet[1] = c.sortedSet[1], c.sortedSet[0]
+		if err := c.Validate(); err == nil {
+			t.Fatalf("Expected an error for an unsorted set")
+		}
+	})
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"sort"
+)
+
+// virtualNodeHash returns the hash of the given replica of a member. A positive salt re-probes the replica
+// after a collision.
+func (c *Consistent) virtualNodeHash(name string, replica, salt int) uint64 {
+	if salt == 0 {
+		return c.hasher.Sum64([]byte(fmt.Sprintf("%s%d", name, replica)))
+	}
+	return c.hasher.Sum64([]byte(fmt.Sprintf("%s%d#%d", name, replica, salt)))
+}
+
+// precedes reports whether the virtual node (name, replica) has priority over (otherName, otherReplica)
+// when both hash to the same point.
+func precedes(name string, replica int, otherName string, otherReplica int) bool {
+	if name != otherName {
+		return name < otherName
+	}
+	return replica < otherReplica
+}
+
+// insertVirtualNode puts the replica of the member on the ring, probing with increasing salts from the given one.
+// If the point is taken by a virtual node with lower priority, that one is displaced and re-probed in turn.
+// Priorities make the final layout independent of the insertion order. It returns the point which became
+// occupied. It's not thread-safe.
+func (c *Consistent) insertVirtualNode(member *Member, replica, salt int) uint64 {
+	name := (*member).String()
+	for {
+		h := c.virtualNodeHash(name, replica, salt)
+		existing, ok := c.ring[h]
+		if !ok {
+			c.ring[h] = member
+			c.vnodes[name][replica] = h
+			c.setSalt(h, salt)
+			return h
+		}
+
+		// Another virtual node already sits on this point. Re-probe deterministically
+		// instead of overwriting it.
+		c.collisions++
+		existingName := (*existing).String()
+		existingReplica := replicaIndex(c.vnodes[existingName], h)
+		if !precedes(name, replica, existingName, existingReplica) {
+			salt++
+			continue
+		}
+		existingSalt := c.salts[h]
+		c.ring[h] = member
+		c.vnodes[name][replica] = h
+		c.setSalt(h, salt)
+		member, name, replica, salt = existing, existingName, existingReplica, existingSalt+1
+	}
+}
+
+// setSalt records the salt of the virtual node on the given point. Only re-probed virtual nodes are recorded.
+func (c *Consistent) setSalt(h uint64, salt int) {
+	if salt == 0 {
+		delete(c.salts, h)
+		return
+	}
+	c.salts[h] = salt
+}
+
+// replicaIndex returns the replica number of the virtual node on the given point.
+func replicaIndex(hashes []uint64, h uint64) int {
+	for i, hash := range hashes {
+		if hash == h {
+			return i
+		}
+	}
+	return -1
+}
+
+// resettle takes every re-probed virtual node off the ring and inserts it again, after a removal freed some points.
+// The result is the same layout as building the ring from the remaining members. It's not thread-safe.
+func (c *Consistent) resettle() {
+	type vnode struct {
+		member  *Member
+		replica int
+	}
+	removed := make([]uint64, 0, len(c.salts))
+	vnodes := make([]vnode, 0, len(c.salts))
+	for h := range c.salts {
+		member := c.ring[h]
+		vnodes = append(vnodes, vnode{member: member, replica: replicaIndex(c.vnodes[(*member).String()], h)})
+		removed = append(removed, h)
+	}
+	for _, h := range removed {
+		delete(c.ring, h)
+		delete(c.salts, h)
+	}
+	// The insertion order doesn't matter for the result, sort anyway to keep the collision counter deterministic.
+	sort.Slice(vnodes, func(i, j int) bool {
+		return precedes((*vnodes[i].member).String(), vnodes[i].replica, (*vnodes[j].member).String(), vnodes[j].replica)
+	})
+
+	added := make([]uint64, 0, len(vnodes))
+	for _, v := range vnodes {
+		added = append(added, c.insertVirtualNode(v.member, v.replica, 0))
+	}
+	sortHashes(added)
+	c.sortedSet = mergeHashes(deleteHashes(c.sortedSet, removed), added)
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"math"
+	"time"
+)
+
+// Defaults of the warmup ramp, see Config.WarmupDuration.
+const (
+	DefaultWarmupStart = 0.1
+	DefaultWarmupSteps = 10
+)
+
+// warmup is the ramp of a member which joined recently.
+type warmup struct {
+	start    time.Time
+	duration time.Duration
+}
+
+// AddWithWarmup adds a new member like Add, but its capacity ramps up over the given duration instead of
+// Config.WarmupDuration. A non-positive duration adds the member at full capacity.
+func (c *Consistent) AddWithWarmup(member Member, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.validName(member.String()) {
+		return
+	}
+	if _, ok := c.members[c.memberName(member.String())]; ok {
+		return
+	}
+	if i := c.standbyIndex(member.String()); i >= 0 {
+		c.standbys = append(c.standbys[:i:i], c.standbys[i+1:]...)
+	}
+	c.add(member)
+	delete(c.warming, member.String())
+	if duration > 0 {
+		c.warming[member.String()] = warmup{start: c.clock().Now(), duration: duration}
+	}
+	c.redistribute()
+}
+
+// Warming returns the current share of the capacity of the member, between Config.WarmupStart and one. It's one
+// for members which aren't warming up.
+func (c *Consistent) Warming(name string) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	name = c.memberName(name)
+	member, ok := c.members[name]
+	if !ok {
+		return 1
+	}
+	return c.warmupFactor(*member, c.clock().Now())
+}
+
+// startWarmup starts the ramp of a member added by a membership change, if Config.WarmupDuration is set.
+// It's not thread-safe.
+func (c *Consistent) startWarmup(name string) {
+	if c.config.WarmupDuration > 0 {
+		c.warming[name] = warmup{start: c.clock().Now(), duration: c.config.WarmupDuration}
+	}
+}
+
+// warmupFactor returns the share of the capacity of the member at the given time. The share grows in
+// Config.WarmupSteps steps, so a distribution on every step changes the partition table. It's not thread-safe.
+func (c *Consistent) warmupFactor(member Member, now time.Time) float64 {
+	if len(c.warming) == 0 {
+		return 1
+	}
+	w, ok := c.warming[member.String()]
+	if !ok {
+		return 1
+	}
+	elapsed := now.Sub(w.start)
+	if elapsed >= w.duration {
+		return 1
+	}
+	start, steps := c.warmupStart(), c.warmupSteps()
+	step := math.Floor(float64(elapsed) / (float64(w.duration) / float64(steps)))
+	return start + (1-start)*step/float64(steps)
+}
+
+func (c *Consistent) warmupStart() float64 {
+	if c.config.WarmupStart <= 0 || c.config.WarmupStart > 1 {
+		return DefaultWarmupStart
+	}
+	return c.config.WarmupStart
+}
+
+func (c *Consistent) warmupSteps() int {
+	if c.config.WarmupSteps <= 0 {
+		return DefaultWarmupSteps
+	}
+	return c.config.WarmupSteps
+}
+
+// weight returns the weight of the member scaled by a WeightController and by its warmup ramp at the time of
+// the current distribution.
+// It's not thread-safe.
+func (c *Consistent) weight(member Member) float64 {
+	w := memberWeight(member)
+	if len(c.weightScales) != 0 {
+		w *= c.weightScale(member.String())
+	}
+	if len(c.warming) == 0 {
+		return w
+	}
+	return w * c.warmupFactor(member, c.rampAt)
+}
+
+// scheduleWarmup forgets the members whose ramp is over and arms a timer which distributes the partitions again
+// at the next step of the remaining ramps. It's not thread-safe.
+func (c *Consistent) scheduleWarmup() {
+	if c.detached || len(c.warming) == 0 {
+		return
+	}
+	now := c.clock().Now()
+	var next time.Duration
+	steps := c.warmupSteps()
+	for name, w := range c.warming {
+		elapsed := now.Sub(w.start)
+		if elapsed >= w.duration {
+			delete(c.warming, name)
+			continue
+		}
+		interval := w.duration / time.Duration(steps)
+		wait := interval - elapsed%interval
+		if next == 0 || wait < next {
+			next = wait
+		}
+	}
+	if next == 0 {
+		return
+	}
+	if c.warmupTimer != nil {
+		c.warmupTimer.Stop()
+	}
+	c.warmupTimer = c.clock().AfterFunc(next, c.warmupTick)
+}
+
+// warmupTick distributes the partitions at the next step of a ramp. Like a deferred distribution, a failed one
+// keeps the previous partition table.
+func (c *Consistent) warmupTick() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.warming) == 0 || len(c.members) == 0 {
+		return
+	}
+	if c.config.DistributionDebounce > 0 || c.config.AsyncDistribution {
+		c.redistribute()
+		return
+	}
+	_ = c.redistributeNow()
+}
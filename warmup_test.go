@@ -0,0 +1,114 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWarmup(t *testing.T) {
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.WarmupDuration = time.Hour
+	cfg.WarmupStart = 0.2
+	var members []Member
+	for i := 0; i < 4; i++ {
+		members = append(members, testMember(fmt.Sprintf("node%d.olric", i)))
+	}
+	c := New(members, cfg)
+	if c.Warming("node0.olric") != 1 {
+		t.Fatalf("Members of New don't warm up")
+	}
+
+	c.Add(testMember("node4.olric"))
+	if share := c.Warming("node4.olric"); share != 0.2 {
+		t.Fatalf("Expected 0.2, Got: %g", share)
+	}
+	// Weight 0.2 out of 4.2: ceil(271*0.2/4.2*1.25)
+	if load := c.LoadDistribution()["node4.olric"]; load > 17 {
+		t.Fatalf("Expected at most 17 partitions, Got: %g", load)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	// Half way through the ramp.
+	c.mu.Lock()
+	w := c.warming["node4.olric"]
+	w.start = w.start.Add(-30 * time.Minute)
+	c.warming["node4.olric"] = w
+	c.mu.Unlock()
+	if share := c.Warming("node4.olric"); share < 0.59 || share > 0.61 {
+		t.Fatalf("Expected 0.6, Got: %g", share)
+	}
+	before := c.LoadDistribution()["node4.olric"]
+	version := c.Version()
+	c.warmupTick()
+	if c.Version() != version+1 || c.LoadDistribution()["node4.olric"] <= before {
+		t.Fatalf("Expected node4.olric to own more partitions")
+	}
+
+	// After the ramp, the member is a regular one.
+	c.mu.Lock()
+	w.start = w.start.Add(-time.Hour)
+	c.warming["node4.olric"] = w
+	c.mu.Unlock()
+	c.warmupTick()
+	if len(c.warming) != 0 {
+		t.Fatalf("Expected the ramp to be over")
+	}
+	if !c.Equal(New(append(members, testMember("node4.olric")), newConfigWith(271))) {
+		t.Fatalf("Expected the layout without warmup")
+	}
+}
+
+func newConfigWith(partitionCount int) Config {
+	cfg := newConfig()
+	cfg.PartitionCount = partitionCount
+	return cfg
+}
+
+func TestWarmupTicks(t *testing.T) {
+	cfg := newConfigWith(271)
+	cfg.WarmupSteps = 2
+	c := New([]Member{testMember("node0.olric"), testMember("node1.olric")}, cfg)
+	c.AddWithWarmup(testMember("node2.olric"), 100*time.Millisecond)
+	version := c.Version()
+
+	// The timer distributes the partitions at every step.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.WaitForVersion(ctx, version+2); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if c.Warming("node2.olric") != 1 {
+		t.Fatalf("Expected the ramp to be over")
+	}
+	if !c.Equal(New([]Member{testMember("node0.olric"), testMember("node1.olric"), testMember("node2.olric")}, newConfigWith(271))) {
+		t.Fatalf("Expected the layout without warmup")
+	}
+}
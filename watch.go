@@ -0,0 +1,218 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"sort"
+	"sync"
+)
+
+// MemberEvents are the partitions a member gained or lost as owner between two versions of the partition table,
+// see WatchMember.
+type MemberEvents struct {
+	// Member is the name passed to WatchMember.
+	Member string
+
+	// From and To are the versions of the partition table the change leads from and to. Events which were not
+	// received in time are merged, so To may be more than one version after From.
+	From uint64
+	To   uint64
+
+	// Gained and Lost are the partitions the member took over and handed off, ordered by partition ID.
+	Gained []int
+	Lost   []int
+}
+
+// memberWatcher delivers the events of a member to a channel. Events are queued while the receiver is busy, and
+// merged, so the lock of the ring is never held while waiting for the receiver.
+type memberWatcher struct {
+	name string
+	ch   chan MemberEvents
+	wake chan struct{}
+	done chan struct{}
+
+	mu      sync.Mutex
+	pending *MemberEvents
+}
+
+// WatchMember returns a channel which receives the partitions gained and lost by the member on every change of
+// the partition table, e.g. for a data node which only cares about its own partitions. The first event holds the
+// partitions the member owns now, if any. The member doesn't have to be on the ring yet. Stop watching with
+// Unwatch, which closes the channel.
+func (c *Consistent) WatchMember(name string) <-chan MemberEvents {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &memberWatcher{
+		name: c.normalize(name),
+		ch:   make(chan MemberEvents),
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	var owned []int
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		if owner, ok := c.partitions[partID]; ok && c.normalize((*owner).String()) == w.name {
+			owned = append(owned, partID)
+		}
+	}
+	if len(owned) != 0 {
+		w.push(MemberEvents{Member: name, From: c.version, To: c.version, Gained: owned})
+	}
+	if c.watchers == nil {
+		c.watchers = make(map[<-chan MemberEvents]*memberWatcher)
+	}
+	c.watchers[w.ch] = w
+	go w.run(name)
+	return w.ch
+}
+
+// Unwatch stops the delivery of the events to a channel returned by WatchMember and closes it.
+func (c *Consistent) Unwatch(events <-chan MemberEvents) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if w, ok := c.watchers[events]; ok {
+		delete(c.watchers, events)
+		close(w.done)
+	}
+}
+
+// notifyWatchers passes the partitions which moved from the previous partition table to the current one to the
+// watchers of their old and new owners. It must be called right before the version is bumped. It's not
+// thread-safe.
+func (c *Consistent) notifyWatchers(previous map[int]*Member) {
+	if len(c.watchers) == 0 {
+		return
+	}
+	gained := make(map[string][]int)
+	lost := make(map[string][]int)
+	for partID := 0; partID < int(c.partitionCount); partID++ {
+		var from, to Member
+		if m, ok := previous[partID]; ok {
+			from = *m
+		}
+		if m, ok := c.partitions[partID]; ok {
+			to = *m
+		}
+		if sameMember(from, to) {
+			continue
+		}
+		if from != nil {
+			name := c.normalize(from.String())
+			lost[name] = append(lost[name], partID)
+		}
+		if to != nil {
+			name := c.normalize(to.String())
+			gained[name] = append(gained[name], partID)
+		}
+	}
+	for _, w := range c.watchers {
+		if len(gained[w.name]) == 0 && len(lost[w.name]) == 0 {
+			continue
+		}
+		w.push(MemberEvents{From: c.version, To: c.version + 1, Gained: gained[w.name], Lost: lost[w.name]})
+	}
+}
+
+// push queues the events, merging them with the ones the receiver hasn't taken yet.
+func (w *memberWatcher) push(events MemberEvents) {
+	w.mu.Lock()
+	if w.pending == nil {
+		w.pending = &events
+	} else {
+		w.pending.merge(events)
+	}
+	w.mu.Unlock()
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers the queued events until the watcher is stopped, then closes the channel.
+func (w *memberWatcher) run(name string) {
+	defer close(w.ch)
+	for {
+		select {
+		case <-w.wake:
+		case <-w.done:
+			return
+		}
+		w.mu.Lock()
+		events := w.pending
+		w.pending = nil
+		w.mu.Unlock()
+		if events == nil {
+			continue
+		}
+		events.Member = name
+		select {
+		case w.ch <- *events:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// merge appends the later events to e. A partition which was gained and lost again in between is in neither list.
+func (e *MemberEvents) merge(later MemberEvents) {
+	gained := make(map[int]bool, len(e.Gained)+len(later.Gained))
+	for _, partID := range e.Gained {
+		gained[partID] = true
+	}
+	lost := make(map[int]bool, len(e.Lost)+len(later.Lost))
+	for _, partID := range e.Lost {
+		lost[partID] = true
+	}
+	for _, partID := range later.Lost {
+		if gained[partID] {
+			delete(gained, partID)
+		} else {
+			lost[partID] = true
+		}
+	}
+	for _, partID := range later.Gained {
+		if lost[partID] {
+			delete(lost, partID)
+		} else {
+			gained[partID] = true
+		}
+	}
+	e.Gained, e.Lost = sortedKeys(gained), sortedKeys(lost)
+	e.To = later.To
+}
+
+// sortedKeys returns the keys of the set in ascending order, nil if it's empty.
+func sortedKeys(set map[int]bool) []int {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]int, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func receiveEvents(t *testing.T, ch <-chan MemberEvents) MemberEvents {
+	t.Helper()
+	select {
+	case events, ok := <-ch:
+		if !ok {
+			t.Fatalf("Expected events, the channel is closed")
+		}
+		return events
+	case <-time.After(time.Second):
+		t.Fatalf("Expected events")
+	}
+	return MemberEvents{}
+}
+
+func ownedPartitions(c *Consistent, name string) map[int]bool {
+	owned := make(map[int]bool)
+	for partID, owner := range c.owners() {
+		if owner.String() == name {
+			owned[partID] = true
+		}
+	}
+	return owned
+}
+
+func TestWatchMember(t *testing.T) {
+	c := New(testMembers(4), newConfigWith(271))
+	watched := append(testMembers(4), testMember("node4.olric"))
+	channels := make(map[string]<-chan MemberEvents)
+	before := make(map[string]map[int]bool)
+	for _, member := range watched {
+		name := member.String()
+		channels[name] = c.WatchMember(name)
+		defer c.Unwatch(channels[name])
+		before[name] = ownedPartitions(c, name)
+		if len(before[name]) == 0 {
+			continue
+		}
+		initial := receiveEvents(t, channels[name])
+		if initial.Member != name || initial.To != c.Version() || len(initial.Gained) != len(before[name]) ||
+			len(initial.Lost) != 0 {
+			t.Fatalf("Expected the owned partitions of %s at version %d, got: %+v", name, c.Version(), initial)
+		}
+	}
+
+	version := c.Version()
+	c.Add(testMember("node4.olric"))
+	var lost bool
+	for _, member := range watched {
+		name := member.String()
+		owned, after := before[name], ownedPartitions(c, name)
+		if sameSet(owned, after) {
+			continue
+		}
+		events := receiveEvents(t, channels[name])
+		if events.From != version || events.To != c.Version() {
+			t.Fatalf("Expected versions %d..%d, got: %d..%d", version, c.Version(), events.From, events.To)
+		}
+		for _, partID := range events.Lost {
+			if !owned[partID] || after[partID] {
+				t.Fatalf("Unexpected lost partition %d of %s", partID, name)
+			}
+			delete(owned, partID)
+			lost = true
+		}
+		for _, partID := range events.Gained {
+			if owned[partID] || !after[partID] {
+				t.Fatalf("Unexpected gained partition %d of %s", partID, name)
+			}
+			owned[partID] = true
+		}
+		if !sameSet(owned, after) {
+			t.Fatalf("Expected the events to lead to the partitions of %s", name)
+		}
+	}
+	if !lost {
+		t.Fatalf("Expected a member to lose partitions")
+	}
+}
+
+func sameSet(a, b map[int]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key := range a {
+		if !b[key] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestWatchMemberMerge(t *testing.T) {
+	c := New(testMembers(4), newConfigWith(271))
+	ch := c.WatchMember("node4.olric")
+	defer c.Unwatch(ch)
+
+	version := c.Version()
+	c.Add(testMember("node4.olric"))
+	c.Add(testMember("node5.olric"))
+	c.Remove("node4.olric")
+
+	// The receiver was busy, the later events are merged. Applying them leaves nothing.
+	owned := make(map[int]bool)
+	received := 0
+	for to := version; to != c.Version(); received++ {
+		events := receiveEvents(t, ch)
+		if events.From != to {
+			t.Fatalf("Expected events from version %d, got: %d", to, events.From)
+		}
+		for _, partID := range events.Lost {
+			delete(owned, partID)
+		}
+		for _, partID := range events.Gained {
+			owned[partID] = true
+		}
+		to = events.To
+	}
+	if len(owned) != 0 {
+		t.Fatalf("Expected no partitions, got: %v", owned)
+	}
+	if received >= 3 {
+		t.Fatalf("Expected merged events, got %d", received)
+	}
+}
+
+func TestMemberEventsMerge(t *testing.T) {
+	e := MemberEvents{From: 1, To: 2, Gained: []int{1, 2}, Lost: []int{5}}
+	e.merge(MemberEvents{From: 2, To: 4, Gained: []int{5, 7}, Lost: []int{2, 3}})
+	if e.From != 1 || e.To != 4 {
+		t.Fatalf("Expected versions 1..4, got: %d..%d", e.From, e.To)
+	}
+	if !reflect.DeepEqual(e.Gained, []int{1, 7}) || !reflect.DeepEqual(e.Lost, []int{3}) {
+		t.Fatalf("Unexpected merged events: %+v", e)
+	}
+}
+
+func TestUnwatch(t *testing.T) {
+	c := New(testMembers(4), newConfig())
+	ch := c.WatchMember("node9.olric")
+	c.Unwatch(ch)
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatalf("Expected no events")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the channel to be closed")
+	}
+	c.Add(testMember("node9.olric"))
+	c.Unwatch(ch)
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// WeightControllerConfig configures a WeightController.
+type WeightControllerConfig struct {
+	// MinScale and MaxScale bound the factor the controller applies to the weight of a member. The defaults are
+	// 0.5 and 2.
+	MinScale float64
+	MaxScale float64
+
+	// Gain is the share of the correction applied by a step, between 0 and 1. Lower values converge slower but
+	// react less to noise. The default is 0.5.
+	Gain float64
+
+	// Tolerance is the relative deviation from the mean rate below which a step leaves the weights alone, so
+	// small imbalances don't move partitions. The default is 0.1.
+	Tolerance float64
+}
+
+// WeightController adjusts the effective weights of the members of a ring from their observed traffic, so the
+// distribution converges towards equal request or byte rates instead of equal partition counts. Feed the rates
+// with Observe and call Step, or Start it. The scales aren't part of snapshots and are dropped when a member
+// leaves the ring.
+type WeightController struct {
+	c      *Consistent
+	config WeightControllerConfig
+
+	mu      sync.Mutex
+	rates   map[string]float64
+	timer   Timer
+	stopped bool
+}
+
+// NewWeightController returns a WeightController for the ring.
+func NewWeightController(c *Consistent, config WeightControllerConfig) *WeightController {
+	if config.MinScale <= 0 {
+		config.MinScale = 0.5
+	}
+	if config.MaxScale < config.MinScale {
+		config.MaxScale = math.Max(2, config.MinScale)
+	}
+	if config.Gain <= 0 || config.Gain > 1 {
+		config.Gain = 0.5
+	}
+	if config.Tolerance <= 0 {
+		config.Tolerance = 0.1
+	}
+	return &WeightController{
+		c:      c,
+		config: config,
+		rates:  make(map[string]float64),
+	}
+}
+
+// Observe records the rate of the member since the last step, e.g. requests or bytes per second. A negative or
+// NaN rate is ignored.
+func (w *WeightController) Observe(name string, rate float64) {
+	if rate < 0 || math.IsNaN(rate) {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.rates[name] = rate
+}
+
+// Step scales the weights of the members which reported a rate since the last step by their deviation from the
+// mean rate, and distributes the partitions again if any scale changed. Members without a rate keep their
+// scale. It reports whether the weights changed.
+func (w *WeightController) Step() bool {
+	w.mu.Lock()
+	rates := w.rates
+	w.rates = make(map[string]float64)
+	w.mu.Unlock()
+
+	w.c.mu.Lock()
+	defer w.c.mu.Unlock()
+
+	var sum float64
+	observed := make(map[string]float64, len(rates))
+	for name, rate := range rates {
+		name = w.c.memberName(name)
+		if _, ok := w.c.members[name]; ok {
+			observed[name] = rate
+			sum += rate
+		}
+	}
+	if len(observed) == 0 || sum == 0 {
+		return false
+	}
+	mean := sum / float64(len(observed))
+	balanced := true
+	for _, rate := range observed {
+		if math.Abs(rate/mean-1) > w.config.Tolerance {
+			balanced = false
+			break
+		}
+	}
+	if balanced {
+		return false
+	}
+
+	scales := make(map[string]float64, len(w.c.weightScales)+len(observed))
+	for name, scale := range w.c.weightScales {
+		scales[name] = scale
+	}
+	changed := false
+	for name, rate := range observed {
+		current := w.c.weightScale(name)
+		scale := current * math.Pow(mean/rate, w.config.Gain)
+		scale = math.Min(math.Max(scale, w.config.MinScale), w.config.MaxScale)
+		if scale != current {
+			changed = true
+		}
+		if scale == 1 {
+			delete(scales, name)
+			continue
+		}
+		scales[name] = scale
+	}
+	if !changed {
+		return false
+	}
+	w.c.weightScales = scales
+	w.c.redistribute()
+	return true
+}
+
+// Start runs Step every interval on Config.Clock until the returned function is called.
+func (w *WeightController) Start(interval time.Duration) (stop func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stopped = false
+	w.schedule(interval)
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		w.stopped = true
+		if w.timer != nil {
+			w.timer.Stop()
+		}
+	}
+}
+
+// schedule arms the timer of the next step. It's not thread-safe.
+func (w *WeightController) schedule(interval time.Duration) {
+	w.timer = w.c.clock().AfterFunc(interval, func() {
+		w.Step()
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		if !w.stopped {
+			w.schedule(interval)
+		}
+	})
+}
+
+// EffectiveWeight returns the weight of the member used by the distribution: its Weight, scaled by a
+// WeightController. ok is false if there is no such member.
+func (c *Consistent) EffectiveWeight(name string) (weight float64, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	member, ok := c.members[c.memberName(name)]
+	if !ok {
+		return 0, false
+	}
+	return memberWeight(*member) * c.weightScale((*member).String()), true
+}
+
+// dropWeightScale forgets the scale of the member. The map is replaced, an asynchronous distribution may be
+// reading it. It's not thread-safe.
+func (c *Consistent) dropWeightScale(name string) {
+	if _, ok := c.weightScales[name]; !ok {
+		return
+	}
+	scales := make(map[string]float64, len(c.weightScales)-1)
+	for other, scale := range c.weightScales {
+		if other != name {
+			scales[other] = scale
+		}
+	}
+	c.weightScales = scales
+}
+
+// weightScale returns the factor a WeightController applied to the weight of the member. It's not thread-safe.
+func (c *Consistent) weightScale(name string) float64 {
+	if scale, ok := c.weightScales[name]; ok {
+		return scale
+	}
+	return 1
+}
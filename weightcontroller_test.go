@@ -0,0 +1,160 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// observeHotness feeds the controller the rate of every member, the sum of the hotness of its partitions, and
+// returns the ratio of the highest rate to the mean.
+func observeHotness(c *Consistent, w *WeightController, hotness func(partID int) float64) float64 {
+	rates := make(map[string]float64)
+	for partID, owner := range c.owners() {
+		rates[owner.String()] += hotness(partID)
+	}
+	var sum, max float64
+	for _, member := range c.GetMembers() {
+		rate := rates[member.String()]
+		w.Observe(member.String(), rate)
+		sum += rate
+		max = math.Max(max, rate)
+	}
+	return max / (sum / float64(len(c.GetMembers())))
+}
+
+func TestWeightControllerConverges(t *testing.T) {
+	cfg := newConfigWith(271)
+	c := New(testMembers(6), cfg)
+	w := NewWeightController(c, WeightControllerConfig{MinScale: 0.25, MaxScale: 4})
+	// The partitions of one member are ten times hotter than the others.
+	hot := make(map[int]bool)
+	for partID, owner := range c.owners() {
+		if owner.String() == "node0.olric" {
+			hot[partID] = true
+		}
+	}
+	hotness := func(partID int) float64 {
+		if hot[partID] {
+			return 10
+		}
+		return 1
+	}
+
+	initial := observeHotness(c, w, hotness)
+	if !w.Step() {
+		t.Fatalf("Expected the weights to change")
+	}
+	for i := 0; i < 10; i++ {
+		observeHotness(c, w, hotness)
+		w.Step()
+	}
+	final := observeHotness(c, w, hotness)
+	if final >= initial {
+		t.Fatalf("Expected the imbalance to shrink from %v, got: %v", initial, final)
+	}
+	weight, ok := c.EffectiveWeight("node0.olric")
+	if !ok || weight >= 1 || weight < 0.25 {
+		t.Fatalf("Expected a reduced weight within bounds for the hot member, got: %v", weight)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, got: %v", err)
+	}
+
+	c.Remove("node0.olric")
+	if _, ok := c.weightScales["node0.olric"]; ok {
+		t.Fatalf("Expected the scale of the removed member to be dropped")
+	}
+}
+
+func TestWeightControllerTolerance(t *testing.T) {
+	c := New(testMembers(4), newConfig())
+	w := NewWeightController(c, WeightControllerConfig{Tolerance: 0.2})
+	version := c.Version()
+	w.Observe("node0.olric", 110)
+	w.Observe("node1.olric", 90)
+	w.Observe("node2.olric", 100)
+	w.Observe("unknown", 1000)
+	if w.Step() {
+		t.Fatalf("Expected no change within the tolerance")
+	}
+	if w.Step() {
+		t.Fatalf("Expected no change without rates")
+	}
+	if c.Version() != version {
+		t.Fatalf("Expected no distribution")
+	}
+	if weight, _ := c.EffectiveWeight("node0.olric"); weight != 1 {
+		t.Fatalf("Expected weight 1, got: %v", weight)
+	}
+	if _, ok := c.EffectiveWeight("unknown"); ok {
+		t.Fatalf("Expected no weight for an unknown member")
+	}
+}
+
+func TestWeightControllerBounds(t *testing.T) {
+	c := New(testMembers(4), newConfigWith(271))
+	w := NewWeightController(c, WeightControllerConfig{MinScale: 0.5, MaxScale: 2, Gain: 1})
+	for i := 0; i < 5; i++ {
+		w.Observe("node0.olric", 1000)
+		w.Observe("node1.olric", 0)
+		w.Observe("node2.olric", 100)
+		w.Observe("node3.olric", 100)
+		w.Step()
+	}
+	if weight, _ := c.EffectiveWeight("node0.olric"); weight != 0.5 {
+		t.Fatalf("Expected weight 0.5, got: %v", weight)
+	}
+	if weight, _ := c.EffectiveWeight("node1.olric"); weight != 2 {
+		t.Fatalf("Expected weight 2, got: %v", weight)
+	}
+}
+
+func TestWeightControllerStart(t *testing.T) {
+	clock := newFakeClock()
+	cfg := newConfigWith(271)
+	cfg.Clock = clock
+	c := New(testMembers(4), cfg)
+	w := NewWeightController(c, WeightControllerConfig{})
+	stop := w.Start(time.Minute)
+
+	version := c.Version()
+	w.Observe("node0.olric", 400)
+	w.Observe("node1.olric", 100)
+	clock.Advance(time.Minute)
+	if c.Version() == version {
+		t.Fatalf("Expected a distribution after the interval")
+	}
+
+	stop()
+	version = c.Version()
+	w.Observe("node0.olric", 400)
+	w.Observe("node1.olric", 100)
+	clock.Advance(time.Minute)
+	if c.Version() != version {
+		t.Fatalf("Expected no distribution after stop")
+	}
+}
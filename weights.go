@@ -0,0 +1,192 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"math"
+)
+
+// WeightedMember is a Member with a relative capacity. A member with weight 2 may own twice as many partitions
+// and backups as a member with weight 1. Members which don't implement WeightedMember, or return a non-positive
+// weight, have weight 1.
+type WeightedMember interface {
+	Member
+	Weight() float64
+}
+
+// memberWeight returns the weight of the given member.
+func memberWeight(member Member) float64 {
+	if wm, ok := member.(WeightedMember); ok {
+		if w := wm.Weight(); w > 0 {
+			return w
+		}
+	}
+	return 1
+}
+
+// memberLoad returns the load factor of the member: the one of its class in Config.ClassLoads, or Config.Load.
+// It's not thread-safe.
+func (c *Consistent) memberLoad(member Member) float64 {
+	load, class := c.classLoad(member)
+	if c.overflowTier > 0 {
+		// Config.Load isn't relaxed while only the overflow members are, see SetOverflowPriority.
+		if c.overflowing(member) {
+			load *= c.loadScale
+		}
+		return load
+	}
+	if class && c.loadScale > 0 {
+		load *= c.loadScale
+	}
+	return load
+}
+
+// classLoad returns the load factor of the class of the member, or Config.Load if it has none. class reports
+// whether it has one.
+func (c *Consistent) classLoad(member Member) (load float64, class bool) {
+	if len(c.config.ClassLoads) == 0 {
+		return c.loadFactor(), false
+	}
+	lm, ok := member.(LabeledMember)
+	if !ok {
+		return c.loadFactor(), false
+	}
+	label := c.config.ClassLabel
+	if label == "" {
+		label = DefaultClassLabel
+	}
+	load, ok = c.config.ClassLoads[lm.Labels()[label]]
+	if !ok {
+		return c.loadFactor(), false
+	}
+	return load, true
+}
+
+// totalWeight returns the sum of the weights of all members. The sum is computed in the order of the member
+// hashes, so it doesn't depend on the insertion order. It's not thread-safe.
+func (c *Consistent) totalWeight() float64 {
+	if c.weighted == 0 && len(c.warming) == 0 && len(c.weightScales) == 0 {
+		return float64(len(c.members) - len(c.draining))
+	}
+	var total float64
+	for _, key := range c.memberHashes {
+		if member := *c.hashedMembers[key]; !c.drained(member) {
+			total += c.weight(member)
+		}
+	}
+	return total
+}
+
+// capacity returns the maximum number of items the member may hold if count items are distributed
+// in proportion to the member weights with the given load. It's not thread-safe.
+func (c *Consistent) capacity(member Member, count, load, total float64) float64 {
+	// With equal weights, this is exactly the computation in averageLoad.
+	return math.Ceil((count * c.weight(member) / total) * load)
+}
+
+// loadBound returns the maximum number of partitions the member may own in the current distribution.
+// It's not thread-safe.
+func (c *Consistent) loadBound(member Member) float64 {
+	if c.drained(member) {
+		return 0
+	}
+	if bound, ok := c.leavingBound(member); ok {
+		return bound
+	}
+	bound := c.capacity(member, float64(c.partitionCount), c.memberLoad(member), c.weightSum)
+	if max, ok := c.maxLoad(member); ok && max < bound {
+		return max
+	}
+	return bound
+}
+
+// minimumLoad returns the smallest Load value which leaves enough room to distribute the partitions
+// among the current members. It's not thread-safe.
+func (c *Consistent) minimumLoad() float64 {
+	if len(c.members) == 0 || c.partitionCount == 0 {
+		return 0
+	}
+	total, maxWeight, maxUncapped := 0.0, 0.0, 0.0
+	var capped float64
+	uniform := true
+	for _, member := range c.members {
+		if c.drained(*member) {
+			continue
+		}
+		w := c.weight(*member)
+		if maxWeight != 0 && w != maxWeight {
+			uniform = false
+		}
+		if w > maxWeight {
+			maxWeight = w
+		}
+		if max, ok := c.maxLoad(*member); ok {
+			capped += max
+			uniform = false
+		} else if w > maxUncapped {
+			maxUncapped = w
+		}
+		total += w
+	}
+	if total == 0 {
+		// Every member is draining.
+		return math.Inf(1)
+	}
+	if uniform {
+		return MinimumFeasibleLoadFactor(int(c.partitionCount), len(c.members)-len(c.draining))
+	}
+	if maxUncapped == 0 {
+		if capped < float64(c.partitionCount) {
+			// Every member has a limit set by SetMaxLoad and they are too low.
+			return math.Inf(1)
+		}
+		maxUncapped = maxWeight
+	}
+
+	fits := func(load float64) bool {
+		var room float64
+		for _, member := range c.members {
+			if c.drained(*member) {
+				continue
+			}
+			r := c.capacity(*member, float64(c.partitionCount), load, total)
+			if max, ok := c.maxLoad(*member); ok && max < r {
+				r = max
+			}
+			room += r
+		}
+		return room >= float64(c.partitionCount)
+	}
+	// The heaviest member without a limit alone can own all the partitions with this value.
+	lo, hi := 0.0, total/maxUncapped
+	for i := 0; i < 64; i++ {
+		mid := (lo + hi) / 2
+		if fits(mid) {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consistent
+
+import (
+	"fmt"
+	"testing"
+)
+
+type weightedMember struct {
+	name   string
+	weight float64
+}
+
+func (wm weightedMember) String() string {
+	return wm.name
+}
+
+func (wm weightedMember) Weight() float64 {
+	return wm.weight
+}
+
+func TestConsistentWeightedMembers(t *testing.T) {
+	var members []Member
+	for i := 0; i < 6; i++ {
+		members = append(members, weightedMember{name: fmt.Sprintf("node%d.olric", i), weight: 1})
+	}
+	heavy := weightedMember{name: "heavy.olric", weight: 4}
+	members = append(members, heavy)
+
+	cfg := newConfig()
+	cfg.PartitionCount = 271
+	cfg.BackupCount = 2
+	c := New(members, cfg)
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	loads := c.LoadDistribution()
+	backups := c.BackupLoadDistribution()
+	for _, member := range members[:6] {
+		if loads[member.String()] >= loads[heavy.name] {
+			t.Fatalf("%s owns %f partitions, heavy member owns %f", member, loads[member.String()], loads[heavy.name])
+		}
+		if backups[member.String()] >= backups[heavy.name] {
+			t.Fatalf("%s holds %f backups, heavy member holds %f", member, backups[member.String()], backups[heavy.name])
+		}
+	}
+	if loads[heavy.name] <= c.AverageLoad() {
+		t.Fatalf("Heavy member should be allowed to exceed the average load: %f", loads[heavy.name])
+	}
+}
+
+func TestConsistentWeightedMinimumLoad(t *testing.T) {
+	members := []Member{
+		weightedMember{name: "node0.olric", weight: 1},
+		weightedMember{name: "node1.olric", weight: 3},
+	}
+	cfg := newConfig()
+	cfg.Load = 0.5
+
+	var err error
+	func() {
+		defer func() {
+			err, _ = recover().(error)
+		}()
+		New(members, cfg)
+	}()
+	derr, ok := err.(*DistributionError)
+	if !ok {
+		t.Fatalf("Expected DistributionError, Got: %v", err)
+	}
+
+	// The suggested load must be enough to distribute the partitions.
+	cfg.Load = derr.MinimumLoad
+	c := New(members, cfg)
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+}
+
+func TestConsistentClassLoads(t *testing.T) {
+	var members []Member
+	for i := 0; i < 4; i++ {
+		class := "ssd"
+		if i%2 == 1 {
+			class = "hdd"
+		}
+		members = append(members, labeledMember{name: fmt.Sprintf("node%d.olric", i), labels: map[string]string{"class": class}})
+	}
+	members = append(members, testMember("node4.olric"))
+	cfg := newConfig()
+	cfg.Load = 1.25
+	cfg.ClassLoads = map[string]float64{"ssd": 1, "hdd": 1.5}
+	c := New(members, cfg)
+
+	// 23 partitions over 5 members: 4.6 per member.
+	want := map[string]float64{"ssd": 5, "hdd": 7, "": 6}
+	loads := c.LoadDistribution()
+	for _, member := range c.GetMembers() {
+		var class string
+		if lm, ok := member.(labeledMember); ok {
+			class = lm.labels["class"]
+		}
+		bound := c.loadBound(member)
+		if bound != want[class] {
+			t.Fatalf("Expected the bound %v for %s, got: %v", want[class], member, bound)
+		}
+		if loads[member.String()] > bound {
+			t.Fatalf("%s owns %v partitions, more than %v", member, loads[member.String()], bound)
+		}
+	}
+}
+
+func TestConsistentClassLoadsLabel(t *testing.T) {
+	var members []Member
+	for i := 0; i < 4; i++ {
+		members = append(members, labeledMember{name: fmt.Sprintf("node%d.olric", i), labels: map[string]string{"disk": "nvme"}})
+	}
+	cfg := newConfig()
+	cfg.Load = 0.5
+	cfg.PanicFree = true
+	cfg.ClassLabel = "disk"
+	cfg.ClassLoads = map[string]float64{"nvme": 1.25}
+	c := New(members, cfg)
+	if r := c.LastDistribution(); r.Err != nil {
+		t.Fatalf("Expected the class load factor to leave enough room, got: %v", r.Err)
+	}
+}
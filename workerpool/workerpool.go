@@ -0,0 +1,262 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package workerpool runs tasks on a fixed number of goroutines and sends the tasks with the same key to the
+// same worker, using a consistent hash ring to map keys to workers:
+//
+//	p, err := workerpool.New(8)
+//	err = p.Submit([]byte("user-42"), func() { ... })
+//
+// Tasks with the same key run one at a time, in the order of submission. Resize changes the number of workers
+// and moves only the queued tasks whose keys change owner.
+package workerpool
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/buraksezer/consistent"
+)
+
+var (
+	// ErrClosed represents an error which means the pool doesn't accept tasks anymore.
+	ErrClosed = errors.New("worker pool is closed")
+
+	// ErrInvalidWorkerCount represents an error which means the worker count is less than one.
+	ErrInvalidWorkerCount = errors.New("invalid worker count")
+)
+
+type task struct {
+	seq uint64
+	key []byte
+	run func()
+}
+
+type worker struct {
+	index int
+	queue []task
+	cond  *sync.Cond
+	stop  bool
+}
+
+type workerName string
+
+func (w workerName) String() string {
+	return string(w)
+}
+
+// Pool is a pool of workers which keeps the tasks with the same key on the same worker.
+type Pool struct {
+	mu      sync.Mutex
+	ring    *consistent.Consistent
+	workers map[string]*worker
+	count   int
+	seq     uint64
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// New creates a pool of the given number of workers. The options configure the consistent hash ring, the
+// default hasher is used unless WithHasher is given.
+func New(workers int, opts ...consistent.Option) (*Pool, error) {
+	if workers < 1 {
+		return nil, ErrInvalidWorkerCount
+	}
+	p := &Pool{
+		workers: make(map[string]*worker, workers),
+	}
+	members := make([]consistent.Member, 0, workers)
+	for i := 0; i < workers; i++ {
+		members = append(members, workerName(name(i)))
+	}
+	opts = append([]consistent.Option{consistent.WithDefaultHasher(), consistent.WithMembers(members...)}, opts...)
+	p.ring = consistent.NewRing(opts...)
+	for i := 0; i < workers; i++ {
+		p.start(i)
+	}
+	p.count = workers
+	return p, nil
+}
+
+func name(i int) string {
+	return "worker-" + strconv.Itoa(i)
+}
+
+// start starts a worker goroutine. The caller must hold the lock, or own the pool exclusively.
+func (p *Pool) start(i int) {
+	w := &worker{index: i, cond: sync.NewCond(&p.mu)}
+	p.workers[name(i)] = w
+	p.wg.Add(1)
+	go p.work(w)
+}
+
+func (p *Pool) work(w *worker) {
+	defer p.wg.Done()
+
+	p.mu.Lock()
+	for {
+		for len(w.queue) == 0 && !w.stop {
+			w.cond.Wait()
+		}
+		if len(w.queue) == 0 {
+			p.mu.Unlock()
+			return
+		}
+		t := w.queue[0]
+		w.queue[0] = task{}
+		w.queue = w.queue[1:]
+		p.mu.Unlock()
+		t.run()
+		p.mu.Lock()
+	}
+}
+
+// Submit queues the task on the worker which owns the key. The key is copied, the caller may reuse it. It returns
+// ErrClosed after Close.
+func (p *Pool) Submit(key []byte, run func()) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+	p.seq++
+	w := p.workers[p.ring.LocateKey(key).String()]
+	// Resize locates the key again, the caller may have modified its slice by then.
+	w.queue = append(w.queue, task{seq: p.seq, key: append([]byte(nil), key...), run: run})
+	w.cond.Signal()
+	return nil
+}
+
+// Worker returns the index of the worker which runs the tasks with the given key, between zero and Workers.
+func (p *Pool) Worker(key []byte) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.workers[p.ring.LocateKey(key).String()].index
+}
+
+// Workers returns the number of workers.
+func (p *Pool) Workers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.count
+}
+
+// Pending returns the number of queued tasks which haven't started yet.
+func (p *Pool) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var n int
+	for _, w := range p.workers {
+		n += len(w.queue)
+	}
+	return n
+}
+
+// Resize changes the number of workers. Workers are added and removed at the end of the list and, like the
+// partitions of the ring, most keys of the remaining workers stay where they are. Queued tasks whose keys changed
+// owner move to the new owner and keep their order. A task that is already running finishes on its worker, so a
+// task with the same key may start on the new owner before it completes. It returns ErrInvalidWorkerCount if
+// workers is less than one and ErrClosed after Close.
+func (p *Pool) Resize(workers int) error {
+	if workers < 1 {
+		return ErrInvalidWorkerCount
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+	if workers == p.count {
+		return nil
+	}
+	members := make([]consistent.Member, 0, workers)
+	for i := 0; i < workers; i++ {
+		members = append(members, workerName(name(i)))
+	}
+	p.ring.SetMembers(members)
+	for i := p.count; i < workers; i++ {
+		p.start(i)
+	}
+
+	var moved []task
+	for n, w := range p.workers {
+		queue := w.queue[:0]
+		for _, t := range w.queue {
+			if p.ring.LocateKey(t.key).String() == n {
+				queue = append(queue, t)
+			} else {
+				moved = append(moved, t)
+			}
+		}
+		for i := len(queue); i < len(w.queue); i++ {
+			w.queue[i] = task{}
+		}
+		w.queue = queue
+	}
+	for i := workers; i < p.count; i++ {
+		w := p.workers[name(i)]
+		delete(p.workers, name(i))
+		w.stop = true
+		w.cond.Signal()
+	}
+	p.count = workers
+
+	touched := make(map[*worker]struct{})
+	for _, t := range moved {
+		w := p.workers[p.ring.LocateKey(t.key).String()]
+		w.queue = append(w.queue, t)
+		touched[w] = struct{}{}
+	}
+	// Tasks from different queues interleave on the new owner, restore the order of submission.
+	for w := range touched {
+		sort.Slice(w.queue, func(i, j int) bool {
+			return w.queue[i].seq < w.queue[j].seq
+		})
+		w.cond.Signal()
+	}
+	return nil
+}
+
+// Close stops accepting tasks, waits for the queued tasks to complete and stops the workers.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		p.wg.Wait()
+		return
+	}
+	p.closed = true
+	for _, w := range p.workers {
+		w.stop = true
+		w.cond.Signal()
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2018-2022 Burak Sezer
+// All rights reserved.
+//
+// This code is licensed under the MIT License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files(the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and / or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions :
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package workerpool
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestPool(t *testing.T) {
+	p, err := New(4)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	var mu sync.Mutex
+	got := make(map[string][]int)
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key-%d", i%17)
+		i := i
+		if err := p.Submit([]byte(key), func() {
+			mu.Lock()
+			got[key] = append(got[key], i)
+			mu.Unlock()
+		}); err != nil {
+			t.Fatalf("Expected nil, Got: %v", err)
+		}
+	}
+	p.Close()
+	var total int
+	for key, seq := range got {
+		for i := 1; i < len(seq); i++ {
+			if seq[i] < seq[i-1] {
+				t.Fatalf("Tasks of %s ran out of order: %v", key, seq)
+			}
+		}
+		total += len(seq)
+	}
+	if total != 1000 {
+		t.Fatalf("Expected 1000 tasks, Got: %d", total)
+	}
+	if err := p.Submit([]byte("key"), func() {}); err != ErrClosed {
+		t.Fatalf("Expected ErrClosed, Got: %v", err)
+	}
+	if err := p.Resize(2); err != ErrClosed {
+		t.Fatalf("Expected ErrClosed, Got: %v", err)
+	}
+}
+
+func TestPoolResize(t *testing.T) {
+	if _, err := New(0); err != ErrInvalidWorkerCount {
+		t.Fatalf("Expected ErrInvalidWorkerCount, Got: %v", err)
+	}
+	p, err := New(4)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+
+	// Block every worker, so the tasks stay queued.
+	block := make(chan struct{})
+	var started sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		for j := 0; ; j++ {
+			key := []byte(fmt.Sprintf("block-%d", j))
+			if p.Worker(key) == i {
+				started.Add(1)
+				_ = p.Submit(key, func() {
+					started.Done()
+					<-block
+				})
+				break
+			}
+		}
+	}
+	started.Wait()
+
+	var mu sync.Mutex
+	ran := make(map[string]int)
+	before := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		before[key] = p.Worker([]byte(key))
+		_ = p.Submit([]byte(key), func() {
+			mu.Lock()
+			ran[key]++
+			mu.Unlock()
+		})
+	}
+	if err := p.Resize(3); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	if p.Workers() != 3 {
+		t.Fatalf("Expected 3 workers, Got: %d", p.Workers())
+	}
+	p.mu.Lock()
+	for _, w := range p.workers {
+		for _, task := range w.queue {
+			owner := p.ring.LocateKey(task.key).String()
+			if owner != name(w.index) {
+				t.Fatalf("Task of %s is queued on %s, owner is %s", task.key, name(w.index), owner)
+			}
+		}
+	}
+	p.mu.Unlock()
+	var kept, stayed int
+	for key, worker := range before {
+		if worker == 3 {
+			continue
+		}
+		kept++
+		if p.Worker([]byte(key)) == worker {
+			stayed++
+		}
+	}
+	if stayed < kept*3/4 {
+		t.Fatalf("Only %d of %d keys stayed on their worker", stayed, kept)
+	}
+	if err := p.Resize(0); err != ErrInvalidWorkerCount {
+		t.Fatalf("Expected ErrInvalidWorkerCount, Got: %v", err)
+	}
+	if err := p.Resize(6); err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	close(block)
+	p.Close()
+	if len(ran) != 100 {
+		t.Fatalf("Expected 100 tasks, Got: %d", len(ran))
+	}
+}
+
+func TestPoolSubmitCopiesKey(t *testing.T) {
+	p, err := New(1)
+	if err != nil {
+		t.Fatalf("Expected nil, Got: %v", err)
+	}
+	block := make(chan struct{})
+	started := make(chan struct{})
+	_ = p.Submit([]byte("block"), func() {
+		close(started)
+		<-block
+	})
+	<-started
+
+	key := []byte("key-1")
+	_ = p.Submit(key, func() {})
+	copy(key, "key-2")
+	p.mu.Lock()
+	for _, w := range p.workers {
+		for _, task := range w.queue {
+			if string(task.key) != "key-1" {
+				t.Errorf("Expected the queued key to be key-1, Got: %s", task.key)
+			}
+		}
+	}
+	p.mu.Unlock()
+	close(block)
+	p.Close()
+}